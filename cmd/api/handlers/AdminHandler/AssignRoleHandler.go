@@ -0,0 +1,55 @@
+package AdminHandler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+type AssignRoleJsonRequest struct {
+	Role string `json:"role"`
+}
+
+// AssignRoleHandler gives a user a role
+func AssignRoleHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		assignRequest := new(AssignRoleJsonRequest)
+		if err := c.Bind(assignRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing JSON",
+			})
+		}
+
+		user, err := findUserFromParam(c, app)
+		if errors.Is(err, sql.ErrNoRows) {
+			return userNotFound(c)
+		}
+		if err != nil {
+			return err
+		}
+
+		assigned, err := app.Roles.AssignToUser(ctx, user.ID, assignRequest.Role)
+		if err != nil {
+			return err
+		}
+		if !assigned {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "Role does not exist",
+				Errors:  map[string][]string{"role": {"Role does not exist"}},
+			})
+		}
+
+		app.Permissions.Invalidate(user.ID)
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Role assigned",
+		})
+	}
+}
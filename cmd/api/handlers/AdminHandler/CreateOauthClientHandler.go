@@ -0,0 +1,120 @@
+package AdminHandler
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/oauthServer"
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
+)
+
+type CreateOauthClientJsonRequest struct {
+	Name         string   `json:"name"`
+	RedirectURIs []string `json:"redirectUris"`
+	Scopes       []string `json:"scopes"`
+	GrantTypes   []string `json:"grantTypes"`
+	// Public clients, such as mobile and single page apps, can't keep a secret so aren't given one
+	Public bool `json:"public"`
+}
+
+// CreateOauthClientHandler registers a third-party app to use the API through OAuth2. The client secret is only
+// ever returned in this response.
+func CreateOauthClientHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		adminId := c.Get("userId").(int64)
+
+		createRequest := new(CreateOauthClientJsonRequest)
+		if err := c.Bind(createRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing JSON",
+			})
+		}
+
+		invalid := func(field string, message string) error {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: message,
+				Errors:  map[string][]string{field: {message}},
+			})
+		}
+
+		name := strings.TrimSpace(createRequest.Name)
+		if name == "" || len(name) > 255 {
+			return invalid("name", "Name is required and must be at most 255 characters")
+		}
+
+		if len(createRequest.GrantTypes) == 0 {
+			return invalid("grantTypes", "At least one grant type is required")
+		}
+		for _, grantType := range createRequest.GrantTypes {
+			if grantType != oauthServer.GrantAuthorizationCode && grantType != oauthServer.GrantClientCredentials {
+				return invalid("grantTypes", "Unsupported grant type: "+grantType)
+			}
+		}
+		if createRequest.Public && slices.Contains(createRequest.GrantTypes, oauthServer.GrantClientCredentials) {
+			return invalid("grantTypes", "Public clients cannot use the client credentials grant")
+		}
+
+		if slices.Contains(createRequest.GrantTypes, oauthServer.GrantAuthorizationCode) && len(createRequest.RedirectURIs) == 0 {
+			return invalid("redirectUris", "At least one redirect URI is required for the authorization code grant")
+		}
+		for _, redirectURI := range createRequest.RedirectURIs {
+			if err := oauthServer.ValidateRedirectURI(redirectURI); err != nil {
+				return invalid("redirectUris", err.Error())
+			}
+		}
+
+		scopes := oauthServer.ParseScope(strings.Join(createRequest.Scopes, " "))
+		if len(scopes) == 0 {
+			return invalid("scopes", "At least one scope is required")
+		}
+		for _, scope := range scopes {
+			if _, ok := oauthServer.Scopes[scope]; !ok {
+				return invalid("scopes", "Unknown scope: "+scope)
+			}
+		}
+
+		clientId, err := oauthServer.GenerateClientID()
+		if err != nil {
+			return err
+		}
+
+		var clientSecret, secretHash string
+		if !createRequest.Public {
+			clientSecret, secretHash, err = tokenHelper.Generate()
+			if err != nil {
+				return err
+			}
+		}
+
+		grantTypes := oauthServer.ParseScope(strings.Join(createRequest.GrantTypes, " "))
+		if err := app.OauthClients.Create(ctx, clientId, name, secretHash, createRequest.RedirectURIs, scopes, grantTypes, adminId); err != nil {
+			return err
+		}
+
+		data := application.ResponseData{
+			"clientId":     clientId,
+			"name":         name,
+			"redirectUris": createRequest.RedirectURIs,
+			"scopes":       scopes,
+			"grantTypes":   grantTypes,
+			"public":       createRequest.Public,
+		}
+		message := "OAuth client created"
+		if clientSecret != "" {
+			data["clientSecret"] = clientSecret
+			message = "OAuth client created. Copy the client secret now, it will not be shown again"
+		}
+
+		return c.JSON(http.StatusCreated, application.Response{
+			Success: true,
+			Message: message,
+			Data:    data,
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package AdminHandler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+)
+
+// GetUserHandler returns any user's account, along with their roles
+func GetUserHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		user, err := findUserFromParam(c, app)
+		if errors.Is(err, sql.ErrNoRows) {
+			return userNotFound(c)
+		}
+		if err != nil {
+			return err
+		}
+
+		roles, err := app.Roles.GetRoleNamesForUser(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "User Retrieved",
+			Data: application.ResponseData{
+				"user":  user,
+				"roles": roles,
+			},
+		})
+	}
+}
+
+// findUserFromParam loads the user named by the :id route parameter, returning sql.ErrNoRows if there is none
+func findUserFromParam(c echo.Context, app *application.Application) (database.User, error) {
+	ctx := c.Request().Context()
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return database.User{}, sql.ErrNoRows
+	}
+
+	return app.Users.FindUser(ctx, id)
+}
+
+func userNotFound(c echo.Context) error {
+	return c.JSON(http.StatusNotFound, application.Response{
+		Success: false,
+		Message: "User not found",
+	})
+}
@@ -0,0 +1,88 @@
+package AdminHandler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+)
+
+// ImpersonateUserHandler mints a short-lived access token for acting as another user, to reproduce their issues.
+// The token records the admin in an "act" claim, cannot be refreshed, and every request made with it is audited.
+// It is only returned in the body, to send as a Bearer token, so the admin's own session cookies are untouched.
+func ImpersonateUserHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		adminId := c.Get("userId").(int64)
+
+		// Impersonation is for people, not scripts
+		if c.Get("apiKeyId") != nil {
+			return c.JSON(http.StatusForbidden, application.Response{
+				Success: false,
+				Message: "Users cannot be impersonated using an API key",
+			})
+		}
+
+		user, err := findUserFromParam(c, app)
+		if errors.Is(err, sql.ErrNoRows) {
+			return userNotFound(c)
+		}
+		if err != nil {
+			return err
+		}
+
+		if user.ID == adminId {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "You cannot impersonate yourself",
+			})
+		}
+
+		// Acting as a user with roles could be used to gain permissions the admin doesn't have
+		roles, err := app.Roles.GetRoleNamesForUser(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if len(roles) > 0 {
+			return c.JSON(http.StatusForbidden, application.Response{
+				Success: false,
+				Message: "Users with roles cannot be impersonated",
+			})
+		}
+
+		token, claims, err := app.JWTService.CreateImpersonationToken(user.ID, user.Username, adminId, app.Config.ImpersonationTTL)
+		if err != nil {
+			return err
+		}
+
+		_, err = app.ImpersonationAudit.Create(ctx, database.ImpersonationAuditEntry{
+			ImpersonatorID: adminId,
+			UserID:         user.ID,
+			TokenID:        claims.ID,
+			Action:         database.ImpersonationActionStart,
+			Method:         c.Request().Method,
+			Path:           c.Request().URL.Path,
+			IPAddress:      c.RealIP(),
+			UserAgent:      c.Request().UserAgent(),
+		})
+		if err != nil {
+			return err
+		}
+
+		app.Logger.Info("Impersonation started", "impersonatorId", adminId, "userId", user.ID, "tokenId", claims.ID)
+
+		return c.JSON(http.StatusCreated, application.Response{
+			Success: true,
+			Message: "Impersonation token created",
+			Data: application.ResponseData{
+				"accessToken": token,
+				"tokenType":   "Bearer",
+				"expiresAt":   claims.ExpiresAt,
+				"user":        user,
+			},
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package AdminHandler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+type ListImpersonationAuditJsonRequest struct {
+	UserID  int64 `query:"userId"`
+	Page    int   `query:"page"`
+	PerPage int   `query:"perPage"`
+}
+
+// ListImpersonationAuditHandler returns a page of the impersonation audit log, newest first,
+// optionally only for one impersonated user
+func ListImpersonationAuditHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		listRequest := new(ListImpersonationAuditJsonRequest)
+		if err := c.Bind(listRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing request",
+			})
+		}
+
+		page := max(listRequest.Page, 1)
+		perPage := listRequest.PerPage
+		if perPage < 1 || perPage > maxPerPage {
+			perPage = defaultPerPage
+		}
+
+		entries, err := app.ImpersonationAudit.List(ctx, listRequest.UserID, perPage, (page-1)*perPage)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Impersonation Audit Log Retrieved",
+			Data: application.ResponseData{
+				"entries": entries,
+				"page":    page,
+				"perPage": perPage,
+			},
+		})
+	}
+}
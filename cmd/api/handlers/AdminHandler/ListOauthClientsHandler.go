@@ -0,0 +1,27 @@
+package AdminHandler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+// ListOauthClientsHandler returns every registered OAuth client, including revoked ones
+func ListOauthClientsHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		clients, err := app.OauthClients.List(ctx)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "OAuth Clients Retrieved",
+			Data: application.ResponseData{
+				"clients": clients,
+			},
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package AdminHandler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+func ListRolesHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		roles, err := app.Roles.List(ctx)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Roles Retrieved",
+			Data: application.ResponseData{
+				"roles": roles,
+			},
+		})
+	}
+}
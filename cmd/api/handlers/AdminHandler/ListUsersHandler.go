@@ -0,0 +1,59 @@
+package AdminHandler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+const (
+	defaultPerPage = 25
+	maxPerPage     = 100
+)
+
+type ListUsersJsonRequest struct {
+	Page    int `query:"page"`
+	PerPage int `query:"perPage"`
+}
+
+// ListUsersHandler returns a page of every user account
+func ListUsersHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		listRequest := new(ListUsersJsonRequest)
+		if err := c.Bind(listRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing request",
+			})
+		}
+
+		page := max(listRequest.Page, 1)
+		perPage := listRequest.PerPage
+		if perPage < 1 || perPage > maxPerPage {
+			perPage = defaultPerPage
+		}
+
+		users, err := app.Users.List(ctx, perPage, (page-1)*perPage)
+		if err != nil {
+			return err
+		}
+
+		total, err := app.Users.Count(ctx)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Users Retrieved",
+			Data: application.ResponseData{
+				"users":   users,
+				"page":    page,
+				"perPage": perPage,
+				"total":   total,
+			},
+		})
+	}
+}
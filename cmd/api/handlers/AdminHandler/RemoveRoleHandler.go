@@ -0,0 +1,55 @@
+package AdminHandler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/rbac"
+)
+
+// RemoveRoleHandler takes a role away from a user
+func RemoveRoleHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+
+		user, err := findUserFromParam(c, app)
+		if errors.Is(err, sql.ErrNoRows) {
+			return userNotFound(c)
+		}
+		if err != nil {
+			return err
+		}
+
+		role := c.Param("role")
+
+		// Stop admins locking themselves out of the admin API
+		if user.ID == userId && role == rbac.RoleAdmin {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "You cannot remove your own admin role",
+			})
+		}
+
+		removed, err := app.Roles.RemoveFromUser(ctx, user.ID, role)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return c.JSON(http.StatusNotFound, application.Response{
+				Success: false,
+				Message: "User does not have this role",
+			})
+		}
+
+		app.Permissions.Invalidate(user.ID)
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Role removed",
+		})
+	}
+}
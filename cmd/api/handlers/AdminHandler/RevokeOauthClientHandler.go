@@ -0,0 +1,31 @@
+package AdminHandler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+// RevokeOauthClientHandler stops a client being used. Its outstanding access tokens stop working straight away.
+func RevokeOauthClientHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		revoked, err := app.OauthClients.Revoke(ctx, c.Param("id"))
+		if err != nil {
+			return err
+		}
+
+		if !revoked {
+			return c.JSON(http.StatusNotFound, application.Response{
+				Success: false,
+				Message: "OAuth client not found",
+			})
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "OAuth client revoked",
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package AuthHandler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/webauthnHelper"
+)
+
+// BeginPasskeyLoginHandler starts a passkey login, returning the options to pass to navigator.credentials.get()
+// and a session token to send back with the result. No username is needed, the passkey identifies the user.
+func BeginPasskeyLoginHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		assertion, session, err := app.WebAuthn.BeginDiscoverableLogin()
+		if err != nil {
+			return err
+		}
+
+		sessionToken, err := webauthnHelper.SaveSession(ctx, app.WebAuthnSessions, 0, database.WebAuthnLogin, session)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Success",
+			Data: application.ResponseData{
+				"options":      assertion,
+				"sessionToken": sessionToken,
+			},
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package AuthHandler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/mailer"
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
+)
+
+type ChangeEmailJsonRequest struct {
+	Email           string `json:"email"`
+	CurrentPassword string `json:"currentPassword"`
+}
+
+// ChangeEmailHandler starts changing the logged in user's email address. Nothing changes until the link
+// emailed to the new address is confirmed with ConfirmEmailChangeHandler.
+func ChangeEmailHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+
+		changeEmailRequest := new(ChangeEmailJsonRequest)
+		if err := c.Bind(changeEmailRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing JSON",
+			})
+		}
+
+		user, err := app.Users.FindUser(ctx, userId)
+		if err != nil {
+			return err
+		}
+
+		ok, err := checkCurrentPassword(c, app, user, changeEmailRequest.CurrentPassword)
+		if !ok || err != nil {
+			return err
+		}
+
+		email := strings.TrimSpace(changeEmailRequest.Email)
+		if !isValidEmail(email) {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "Invalid email address",
+				Errors:  map[string][]string{"email": {"Invalid email address"}},
+			})
+		}
+
+		if strings.EqualFold(email, user.Username) {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "This is already your email address",
+				Errors:  map[string][]string{"email": {"This is already your email address"}},
+			})
+		}
+
+		if _, err := app.Users.GetByUsername(ctx, email); err == nil {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "Email address is already in use",
+				Errors:  map[string][]string{"email": {"Email address is already in use"}},
+			})
+		}
+
+		// Only the most recently requested change should go through
+		if err := app.UserTokens.InvalidateForUser(ctx, user.ID, database.UserTokenEmailChange); err != nil {
+			return err
+		}
+
+		token, tokenHash, err := tokenHelper.Generate()
+		if err != nil {
+			return err
+		}
+
+		if err := app.UserTokens.Create(ctx, user.ID, database.UserTokenEmailChange, tokenHash, email, time.Now().Add(app.Config.EmailVerificationTTL)); err != nil {
+			return err
+		}
+
+		confirmLink := fmt.Sprintf("%s/confirm-email?token=%s", app.Config.FrontendURL, url.QueryEscape(token))
+
+		err = app.Mailer.Send(mailer.Message{
+			To:      email,
+			Subject: "Confirm your new email address",
+			Body: fmt.Sprintf(
+				"Someone asked to change the email address of their account to this one.\n\nTo confirm the change, open the link below within %s while logged in:\n\n%s\n\nIf this wasn't you, you can ignore this email.",
+				app.Config.EmailVerificationTTL,
+				confirmLink,
+			),
+		})
+		if err != nil {
+			return err
+		}
+
+		err = app.Mailer.Send(mailer.Message{
+			To:      user.Username,
+			Subject: "Your email address is being changed",
+			Body:    fmt.Sprintf("Someone asked to change the email address of your account to %s. It will only change once the new address has been confirmed.\n\nIf this wasn't you, change your password straight away.", email),
+		})
+		if err != nil {
+			app.ReportError(err)
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "A confirmation link has been sent to your new email address",
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package AuthHandler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/mailer"
+)
+
+type ChangePasswordJsonRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	Password        string `json:"password"`
+	PasswordConfirm string `json:"passwordConfirm"`
+}
+
+// ChangePasswordHandler sets a new password for the logged in user after checking their current one.
+// Every other session is signed out, and the user is emailed in case it wasn't them.
+func ChangePasswordHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+
+		changePasswordRequest := new(ChangePasswordJsonRequest)
+		if err := c.Bind(changePasswordRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing JSON",
+			})
+		}
+
+		user, err := app.Users.FindUser(ctx, userId)
+		if err != nil {
+			return err
+		}
+
+		ok, err := checkCurrentPassword(c, app, user, changePasswordRequest.CurrentPassword)
+		if !ok || err != nil {
+			return err
+		}
+
+		errorResponse, err := validateNewPassword(app, changePasswordRequest.Password, changePasswordRequest.PasswordConfirm, user.Username)
+		if err != nil {
+			return err
+		}
+		if errorResponse != nil {
+			return c.JSON(http.StatusUnprocessableEntity, errorResponse)
+		}
+
+		passwordHash, err := app.Passwords.Hash(changePasswordRequest.Password)
+		if err != nil {
+			return err
+		}
+
+		if err := app.Users.UpdatePassword(ctx, user.ID, passwordHash); err != nil {
+			return err
+		}
+
+		data, err := signOutOtherSessions(c, app, user)
+		if err != nil {
+			return err
+		}
+
+		err = app.Mailer.Send(mailer.Message{
+			To:      user.Username,
+			Subject: "Your password has been changed",
+			Body:    "The password for your account was just changed, and any other devices have been signed out.\n\nIf this wasn't you, reset your password straight away.",
+		})
+		if err != nil {
+			app.ReportError(err)
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Password changed",
+			Data:    data,
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package AuthHandler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/mailer"
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
+)
+
+type ConfirmEmailChangeJsonRequest struct {
+	Token string `json:"token"`
+}
+
+// ConfirmEmailChangeHandler finishes changing the logged in user's email address with the token sent to the new address.
+// Every other session is signed out, and the old address is told about the change.
+func ConfirmEmailChangeHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+
+		confirmRequest := new(ConfirmEmailChangeJsonRequest)
+		if err := c.Bind(confirmRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing JSON",
+			})
+		}
+
+		invalidToken := func() error {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "This confirmation link is invalid or has expired",
+				Errors:  map[string][]string{"token": {"This confirmation link is invalid or has expired"}},
+			})
+		}
+
+		if confirmRequest.Token == "" {
+			return invalidToken()
+		}
+
+		userToken, err := app.UserTokens.GetValid(ctx, database.UserTokenEmailChange, tokenHelper.Hash(confirmRequest.Token))
+		if err != nil || userToken.UserID != userId || !userToken.Payload.Valid {
+			return invalidToken()
+		}
+
+		newEmail := userToken.Payload.String
+
+		// Someone may have registered with the address since the change was requested
+		if _, err := app.Users.GetByUsername(ctx, newEmail); err == nil {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "Email address is already in use",
+				Errors:  map[string][]string{"email": {"Email address is already in use"}},
+			})
+		}
+
+		used, err := app.UserTokens.MarkUsed(ctx, userToken.ID)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return invalidToken()
+		}
+
+		user, err := app.Users.FindUser(ctx, userId)
+		if err != nil {
+			return err
+		}
+		oldEmail := user.Username
+
+		if err := app.Users.UpdateEmail(ctx, user.ID, newEmail); err != nil {
+			return err
+		}
+		user.Username = newEmail
+
+		data, err := signOutOtherSessions(c, app, user)
+		if err != nil {
+			return err
+		}
+
+		err = app.Mailer.Send(mailer.Message{
+			To:      oldEmail,
+			Subject: "Your email address has been changed",
+			Body:    fmt.Sprintf("The email address of your account has been changed to %s, and any other devices have been signed out.\n\nIf this wasn't you, please contact us straight away.", newEmail),
+		})
+		if err != nil {
+			app.ReportError(err)
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Email address changed",
+			Data:    data,
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package AuthHandler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+// CsrfTokenHandler returns the CSRF token set by CSRFMiddleware, for the frontend to send back in the
+// X-CSRF-Token header of cookie-authenticated POST, PUT, PATCH and DELETE requests
+func CsrfTokenHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Response().Header().Set("Cache-Control", "no-store")
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "CSRF token retrieved",
+			Data: application.ResponseData{
+				"csrfToken": c.Get("csrf"),
+			},
+		})
+	}
+}
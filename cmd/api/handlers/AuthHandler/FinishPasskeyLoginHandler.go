@@ -0,0 +1,89 @@
+package AuthHandler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/webauthnHelper"
+)
+
+type FinishPasskeyLoginJsonRequest struct {
+	SessionToken  string          `json:"sessionToken"`
+	Credential    json.RawMessage `json:"credential"`
+	RememberMe    bool            `json:"rememberMe"`
+	TokenResponse bool            `json:"tokenResponse"`
+}
+
+// FinishPasskeyLoginHandler verifies the result of navigator.credentials.get() and signs the user in.
+// Passkeys require user verification, so they already count as two factors and skip the TOTP step.
+func FinishPasskeyLoginHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		finishRequest := new(FinishPasskeyLoginJsonRequest)
+		if err := c.Bind(finishRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing JSON",
+			})
+		}
+
+		loginFailed := func() error {
+			return c.JSON(http.StatusUnauthorized, application.Response{
+				Success: false,
+				Message: "Passkey login failed",
+			})
+		}
+
+		session, _, err := webauthnHelper.LoadSession(ctx, app.WebAuthnSessions, finishRequest.SessionToken, database.WebAuthnLogin)
+		if err != nil {
+			return loginFailed()
+		}
+
+		parsed, err := protocol.ParseCredentialRequestResponseBytes(finishRequest.Credential)
+		if err != nil {
+			return loginFailed()
+		}
+
+		var storedCredential database.UserCredential
+		findUser := func(rawId, userHandle []byte) (webauthn.User, error) {
+			userId, err := webauthnHelper.UserIdFromHandle(userHandle)
+			if err != nil {
+				return nil, err
+			}
+
+			user, err := app.Users.FindUser(ctx, userId)
+			if err != nil {
+				return nil, err
+			}
+
+			storedCredential, err = app.Credentials.GetByCredentialId(ctx, rawId)
+			if err != nil || storedCredential.UserID != userId {
+				return nil, protocol.ErrBadRequest.WithDetails("Unknown credential")
+			}
+
+			return &webauthnHelper.User{User: user, Credentials: []database.UserCredential{storedCredential}}, nil
+		}
+
+		webAuthnUser, credential, err := app.WebAuthn.ValidatePasskeyLogin(findUser, session, parsed)
+		if err != nil {
+			return loginFailed()
+		}
+
+		if credential.Authenticator.CloneWarning {
+			app.Logger.Warn("Passkey signature counter went backwards, the authenticator may have been cloned", "credentialId", storedCredential.ID)
+			return loginFailed()
+		}
+
+		flags := uint8(parsed.Response.AuthenticatorData.Flags)
+		if err := app.Credentials.RecordUse(ctx, storedCredential.ID, credential.Authenticator.SignCount, flags); err != nil {
+			return err
+		}
+
+		return loginSuccess(c, app, webAuthnUser.(*webauthnHelper.User).User, finishRequest.RememberMe, finishRequest.TokenResponse)
+	}
+}
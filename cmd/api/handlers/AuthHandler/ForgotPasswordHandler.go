@@ -0,0 +1,105 @@
+package AuthHandler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/mailer"
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
+)
+
+const passwordResetResendInterval = time.Minute
+
+type ForgotPasswordJsonRequest struct {
+	Username string `json:"username"`
+}
+
+// ForgotPasswordHandler emails a single-use password reset link. The response is the same whether or not
+// the account exists, so it cannot be used to find out which email addresses are registered.
+func ForgotPasswordHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		forgotPasswordRequest := new(ForgotPasswordJsonRequest)
+		if err := c.Bind(forgotPasswordRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing JSON",
+			})
+		}
+
+		if forgotPasswordRequest.Username == "" {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "Username is required",
+				Errors:  map[string][]string{"username": {"Username is required"}},
+			})
+		}
+
+		successResponse := application.Response{
+			Success: true,
+			Message: "If an account exists for that email address, a password reset link has been sent",
+		}
+
+		// The account is looked up and the link issued in the background, so the response takes as long whatever
+		// happens to the request
+		app.Background(ctx, func(ctx context.Context) error {
+			user, err := app.Users.GetByUsername(ctx, forgotPasswordRequest.Username)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			token, tokenHash, err := tokenHelper.Generate()
+			if err != nil {
+				return err
+			}
+
+			issued := false
+			err = app.WithTx(ctx, func(tx database.Stores) error {
+				// Quietly drop repeated requests so the endpoint can't be used to flood someone's inbox
+				sentRecently, err := tx.UserTokens.CountCreatedSince(ctx, user.ID, database.UserTokenPasswordReset, time.Now().Add(-passwordResetResendInterval))
+				if err != nil || sentRecently > 0 {
+					return err
+				}
+
+				// Only the most recently requested link should work
+				if err := tx.UserTokens.InvalidateForUser(ctx, user.ID, database.UserTokenPasswordReset); err != nil {
+					return err
+				}
+				if err := tx.UserTokens.Create(ctx, user.ID, database.UserTokenPasswordReset, tokenHash, "", time.Now().Add(app.Config.PasswordResetTTL)); err != nil {
+					return err
+				}
+
+				issued = true
+				return nil
+			})
+			if err != nil || !issued {
+				return err
+			}
+
+			resetLink := fmt.Sprintf("%s/reset-password?token=%s", app.Config.FrontendURL, url.QueryEscape(token))
+
+			return app.Mailer.Send(mailer.Message{
+				To:      user.Username,
+				Subject: "Reset your password",
+				Body: fmt.Sprintf(
+					"Someone asked to reset the password for your account.\n\nTo choose a new password, open the link below within %s:\n\n%s\n\nIf this wasn't you, you can ignore this email.",
+					app.Config.PasswordResetTTL,
+					resetLink,
+				),
+			})
+		})
+
+		return c.JSON(http.StatusOK, successResponse)
+	}
+}
@@ -0,0 +1,66 @@
+package AuthHandler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/testHelper"
+)
+
+func forgotPassword(t *testing.T, app *application.Application, username string) string {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/forgot-password", strings.NewReader(`{"username":"`+username+`"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	if err := ForgotPasswordHandler(app)(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("ForgotPasswordHandler: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	return rec.Body.String()
+}
+
+func TestForgotPasswordHandler(t *testing.T) {
+	app, mail := testHelper.NewApp(t)
+	testHelper.CreateUser(t, app, "user@example.com", "correct horse battery staple")
+
+	known := forgotPassword(t, app, "user@example.com")
+	unknown := forgotPassword(t, app, "nobody@example.com")
+	if known != unknown {
+		t.Errorf("response for an existing account %s differs from an unknown one %s", known, unknown)
+	}
+
+	app.Wait()
+	messages := mail.Messages()
+	if len(messages) != 1 || messages[0].To != "user@example.com" {
+		t.Fatalf("sent %+v, want one email to user@example.com", messages)
+	}
+	if !strings.Contains(messages[0].Body, "/reset-password?token=") {
+		t.Errorf("email has no reset link: %s", messages[0].Body)
+	}
+}
+
+func TestForgotPasswordHandlerThrottles(t *testing.T) {
+	app, mail := testHelper.NewApp(t)
+	testHelper.CreateUser(t, app, "user@example.com", "correct horse battery staple")
+
+	first := forgotPassword(t, app, "user@example.com")
+	app.Wait()
+	// A repeat within the resend interval sends nothing, with the same response
+	if again := forgotPassword(t, app, "user@example.com"); again != first {
+		t.Errorf("response to a repeated request %s differs from the first %s", again, first)
+	}
+	app.Wait()
+
+	if got := len(mail.Messages()); got != 1 {
+		t.Errorf("sent %d emails, want 1", got)
+	}
+}
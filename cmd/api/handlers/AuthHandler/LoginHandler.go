@@ -1,23 +1,24 @@
 package AuthHandler
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
 	"github.com/nathanjms/go-api-template/internal/application"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
+// LoginJsonUser is the login request. TokenResponse returns the tokens in the
+// response body instead of setting cookies, for clients using Bearer auth.
 type LoginJsonUser struct {
-	Username   string `json:"username"`
-	Password   string `json:"password"`
-	RememberMe bool   `json:"rememberMe"`
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	RememberMe    bool   `json:"rememberMe"`
+	TokenResponse bool   `json:"tokenResponse"`
 }
 
 func LoginHandler(app *application.Application) echo.HandlerFunc {
 	return func(c echo.Context) error {
+		ctx := c.Request().Context()
 		loginUserRequest := new(LoginJsonUser)
 		if err := c.Bind(loginUserRequest); err != nil {
 			return c.JSON(http.StatusBadRequest, application.Response{
@@ -34,40 +35,46 @@ func LoginHandler(app *application.Application) echo.HandlerFunc {
 			})
 		}
 
-		user, err := app.DB.UserModel.GetByUsername(loginUserRequest.Username)
-		if err != nil {
-			return c.JSON(http.StatusUnprocessableEntity, application.Response{
-				Success: false,
-				Message: "Invalid username or password",
-			})
+		accountKey := accountThrottleKey(loginUserRequest.Username)
+		if throttled, err := checkThrottle(c, app, accountKey); throttled || err != nil {
+			return err
 		}
 
-		// Compare password using bcrypt, failing if they don't match
-		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginUserRequest.Password)); err != nil {
+		invalidLogin := func() error {
+			if err := recordFailure(c, app, accountKey); err != nil {
+				return err
+			}
+
 			return c.JSON(http.StatusUnprocessableEntity, application.Response{
 				Success: false,
 				Message: "Invalid username or password",
 			})
 		}
 
-		jwtCookie, err := app.JWTService.CreateJwtCookie(user.ID, loginUserRequest.Username, loginUserRequest.RememberMe)
+		user, err := app.Users.GetByUsername(ctx, loginUserRequest.Username)
+		if err != nil {
+			return invalidLogin()
+		}
 
+		match, needsRehash, err := app.Passwords.Verify(loginUserRequest.Password, user.Password)
 		if err != nil {
-			fmt.Println(err)
 			return err
 		}
-		fmt.Println(jwtCookie)
+		if !match {
+			return invalidLogin()
+		}
 
-		// Set the cookie:
-		c.SetCookie(jwtCookie)
+		// Upgrade hashes made with an old algorithm or cost now that we have the plain password
+		if needsRehash {
+			if err := rehashPassword(ctx, app, user.ID, loginUserRequest.Password); err != nil {
+				app.ReportError(err)
+			}
+		}
+
+		if err := app.AccountThrottle.Succeed(ctx, accountKey); err != nil {
+			return err
+		}
 
-		return c.JSON(http.StatusOK, application.Response{
-			Success: true,
-			Message: "Success",
-			Data: application.ResponseData{
-				"id":       user.ID,
-				"username": user.Username,
-			},
-		})
+		return completeLogin(c, app, user, loginUserRequest.RememberMe, loginUserRequest.TokenResponse)
 	}
 }
@@ -0,0 +1,109 @@
+package AuthHandler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
+	"github.com/nathanjms/go-api-template/internal/totpHelper"
+)
+
+type LoginMfaJsonRequest struct {
+	MfaToken     string `json:"mfaToken"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
+}
+
+// LoginMfaHandler is the second step of logging in with two-factor authentication. It exchanges the MFA token
+// from LoginHandler plus either a TOTP code or a recovery code for the usual session.
+func LoginMfaHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		loginMfaRequest := new(LoginMfaJsonRequest)
+		if err := c.Bind(loginMfaRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing JSON",
+			})
+		}
+
+		claims, err := app.JWTService.ParsePurposeToken(loginMfaRequest.MfaToken, mfaTokenPurpose)
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, application.Response{
+				Success: false,
+				Message: "Your login has expired, please log in again",
+			})
+		}
+
+		userIdClaim, _ := claims["userId"].(float64)
+		rememberMe, _ := claims["rememberMe"].(bool)
+		tokenResponse, _ := claims["tokenResponse"].(bool)
+		userId := int64(userIdClaim)
+
+		twoFactor, err := app.TwoFactor.Get(ctx, userId)
+		if err != nil || !twoFactor.EnabledAt.Valid {
+			return c.JSON(http.StatusUnauthorized, application.Response{
+				Success: false,
+				Message: "Unauthorized",
+			})
+		}
+
+		// Codes are short, so guesses are throttled per user as well as per IP
+		accountKey := mfaThrottleKey(userId)
+		if throttled, err := checkThrottle(c, app, accountKey); throttled || err != nil {
+			return err
+		}
+
+		invalidCode := func() error {
+			if err := recordFailure(c, app, accountKey); err != nil {
+				return err
+			}
+
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "Invalid authentication code",
+				Errors:  map[string][]string{"code": {"Invalid authentication code"}},
+			})
+		}
+
+		switch {
+		case loginMfaRequest.Code != "":
+			counter, ok := totpHelper.Validate(twoFactor.Secret.String, loginMfaRequest.Code, time.Now())
+			if !ok {
+				return invalidCode()
+			}
+
+			// Each code can only be used once, even within its time window
+			fresh, err := app.TwoFactor.UseCounter(ctx, userId, counter)
+			if err != nil {
+				return err
+			}
+			if !fresh {
+				return invalidCode()
+			}
+		case loginMfaRequest.RecoveryCode != "":
+			used, err := app.TwoFactor.UseRecoveryCode(ctx, userId, tokenHelper.Hash(totpHelper.NormalizeRecoveryCode(loginMfaRequest.RecoveryCode)))
+			if err != nil {
+				return err
+			}
+			if !used {
+				return invalidCode()
+			}
+		default:
+			return invalidCode()
+		}
+
+		if err := app.AccountThrottle.Succeed(ctx, accountKey); err != nil {
+			return err
+		}
+
+		user, err := app.Users.FindUser(ctx, userId)
+		if err != nil {
+			return err
+		}
+
+		return loginSuccess(c, app, user, rememberMe, tokenResponse)
+	}
+}
@@ -0,0 +1,105 @@
+package AuthHandler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/testHelper"
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
+	"github.com/nathanjms/go-api-template/internal/totpHelper"
+)
+
+// newMfaUser creates a user with two-factor authentication enabled, returning them with their secret
+func newMfaUser(t *testing.T, app *application.Application, recoveryCode string) (database.User, string) {
+	t.Helper()
+	ctx := context.Background()
+
+	user := testHelper.CreateUser(t, app, "user@example.com", "correct horse battery staple")
+	secret, err := totpHelper.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.TwoFactor.SetPendingSecret(ctx, user.ID, secret); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.TwoFactor.Enable(ctx, user.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.TwoFactor.ReplaceRecoveryCodes(ctx, user.ID, []string{tokenHelper.Hash(totpHelper.NormalizeRecoveryCode(recoveryCode))}); err != nil {
+		t.Fatal(err)
+	}
+
+	return user, secret
+}
+
+// loginMfa calls LoginMfaHandler with the second factor in body, returning the status
+func loginMfa(t *testing.T, app *application.Application, user database.User, body map[string]string) int {
+	t.Helper()
+
+	mfaToken, err := createMfaToken(app, user, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body["mfaToken"] = mfaToken
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/login/mfa", strings.NewReader(string(encoded)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	if err := LoginMfaHandler(app)(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("LoginMfaHandler: %v", err)
+	}
+
+	return rec.Code
+}
+
+func TestLoginMfaHandlerRejectsReusedCode(t *testing.T) {
+	app, _ := testHelper.NewApp(t)
+	user, secret := newMfaUser(t, app, "abcde-fghij")
+
+	code, err := totpHelper.Code(secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status := loginMfa(t, app, user, map[string]string{"code": code}); status != http.StatusOK {
+		t.Fatalf("first use = %d, want %d", status, http.StatusOK)
+	}
+	// The same code again, such as one seen over the user's shoulder, within its time window
+	if status := loginMfa(t, app, user, map[string]string{"code": code}); status != http.StatusUnprocessableEntity {
+		t.Errorf("second use = %d, want %d", status, http.StatusUnprocessableEntity)
+	}
+
+	// Nor can the previous step's code be used after this one
+	previous, err := totpHelper.Code(secret, time.Now().Add(-30*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := loginMfa(t, app, user, map[string]string{"code": previous}); status != http.StatusUnprocessableEntity {
+		t.Errorf("earlier code = %d, want %d", status, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestLoginMfaHandlerConsumesRecoveryCode(t *testing.T) {
+	app, _ := testHelper.NewApp(t)
+	user, _ := newMfaUser(t, app, "abcde-fghij")
+
+	if status := loginMfa(t, app, user, map[string]string{"recoveryCode": "ABCDE FGHIJ"}); status != http.StatusOK {
+		t.Fatalf("first use = %d, want %d", status, http.StatusOK)
+	}
+	if status := loginMfa(t, app, user, map[string]string{"recoveryCode": "abcde-fghij"}); status != http.StatusUnprocessableEntity {
+		t.Errorf("second use = %d, want %d", status, http.StatusUnprocessableEntity)
+	}
+}
@@ -5,16 +5,45 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/jwtHelper"
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
 )
 
 func LogoutHandler(app *application.Application) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		clearCookie, err := app.JWTService.ClearCookie()
+		ctx := c.Request().Context()
 
-		if err == nil {
-			c.SetCookie(clearCookie)
+		// Revoke the access token so a copy of it cannot be used until it expires
+		if token, _ := jwtHelper.TokenFromRequest(c.Request()); token != "" {
+			if claims, err := app.JWTService.GetClaimsFromJWT(token); err == nil {
+				if err := app.Revocations.Revoke(ctx, claims); err != nil {
+					return err
+				}
+
+				if claims.SessionID != "" {
+					if _, err := app.Revocations.RevokeSession(ctx, claims.UserID, claims.SessionID); err != nil {
+						return err
+					}
+				}
+			}
 		}
 
+		// Revoke the session through the refresh token too, in case the access token has already expired
+		if plainToken, _ := refreshTokenFromRequest(c); plainToken != "" {
+			refreshToken, err := app.RefreshTokens.GetByHash(ctx, tokenHelper.Hash(plainToken))
+			if err == nil {
+				if err := app.RefreshTokens.RevokeFamily(ctx, refreshToken.FamilyID); err != nil {
+					return err
+				}
+
+				if _, err := app.Revocations.RevokeSession(ctx, refreshToken.UserID, refreshToken.FamilyID); err != nil {
+					return err
+				}
+			}
+		}
+
+		clearSessionCookies(c, app)
+
 		return c.JSON(http.StatusOK, application.Response{
 			Success: true,
 			Message: "Logged out successfully",
@@ -0,0 +1,70 @@
+package AuthHandler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
+)
+
+// MagicLinkCallbackHandler is where the emailed magic link points. It logs the user in and redirects to the frontend,
+// or to the frontend's /login page with an error query parameter if the link is invalid, expired or already used.
+func MagicLinkCallbackHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		fail := func(reason string) error {
+			return c.Redirect(http.StatusFound, fmt.Sprintf("%s/login?error=%s", app.Config.FrontendURL, url.QueryEscape(reason)))
+		}
+
+		token := c.QueryParam("token")
+		if token == "" {
+			return fail("magic_link_invalid")
+		}
+
+		userToken, err := app.UserTokens.GetValid(ctx, database.UserTokenMagicLink, tokenHelper.Hash(token))
+		if err != nil {
+			return fail("magic_link_invalid")
+		}
+
+		payload := magicLinkPayload{}
+		if err := json.Unmarshal([]byte(userToken.Payload.String), &payload); err != nil {
+			return fail("magic_link_invalid")
+		}
+
+		// Checked before using up the token, so opening the link in the wrong browser doesn't waste it
+		if app.Config.MagicLinkBindBrowser {
+			cookie, err := c.Cookie(magicLinkCookieName)
+			if err != nil || subtle.ConstantTimeCompare([]byte(tokenHelper.Hash(cookie.Value)), []byte(payload.BrowserHash)) != 1 {
+				return fail("magic_link_wrong_browser")
+			}
+		}
+
+		used, err := app.UserTokens.MarkUsed(ctx, userToken.ID)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return fail("magic_link_invalid")
+		}
+
+		c.SetCookie(magicLinkCookie("", -1))
+
+		// Opening the link proves the user owns the address
+		if err := app.Users.MarkEmailVerified(ctx, userToken.UserID); err != nil {
+			return err
+		}
+
+		user, err := app.Users.FindUser(ctx, userToken.UserID)
+		if err != nil {
+			return err
+		}
+
+		return completeRedirectLogin(c, app, user, payload.RememberMe)
+	}
+}
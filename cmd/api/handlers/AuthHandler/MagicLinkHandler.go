@@ -0,0 +1,168 @@
+package AuthHandler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/mailer"
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
+)
+
+const (
+	magicLinkCookieName     = "magic_link_browser"
+	magicLinkResendInterval = time.Minute
+)
+
+type MagicLinkJsonRequest struct {
+	Username   string `json:"username"`
+	RememberMe bool   `json:"rememberMe"`
+}
+
+// magicLinkPayload is stored with the magic link token
+type magicLinkPayload struct {
+	RememberMe bool `json:"rememberMe"`
+	// BrowserHash is the hash of the magic_link_browser cookie given to the browser that asked for the link
+	BrowserHash string `json:"browserHash"`
+}
+
+// MagicLinkHandler emails the user a single-use link that logs them in without a password. The response is
+// the same whether or not the account exists. The requesting browser is given a cookie the link is bound to,
+// so a link forwarded to or intercepted by someone else does not work in their browser.
+func MagicLinkHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		magicLinkRequest := new(MagicLinkJsonRequest)
+		if err := c.Bind(magicLinkRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing JSON",
+			})
+		}
+
+		if magicLinkRequest.Username == "" {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "Username is required",
+				Errors:  map[string][]string{"username": {"Username is required"}},
+			})
+		}
+
+		successResponse := application.Response{
+			Success: true,
+			Message: "If an account exists for that email address, a login link has been sent",
+		}
+
+		// Every response sets the binding cookie, so it doesn't reveal whether the account exists or was sent a
+		// link recently. A browser that already has one keeps it, so a repeated request doesn't unbind a link
+		// that was already sent to it.
+		browserToken, err := magicLinkBrowserToken(c)
+		if err != nil {
+			return err
+		}
+		c.SetCookie(magicLinkCookie(browserToken, int(app.Config.MagicLinkTTL.Seconds())))
+
+		// The account is looked up and the link issued in the background, so the response takes as long whatever
+		// happens to the request
+		app.Background(ctx, func(ctx context.Context) error {
+			user, err := app.Users.GetByUsername(ctx, magicLinkRequest.Username)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			token, tokenHash, err := tokenHelper.Generate()
+			if err != nil {
+				return err
+			}
+
+			payload, err := json.Marshal(magicLinkPayload{
+				RememberMe:  magicLinkRequest.RememberMe,
+				BrowserHash: tokenHelper.Hash(browserToken),
+			})
+			if err != nil {
+				return err
+			}
+
+			issued := false
+			err = app.WithTx(ctx, func(tx database.Stores) error {
+				// Quietly drop repeated requests so the endpoint can't be used to flood someone's inbox
+				sentRecently, err := tx.UserTokens.CountCreatedSince(ctx, user.ID, database.UserTokenMagicLink, time.Now().Add(-magicLinkResendInterval))
+				if err != nil || sentRecently > 0 {
+					return err
+				}
+
+				// Only the most recently requested link should work
+				if err := tx.UserTokens.InvalidateForUser(ctx, user.ID, database.UserTokenMagicLink); err != nil {
+					return err
+				}
+				if err := tx.UserTokens.Create(ctx, user.ID, database.UserTokenMagicLink, tokenHash, string(payload), time.Now().Add(app.Config.MagicLinkTTL)); err != nil {
+					return err
+				}
+
+				issued = true
+				return nil
+			})
+			if err != nil || !issued {
+				return err
+			}
+
+			loginLink := fmt.Sprintf("%s/login/magic-link/callback?token=%s", strings.TrimSuffix(app.Config.BaseURL, "/"), url.QueryEscape(token))
+
+			return app.Mailer.Send(mailer.Message{
+				To:      user.Username,
+				Subject: "Your login link",
+				Body: fmt.Sprintf(
+					"To log in, open the link below within %s, in the same browser you requested it from:\n\n%s\n\nIf you didn't ask to log in, you can ignore this email.",
+					app.Config.MagicLinkTTL,
+					loginLink,
+				),
+			})
+		})
+
+		return c.JSON(http.StatusOK, successResponse)
+	}
+}
+
+// magicLinkBrowserToken is the browser's existing binding cookie, or a new one if it doesn't have one
+func magicLinkBrowserToken(c echo.Context) (string, error) {
+	if cookie, err := c.Cookie(magicLinkCookieName); err == nil && cookie.Value != "" && len(cookie.Value) <= 64 {
+		return cookie.Value, nil
+	}
+
+	token, _, err := tokenHelper.Generate()
+	return token, err
+}
+
+// magicLinkCookie binds a magic link to the browser that asked for it. It is set from a cross-site request
+// by the frontend, so like the session cookies it needs SameSite=None outside local development.
+func magicLinkCookie(value string, maxAge int) *http.Cookie {
+	isDev := os.Getenv("ENV") == "local"
+
+	sameSite := http.SameSiteNoneMode
+	if isDev {
+		sameSite = http.SameSiteLaxMode
+	}
+
+	return &http.Cookie{
+		Name:     magicLinkCookieName,
+		Value:    value,
+		Path:     "/login/magic-link",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   !isDev,
+		SameSite: sameSite,
+	}
+}
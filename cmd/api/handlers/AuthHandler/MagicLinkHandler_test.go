@@ -0,0 +1,101 @@
+package AuthHandler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/testHelper"
+)
+
+// requestMagicLink asks for a link for the username from a browser with the cookies, returning the binding
+// cookie set
+func requestMagicLink(t *testing.T, app *application.Application, username string, cookies ...*http.Cookie) *http.Cookie {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/login/magic-link", strings.NewReader(`{"username":"`+username+`"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+
+	if err := MagicLinkHandler(app)(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("MagicLinkHandler: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == magicLinkCookieName {
+			return cookie
+		}
+	}
+	t.Fatal("no binding cookie")
+
+	return nil
+}
+
+func TestMagicLinkHandlerThrottlesWithoutRevealingIt(t *testing.T) {
+	app, mail := testHelper.NewApp(t)
+	testHelper.CreateUser(t, app, "user@example.com", "correct horse battery staple")
+
+	first := requestMagicLink(t, app, "user@example.com")
+	app.Wait()
+	if got := len(mail.Messages()); got != 1 {
+		t.Fatalf("sent %d emails, want 1", got)
+	}
+
+	// A repeat within the resend interval sends nothing, but still sets a cookie, keeping the browser's own so
+	// the link already sent keeps working
+	if again := requestMagicLink(t, app, "user@example.com", first); again.Value != first.Value {
+		t.Error("repeated request replaced the cookie the sent link is bound to")
+	}
+	if another := requestMagicLink(t, app, "user@example.com"); another.Value == first.Value {
+		t.Error("another browser was given the same binding cookie")
+	}
+	app.Wait()
+	if got := len(mail.Messages()); got != 1 {
+		t.Errorf("sent %d emails, want 1", got)
+	}
+}
+
+func TestMagicLinkHandlerThrottlesConcurrentRequests(t *testing.T) {
+	app, mail := testHelper.NewApp(t)
+	testHelper.CreateUser(t, app, "user@example.com", "correct horse battery staple")
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			req := httptest.NewRequest(http.MethodPost, "/login/magic-link", strings.NewReader(`{"username":"user@example.com"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if err := MagicLinkHandler(app)(echo.New().NewContext(req, httptest.NewRecorder())); err != nil {
+				t.Errorf("MagicLinkHandler: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	app.Wait()
+
+	if got := len(mail.Messages()); got != 1 {
+		t.Errorf("sent %d emails, want 1", got)
+	}
+}
+
+func TestMagicLinkHandlerSetsCookieForUnknownAccount(t *testing.T) {
+	app, mail := testHelper.NewApp(t)
+
+	requestMagicLink(t, app, "nobody@example.com")
+	app.Wait()
+	if got := len(mail.Messages()); got != 0 {
+		t.Errorf("sent %d emails, want 0", got)
+	}
+}
@@ -0,0 +1,153 @@
+package AuthHandler
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/oidcHelper"
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
+)
+
+var (
+	errOidcEmailNotVerified   = errors.New("provider did not return a verified email address")
+	errOidcAccountNotVerified = errors.New("existing account has not verified its email address")
+)
+
+// OidcCallbackHandler finishes logging in with an OpenID Connect provider. It checks the state against the cookie
+// set by OidcLoginHandler, exchanges the code for a verified ID token, and signs in the user linked to that identity,
+// linking or creating a user by verified email address the first time. Failures redirect to the frontend's
+// /login page with an error query parameter, since the user arrives here in a browser.
+func OidcCallbackHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		// The state cookie is single use, whatever the outcome
+		c.SetCookie(oidcStateCookie("", -1))
+
+		fail := func(reason string, err error) error {
+			app.Logger.Warn("OIDC login failed", "provider", c.Param("provider"), "reason", reason, "error", err)
+			return c.Redirect(http.StatusFound, fmt.Sprintf("%s/login?error=%s", app.Config.FrontendURL, url.QueryEscape(reason)))
+		}
+
+		provider, ok := app.OIDCProviders[c.Param("provider")]
+		if !ok {
+			return fail("oidc_unknown_provider", nil)
+		}
+
+		if providerError := c.QueryParam("error"); providerError != "" {
+			return fail("oidc_denied", errors.New(providerError))
+		}
+
+		stateCookie, err := c.Cookie(oidcStateCookieName)
+		if err != nil {
+			return fail("oidc_invalid_state", err)
+		}
+
+		claims, err := app.JWTService.ParsePurposeToken(stateCookie.Value, oidcStateTokenPurpose)
+		if err != nil {
+			return fail("oidc_invalid_state", err)
+		}
+
+		stateProvider, _ := claims["provider"].(string)
+		state, _ := claims["state"].(string)
+		nonce, _ := claims["nonce"].(string)
+		verifier, _ := claims["verifier"].(string)
+		rememberMe, _ := claims["rememberMe"].(bool)
+
+		if stateProvider != provider.Config.Name || state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(c.QueryParam("state"))) != 1 {
+			return fail("oidc_invalid_state", nil)
+		}
+
+		tokens, err := provider.Exchange(ctx, c.QueryParam("code"), verifier)
+		if err != nil {
+			return fail("oidc_failed", err)
+		}
+
+		idTokenClaims, err := provider.VerifyIDToken(ctx, tokens.IDToken, nonce)
+		if err != nil {
+			return fail("oidc_failed", err)
+		}
+
+		user, err := findOrCreateOidcUser(ctx, app, provider.Config.Name, idTokenClaims)
+		if errors.Is(err, errOidcEmailNotVerified) {
+			return fail("oidc_email_not_verified", err)
+		}
+		if errors.Is(err, errOidcAccountNotVerified) {
+			return fail("oidc_account_not_verified", err)
+		}
+		if err != nil {
+			return err
+		}
+
+		return completeRedirectLogin(c, app, user, rememberMe)
+	}
+}
+
+// findOrCreateOidcUser returns the user linked to the provider identity. An unlinked identity is linked to the
+// user with the same email address, or to a new user, but only if the provider says it has verified that address.
+// An existing user is only linked once they have verified the address themselves too. Otherwise anyone could
+// register with someone else's address and a password they know, and be let into the account the owner later
+// signs in to with their provider.
+func findOrCreateOidcUser(ctx context.Context, app *application.Application, provider string, claims *oidcHelper.IDTokenClaims) (database.User, error) {
+	identity, err := app.Identities.GetByProviderSubject(ctx, provider, claims.Subject)
+	if err == nil {
+		if err := app.Identities.RecordLogin(ctx, identity.ID, claims.Email); err != nil {
+			return database.User{}, err
+		}
+		return app.Users.FindUser(ctx, identity.UserID)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return database.User{}, err
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		return database.User{}, errOidcEmailNotVerified
+	}
+
+	user, err := app.Users.GetByUsername(ctx, claims.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Users created from a provider have a random password they don't know, until they reset it
+		password, _, err := tokenHelper.Generate()
+		if err != nil {
+			return database.User{}, err
+		}
+
+		passwordHash, err := app.Passwords.Hash(password)
+		if err != nil {
+			return database.User{}, err
+		}
+
+		userId, err := app.Users.Create(ctx, claims.Email, passwordHash)
+		if err != nil {
+			return database.User{}, err
+		}
+
+		user, err = app.Users.FindUser(ctx, userId)
+		if err != nil {
+			return database.User{}, err
+		}
+
+		// The provider has verified the user owns this address
+		if err := app.Users.MarkEmailVerified(ctx, user.ID); err != nil {
+			return database.User{}, err
+		}
+	} else if err != nil {
+		return database.User{}, err
+	} else if user.EmailVerifiedAt == nil {
+		return database.User{}, errOidcAccountNotVerified
+	}
+
+	if err := app.Identities.Create(ctx, user.ID, provider, claims.Subject, claims.Email); err != nil {
+		return database.User{}, err
+	}
+
+	return app.Users.FindUser(ctx, user.ID)
+}
@@ -0,0 +1,203 @@
+package AuthHandler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/oidcHelper"
+	"github.com/nathanjms/go-api-template/internal/testHelper"
+)
+
+const oidcRedirectURL = "http://localhost:8080/login/oidc/mock/callback"
+
+func newOidcApp(t *testing.T) (*application.Application, *testHelper.OIDCProvider) {
+	t.Helper()
+
+	mock := testHelper.NewOIDCProvider(t)
+	cfg := testHelper.Config(t)
+	cfg.OIDC = []oidcHelper.ProviderConfig{mock.Config("mock", oidcRedirectURL)}
+	app, _ := testHelper.NewAppWithConfig(t, cfg)
+
+	return app, mock
+}
+
+// startOidcLogin calls OidcLoginHandler, returning the provider's authorization URL and the state cookie
+func startOidcLogin(t *testing.T, app *application.Application) (string, *http.Cookie) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/login/oidc/mock", nil)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("provider")
+	c.SetParamValues("mock")
+
+	if err := OidcLoginHandler(app)(c); err != nil {
+		t.Fatalf("OidcLoginHandler: %v", err)
+	}
+	if rec.Code != http.StatusFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusFound)
+	}
+
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == oidcStateCookieName {
+			return rec.Header().Get(echo.HeaderLocation), cookie
+		}
+	}
+	t.Fatal("no state cookie")
+
+	return "", nil
+}
+
+// oidcCallback calls OidcCallbackHandler as the provider's redirect would, returning where it redirects the browser
+func oidcCallback(t *testing.T, app *application.Application, stateCookie *http.Cookie, code string, state string) string {
+	t.Helper()
+
+	query := url.Values{"code": {code}, "state": {state}}
+	req := httptest.NewRequest(http.MethodGet, "/login/oidc/mock/callback?"+query.Encode(), nil)
+	req.AddCookie(stateCookie)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("provider")
+	c.SetParamValues("mock")
+
+	if err := OidcCallbackHandler(app)(c); err != nil {
+		t.Fatalf("OidcCallbackHandler: %v", err)
+	}
+	if rec.Code != http.StatusFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusFound)
+	}
+
+	return rec.Header().Get(echo.HeaderLocation)
+}
+
+// oidcLogin signs in to the provider as the identity and follows the redirect back to the callback
+func oidcLogin(t *testing.T, app *application.Application, mock *testHelper.OIDCProvider, identity testHelper.OIDCIdentity) string {
+	t.Helper()
+
+	authURL, stateCookie := startOidcLogin(t, app)
+	code, state := mock.Authorize(t, authURL, identity)
+
+	return oidcCallback(t, app, stateCookie, code, state)
+}
+
+func TestOidcCallbackCreatesAndLinksUser(t *testing.T) {
+	ctx := context.Background()
+	app, mock := newOidcApp(t)
+	identity := testHelper.OIDCIdentity{Subject: "subject", Email: "new@example.com", EmailVerified: true}
+
+	if location := oidcLogin(t, app, mock, identity); location != app.Config.FrontendURL {
+		t.Fatalf("redirected to %s, want %s", location, app.Config.FrontendURL)
+	}
+
+	user, err := app.Users.GetByUsername(ctx, "new@example.com")
+	if err != nil {
+		t.Fatalf("no user created: %v", err)
+	}
+	if user.EmailVerifiedAt == nil {
+		t.Error("email not marked verified")
+	}
+	linked, err := app.Identities.GetByProviderSubject(ctx, "mock", "subject")
+	if err != nil || linked.UserID != user.ID {
+		t.Fatalf("identity = %+v, %v, want linked to user %d", linked, err, user.ID)
+	}
+
+	// The identity now decides the user, even once the email address at the provider changes
+	identity.Email = "changed@example.com"
+	if location := oidcLogin(t, app, mock, identity); location != app.Config.FrontendURL {
+		t.Fatalf("redirected to %s, want %s", location, app.Config.FrontendURL)
+	}
+	if _, err := app.Users.GetByUsername(ctx, "changed@example.com"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("second login created another user: %v", err)
+	}
+}
+
+func TestOidcCallbackLinksExistingUserByVerifiedEmail(t *testing.T) {
+	ctx := context.Background()
+	app, mock := newOidcApp(t)
+	user := testHelper.CreateUser(t, app, "user@example.com", "correct horse battery staple")
+	if err := app.Users.MarkEmailVerified(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	oidcLogin(t, app, mock, testHelper.OIDCIdentity{Subject: "subject", Email: "user@example.com", EmailVerified: true})
+
+	linked, err := app.Identities.GetByProviderSubject(ctx, "mock", "subject")
+	if err != nil || linked.UserID != user.ID {
+		t.Fatalf("identity = %+v, %v, want linked to user %d", linked, err, user.ID)
+	}
+}
+
+func TestOidcCallbackRefusesToLinkUnverifiedAccount(t *testing.T) {
+	ctx := context.Background()
+	app, mock := newOidcApp(t)
+	// Someone else registered the address, with a password they know, and never verified it
+	user := testHelper.CreateUser(t, app, "user@example.com", "correct horse battery staple")
+
+	location := oidcLogin(t, app, mock, testHelper.OIDCIdentity{Subject: "subject", Email: "user@example.com", EmailVerified: true})
+	if !strings.HasSuffix(location, "/login?error=oidc_account_not_verified") {
+		t.Errorf("redirected to %s, want the account not verified error", location)
+	}
+
+	if _, err := app.Identities.GetByProviderSubject(ctx, "mock", "subject"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("identity linked to the unverified account: %v", err)
+	}
+	user, err := app.Users.FindUser(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.EmailVerifiedAt != nil {
+		t.Error("unverified account marked verified")
+	}
+}
+
+func TestOidcCallbackRefusesUnverifiedEmail(t *testing.T) {
+	ctx := context.Background()
+	app, mock := newOidcApp(t)
+	user := testHelper.CreateUser(t, app, "user@example.com", "correct horse battery staple")
+
+	location := oidcLogin(t, app, mock, testHelper.OIDCIdentity{Subject: "subject", Email: "user@example.com"})
+	if !strings.HasSuffix(location, "/login?error=oidc_email_not_verified") {
+		t.Errorf("redirected to %s, want the email not verified error", location)
+	}
+
+	if _, err := app.Identities.GetByProviderSubject(ctx, "mock", "subject"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("unverified identity linked to user %d: %v", user.ID, err)
+	}
+}
+
+func TestOidcCallbackRejectsWrongState(t *testing.T) {
+	app, mock := newOidcApp(t)
+	identity := testHelper.OIDCIdentity{Subject: "subject", Email: "user@example.com", EmailVerified: true}
+
+	authURL, stateCookie := startOidcLogin(t, app)
+	code, _ := mock.Authorize(t, authURL, identity)
+
+	location := oidcCallback(t, app, stateCookie, code, "forged")
+	if !strings.HasSuffix(location, "/login?error=oidc_invalid_state") {
+		t.Errorf("redirected to %s, want the invalid state error", location)
+	}
+}
+
+func TestOidcCallbackRejectsAnotherLoginsCode(t *testing.T) {
+	app, mock := newOidcApp(t)
+	identity := testHelper.OIDCIdentity{Subject: "subject", Email: "user@example.com", EmailVerified: true}
+
+	// An attacker's code, injected into the victim's callback with the victim's own state, fails PKCE
+	attackerURL, _ := startOidcLogin(t, app)
+	attackerCode, _ := mock.Authorize(t, attackerURL, identity)
+	victimURL, victimCookie := startOidcLogin(t, app)
+	_, victimState := mock.Authorize(t, victimURL, identity)
+
+	location := oidcCallback(t, app, victimCookie, attackerCode, victimState)
+	if !strings.HasSuffix(location, "/login?error=oidc_failed") {
+		t.Errorf("redirected to %s, want the failed error", location)
+	}
+}
@@ -0,0 +1,92 @@
+package AuthHandler
+
+import (
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/oidcHelper"
+)
+
+const (
+	oidcStateCookieName   = "oidc_state"
+	oidcStateTokenPurpose = "oidc_state"
+	oidcStateTTL          = 10 * time.Minute
+)
+
+type OidcLoginJsonRequest struct {
+	RememberMe bool `query:"rememberMe"`
+}
+
+// OidcLoginHandler starts logging in with an OpenID Connect provider, redirecting the browser to the provider.
+// The state, nonce and PKCE verifier are kept in a short-lived signed cookie until the provider redirects back.
+func OidcLoginHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		provider, ok := app.OIDCProviders[c.Param("provider")]
+		if !ok {
+			return c.JSON(http.StatusNotFound, application.Response{
+				Success: false,
+				Message: "Unknown login provider",
+			})
+		}
+
+		oidcLoginRequest := new(OidcLoginJsonRequest)
+		if err := c.Bind(oidcLoginRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing request",
+			})
+		}
+
+		state, err := oidcHelper.RandomString()
+		if err != nil {
+			return err
+		}
+
+		nonce, err := oidcHelper.RandomString()
+		if err != nil {
+			return err
+		}
+
+		verifier, challenge, err := oidcHelper.GeneratePKCE()
+		if err != nil {
+			return err
+		}
+
+		authURL, err := provider.AuthCodeURL(c.Request().Context(), state, nonce, challenge)
+		if err != nil {
+			return err
+		}
+
+		stateToken, err := app.JWTService.CreatePurposeToken(oidcStateTokenPurpose, map[string]interface{}{
+			"provider":   provider.Config.Name,
+			"state":      state,
+			"nonce":      nonce,
+			"verifier":   verifier,
+			"rememberMe": oidcLoginRequest.RememberMe,
+		}, oidcStateTTL)
+		if err != nil {
+			return err
+		}
+
+		c.SetCookie(oidcStateCookie(stateToken, int(oidcStateTTL.Seconds())))
+
+		return c.Redirect(http.StatusFound, authURL)
+	}
+}
+
+// oidcStateCookie holds the login state between the redirect to the provider and its callback. It must be
+// SameSite=Lax (not Strict) so it is still sent when the provider redirects the browser back to us.
+func oidcStateCookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     oidcStateCookieName,
+		Value:    value,
+		Path:     "/login/oidc",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   os.Getenv("ENV") != "local",
+		SameSite: http.SameSiteLaxMode,
+	}
+}
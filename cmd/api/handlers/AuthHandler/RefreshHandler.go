@@ -0,0 +1,143 @@
+package AuthHandler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/jwtHelper"
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
+)
+
+var (
+	// errRefreshTokenUsed means another request used the refresh token first
+	errRefreshTokenUsed = errors.New("refresh token already used")
+	// errSessionGone means the refresh token's session has been deleted, so it is signed out
+	errSessionGone = errors.New("session no longer exists")
+)
+
+type RefreshJsonRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// RefreshHandler exchanges a refresh token for a new access token and refresh token.
+// Each refresh token can only be used once; presenting one again revokes the whole family.
+//
+// Cookie clients send the refresh_token cookie, Bearer clients send refreshToken in the body and get the new tokens back in the response.
+func RefreshHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		unauthorized := func() error {
+			clearSessionCookies(c, app)
+			return c.JSON(http.StatusUnauthorized, application.Response{
+				Success: false,
+				Message: "Unauthorized",
+			})
+		}
+
+		plainToken, tokenResponse := refreshTokenFromRequest(c)
+		if plainToken == "" {
+			return unauthorized()
+		}
+
+		refreshToken, err := app.RefreshTokens.GetByHash(ctx, tokenHelper.Hash(plainToken))
+		if err != nil {
+			return unauthorized()
+		}
+
+		if refreshToken.RevokedAt.Valid || time.Now().After(refreshToken.ExpiresAt) {
+			return unauthorized()
+		}
+
+		if refreshToken.UsedAt.Valid {
+			return revokeReusedFamily(c, app, refreshToken, unauthorized)
+		}
+
+		user, err := app.Users.FindUser(ctx, refreshToken.UserID)
+		if err != nil {
+			return unauthorized()
+		}
+
+		// Using up the old token, extending the session and saving the new token happen together, so if any of it
+		// fails the old token is still unused and the client can try again without tripping reuse detection
+		var tokens *sessionTokens
+		err = app.WithTx(ctx, func(tx database.Stores) error {
+			marked, err := tx.RefreshTokens.MarkUsed(ctx, refreshToken.ID)
+			if err != nil {
+				return err
+			}
+			if !marked {
+				return errRefreshTokenUsed
+			}
+
+			extended, err := tx.Sessions.Extend(ctx, refreshToken.FamilyID, c.RealIP(), app.JWTService.RefreshTokenExpiry(refreshToken.RememberMe))
+			if err != nil {
+				return err
+			}
+			if !extended {
+				return errSessionGone
+			}
+
+			tokens, err = createSessionTokens(ctx, app, tx.RefreshTokens, user.ID, user.Username, refreshToken.RememberMe, refreshToken.FamilyID)
+			return err
+		})
+		if errors.Is(err, errRefreshTokenUsed) {
+			// Lost a race with another request presenting the same token
+			return revokeReusedFamily(c, app, refreshToken, unauthorized)
+		}
+		if errors.Is(err, errSessionGone) {
+			return unauthorized()
+		}
+		if err != nil {
+			return err
+		}
+
+		data, err := sendSessionTokens(c, app, tokens, refreshToken.RememberMe, tokenResponse)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Success",
+			Data:    data,
+		})
+	}
+}
+
+// revokeReusedFamily handles a refresh token being presented more than once, which means it has
+// most likely been stolen, so every token in the family is revoked to force a fresh login.
+func revokeReusedFamily(c echo.Context, app *application.Application, refreshToken database.RefreshToken, unauthorized func() error) error {
+	ctx := c.Request().Context()
+	app.Logger.Warn("Refresh token reuse detected, revoking token family", "userId", refreshToken.UserID, "familyId", refreshToken.FamilyID)
+
+	if err := app.RefreshTokens.RevokeFamily(ctx, refreshToken.FamilyID); err != nil {
+		return err
+	}
+
+	// Access tokens already issued to the session must stop working too
+	if _, err := app.Revocations.RevokeSession(ctx, refreshToken.UserID, refreshToken.FamilyID); err != nil {
+		return err
+	}
+
+	return unauthorized()
+}
+
+// refreshTokenFromRequest returns the refresh token from the JSON body if there is one, otherwise from the
+// refresh_token cookie. fromBody reports whether the client is using Bearer auth.
+func refreshTokenFromRequest(c echo.Context) (token string, fromBody bool) {
+	request := new(RefreshJsonRequest)
+	if err := c.Bind(request); err == nil && request.RefreshToken != "" {
+		return request.RefreshToken, true
+	}
+
+	cookie, err := c.Cookie(jwtHelper.RefreshCookieName)
+	if err != nil {
+		return "", false
+	}
+
+	return cookie.Value, false
+}
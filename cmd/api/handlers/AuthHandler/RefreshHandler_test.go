@@ -0,0 +1,119 @@
+package AuthHandler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/testHelper"
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
+)
+
+// newRefreshToken starts a session for a new user, returning its refresh token
+func newRefreshToken(t *testing.T, app *application.Application) string {
+	t.Helper()
+	ctx := context.Background()
+
+	user := testHelper.CreateUser(t, app, "user@example.com", "correct horse battery staple")
+	expiresAt := time.Now().Add(time.Hour)
+	if err := app.Sessions.Create(ctx, "session", user.ID, "test", "192.0.2.1", expiresAt); err != nil {
+		t.Fatal(err)
+	}
+
+	token, tokenHash, err := tokenHelper.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.RefreshTokens.Create(ctx, user.ID, "session", tokenHash, false, expiresAt); err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
+
+// refresh calls RefreshHandler as a Bearer client, returning the status and the new refresh token
+func refresh(t *testing.T, ctx context.Context, app *application.Application, refreshToken string) (int, string) {
+	t.Helper()
+
+	req := httptest.NewRequestWithContext(ctx, http.MethodPost, "/refresh", strings.NewReader(`{"refreshToken":"`+refreshToken+`"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	if err := RefreshHandler(app)(echo.New().NewContext(req, rec)); err != nil {
+		return http.StatusInternalServerError, ""
+	}
+
+	var response struct {
+		Data struct {
+			RefreshToken string `json:"refreshToken"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+
+	return rec.Code, response.Data.RefreshToken
+}
+
+func TestRefreshHandlerRotatesTokens(t *testing.T) {
+	ctx := context.Background()
+	app, _ := testHelper.NewApp(t)
+	first := newRefreshToken(t, app)
+
+	status, second := refresh(t, ctx, app, first)
+	if status != http.StatusOK || second == "" {
+		t.Fatalf("refresh = %d, %q, want a new token", status, second)
+	}
+
+	// Presenting the used token again revokes the family, including the token it was exchanged for
+	if status, _ := refresh(t, ctx, app, first); status != http.StatusUnauthorized {
+		t.Errorf("reusing a refresh token = %d, want %d", status, http.StatusUnauthorized)
+	}
+	if status, _ := refresh(t, ctx, app, second); status != http.StatusUnauthorized {
+		t.Errorf("refresh after reuse = %d, want %d", status, http.StatusUnauthorized)
+	}
+}
+
+func TestRefreshHandlerFailureKeepsTokenUsable(t *testing.T) {
+	app, _ := testHelper.NewApp(t)
+	token := newRefreshToken(t, app)
+
+	// The request ends before the refresh is saved, so none of it happens
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if status, _ := refresh(t, canceled, app, token); status == http.StatusOK {
+		t.Fatal("refresh succeeded with a canceled request")
+	}
+
+	if status, next := refresh(t, context.Background(), app, token); status != http.StatusOK || next == "" {
+		t.Errorf("retrying the refresh = %d, %q, want a new token rather than reuse detection", status, next)
+	}
+}
+
+func TestRefreshHandlerRejectsTokenWithoutSession(t *testing.T) {
+	ctx := context.Background()
+	app, _ := testHelper.NewApp(t)
+	user := testHelper.CreateUser(t, app, "user@example.com", "correct horse battery staple")
+
+	// The family's session is gone, such as once it has expired and been deleted
+	token, tokenHash, err := tokenHelper.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.RefreshTokens.Create(ctx, user.ID, "deleted session", tokenHash, false, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if status, _ := refresh(t, ctx, app, token); status != http.StatusUnauthorized {
+		t.Errorf("refresh = %d, want %d", status, http.StatusUnauthorized)
+	}
+	if sessions, _ := app.Sessions.GetActiveByUserId(ctx, user.ID); len(sessions) != 0 {
+		t.Errorf("refresh created sessions %+v", sessions)
+	}
+}
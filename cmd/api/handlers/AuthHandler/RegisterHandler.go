@@ -2,7 +2,6 @@ package AuthHandler
 
 import (
 	"net/http"
-	"regexp"
 
 	"github.com/labstack/echo/v4"
 	"github.com/nathanjms/go-api-template/internal/application"
@@ -17,6 +16,7 @@ type RegisterJsonUser struct {
 
 func RegisterHandler(app *application.Application) echo.HandlerFunc {
 	return func(c echo.Context) error {
+		ctx := c.Request().Context()
 		newUserRequest := new(RegisterJsonUser)
 		if err := c.Bind(newUserRequest); err != nil {
 			return c.JSON(http.StatusBadRequest, application.Response{
@@ -34,30 +34,16 @@ func RegisterHandler(app *application.Application) echo.HandlerFunc {
 			})
 		}
 
-		if len(newUserRequest.Password) < 8 {
-			return c.JSON(http.StatusUnprocessableEntity, application.Response{
-				Success: false,
-				Message: "Password must be at least 8 characters",
-				Errors:  map[string][]string{"password": {"Password must be at least 8 characters"}},
-			})
+		errorResponse, err := validateNewPassword(app, newUserRequest.Password, newUserRequest.PasswordConfirm, newUserRequest.Username)
+		if err != nil {
+			return err
 		}
-
-		if newUserRequest.Password != newUserRequest.PasswordConfirm {
-			return c.JSON(http.StatusUnprocessableEntity, application.Response{
-				Success: false,
-				Message: "Passwords do not match",
-				Errors:  map[string][]string{"passwordConfirm": {"Passwords do not match"}},
-			})
+		if errorResponse != nil {
+			return c.JSON(http.StatusUnprocessableEntity, errorResponse)
 		}
 
-		// Define the regular expression for email validation
-		validRegex := `^[a-zA-Z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[a-zA-Z0-9-]+(?:\.[a-zA-Z0-9-]+)*$`
-
-		// Compile the regular expression
-		re := regexp.MustCompile(validRegex)
-
 		// Check if the username (email) is valid
-		if !re.MatchString(newUserRequest.Username) {
+		if !isValidEmail(newUserRequest.Username) {
 			return c.JSON(http.StatusUnprocessableEntity, application.Response{
 				Success: false,
 				Message: "Invalid email address",
@@ -66,7 +52,7 @@ func RegisterHandler(app *application.Application) echo.HandlerFunc {
 		}
 
 		// Ensure does not exist:
-		_, err := app.DB.UserModel.GetByUsername(newUserRequest.Username)
+		_, err = app.Users.GetByUsername(ctx, newUserRequest.Username)
 		if err == nil {
 			return c.JSON(http.StatusUnprocessableEntity, application.Response{
 				Success: false,
@@ -75,20 +61,24 @@ func RegisterHandler(app *application.Application) echo.HandlerFunc {
 			})
 		}
 
-		var newUserId int64
-		newUserId, err = app.DB.UserModel.Create(newUserRequest.Username, newUserRequest.Password)
+		passwordHash, err := app.Passwords.Hash(newUserRequest.Password)
 		if err != nil {
 			return err
 		}
 
-		jwtCookie, err := app.JWTService.CreateJwtCookie(newUserId, newUserRequest.Username, newUserRequest.RememberMe)
-
+		newUserId, err := app.Users.Create(ctx, newUserRequest.Username, passwordHash)
 		if err != nil {
 			return err
 		}
 
-		// Set the cookie:
-		c.SetCookie(jwtCookie)
+		// The account is still usable if this fails, and the user can ask for the email to be resent
+		if err := sendVerificationEmail(ctx, app, newUserId, newUserRequest.Username); err != nil {
+			app.ReportError(err)
+		}
+
+		if _, err := startSession(c, app, newUserId, newUserRequest.Username, newUserRequest.RememberMe, false); err != nil {
+			return err
+		}
 
 		return c.JSON(http.StatusOK, application.Response{
 			Success: true,
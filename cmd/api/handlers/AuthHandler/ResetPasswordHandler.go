@@ -0,0 +1,88 @@
+package AuthHandler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
+)
+
+type ResetPasswordJsonRequest struct {
+	Token           string `json:"token"`
+	Password        string `json:"password"`
+	PasswordConfirm string `json:"passwordConfirm"`
+}
+
+// ResetPasswordHandler sets a new password using a token from ForgotPasswordHandler, signing the user out everywhere
+func ResetPasswordHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		resetPasswordRequest := new(ResetPasswordJsonRequest)
+		if err := c.Bind(resetPasswordRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing JSON",
+			})
+		}
+
+		invalidToken := func() error {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "This password reset link is invalid or has expired",
+				Errors:  map[string][]string{"token": {"This password reset link is invalid or has expired"}},
+			})
+		}
+
+		if resetPasswordRequest.Token == "" {
+			return invalidToken()
+		}
+
+		userToken, err := app.UserTokens.GetValid(ctx, database.UserTokenPasswordReset, tokenHelper.Hash(resetPasswordRequest.Token))
+		if err != nil {
+			return invalidToken()
+		}
+
+		user, err := app.Users.FindUser(ctx, userToken.UserID)
+		if err != nil {
+			return invalidToken()
+		}
+
+		// Checked before using up the token, so the user can try again with a different password
+		errorResponse, err := validateNewPassword(app, resetPasswordRequest.Password, resetPasswordRequest.PasswordConfirm, user.Username)
+		if err != nil {
+			return err
+		}
+		if errorResponse != nil {
+			return c.JSON(http.StatusUnprocessableEntity, errorResponse)
+		}
+
+		used, err := app.UserTokens.MarkUsed(ctx, userToken.ID)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return invalidToken()
+		}
+
+		passwordHash, err := app.Passwords.Hash(resetPasswordRequest.Password)
+		if err != nil {
+			return err
+		}
+
+		if err := app.Users.UpdatePassword(ctx, userToken.UserID, passwordHash); err != nil {
+			return err
+		}
+
+		// Whoever had access to the account before the reset should not keep it
+		if err := app.Revocations.RevokeAllForUser(ctx, userToken.UserID); err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Password has been reset",
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package AuthHandler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/mailer"
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
+)
+
+const (
+	verificationResendInterval = time.Minute
+	verificationMaxPerHour     = 5
+)
+
+type VerifyEmailJsonRequest struct {
+	Token string `json:"token" query:"token"`
+}
+
+// VerifyEmailHandler marks the user's email address as verified using the token emailed to them.
+// It accepts the token either as a ?token= query parameter (GET) or in the JSON body (POST).
+func VerifyEmailHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		verifyEmailRequest := new(VerifyEmailJsonRequest)
+		if err := c.Bind(verifyEmailRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing JSON",
+			})
+		}
+
+		invalidToken := func() error {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "This verification link is invalid or has expired",
+				Errors:  map[string][]string{"token": {"This verification link is invalid or has expired"}},
+			})
+		}
+
+		if verifyEmailRequest.Token == "" {
+			return invalidToken()
+		}
+
+		userToken, err := app.UserTokens.GetValid(ctx, database.UserTokenEmailVerification, tokenHelper.Hash(verifyEmailRequest.Token))
+		if err != nil {
+			return invalidToken()
+		}
+
+		used, err := app.UserTokens.MarkUsed(ctx, userToken.ID)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return invalidToken()
+		}
+
+		if err := app.Users.MarkEmailVerified(ctx, userToken.UserID); err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Email address verified",
+		})
+	}
+}
+
+// ResendVerificationEmailHandler sends the logged in user a new verification link, at most once a minute and five times an hour
+func ResendVerificationEmailHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+
+		user, err := app.Users.FindUser(ctx, userId)
+		if err != nil {
+			return err
+		}
+
+		if user.EmailVerifiedAt != nil {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "Email address is already verified",
+			})
+		}
+
+		sentRecently, err := app.UserTokens.CountCreatedSince(ctx, user.ID, database.UserTokenEmailVerification, time.Now().Add(-verificationResendInterval))
+		if err != nil {
+			return err
+		}
+
+		sentThisHour, err := app.UserTokens.CountCreatedSince(ctx, user.ID, database.UserTokenEmailVerification, time.Now().Add(-time.Hour))
+		if err != nil {
+			return err
+		}
+
+		if sentRecently > 0 || sentThisHour >= verificationMaxPerHour {
+			retryAfter := verificationResendInterval
+			if sentThisHour >= verificationMaxPerHour {
+				retryAfter = time.Hour
+			}
+
+			c.Response().Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+			return c.JSON(http.StatusTooManyRequests, application.Response{
+				Success: false,
+				Message: "A verification email was sent recently, please wait before requesting another",
+			})
+		}
+
+		if err := sendVerificationEmail(ctx, app, user.ID, user.Username); err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Verification email sent",
+		})
+	}
+}
+
+// sendVerificationEmail emails the user a link to verify their address, replacing any link sent previously
+func sendVerificationEmail(ctx context.Context, app *application.Application, userId int64, email string) error {
+	if err := app.UserTokens.InvalidateForUser(ctx, userId, database.UserTokenEmailVerification); err != nil {
+		return err
+	}
+
+	token, tokenHash, err := tokenHelper.Generate()
+	if err != nil {
+		return err
+	}
+
+	if err := app.UserTokens.Create(ctx, userId, database.UserTokenEmailVerification, tokenHash, "", time.Now().Add(app.Config.EmailVerificationTTL)); err != nil {
+		return err
+	}
+
+	verifyLink := fmt.Sprintf("%s/verify-email?token=%s", app.Config.FrontendURL, url.QueryEscape(token))
+
+	return app.Mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Thanks for signing up!\n\nPlease confirm your email address by opening the link below within %s:\n\n%s\n\nIf you didn't create an account, you can ignore this email.",
+			app.Config.EmailVerificationTTL,
+			verifyLink,
+		),
+	})
+}
@@ -0,0 +1,10 @@
+package AuthHandler
+
+import "regexp"
+
+var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[a-zA-Z0-9-]+(?:\.[a-zA-Z0-9-]+)*$`)
+
+// isValidEmail checks an email address, which is also the user's username
+func isValidEmail(email string) bool {
+	return emailRegex.MatchString(email)
+}
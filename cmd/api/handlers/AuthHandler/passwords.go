@@ -0,0 +1,75 @@
+package AuthHandler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+)
+
+// validateNewPassword checks a password being set by the user against the password policy, returning the error
+// response to send if it is not acceptable. Every rule the password breaks is listed in Errors.
+func validateNewPassword(app *application.Application, password string, passwordConfirm string, email string) (*application.Response, error) {
+	problems, err := app.PasswordPolicy.Validate(password, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(problems) > 0 {
+		return &application.Response{
+			Success: false,
+			Message: problems[0],
+			Errors:  map[string][]string{"password": problems},
+		}, nil
+	}
+
+	if password != passwordConfirm {
+		return &application.Response{
+			Success: false,
+			Message: "Passwords do not match",
+			Errors:  map[string][]string{"passwordConfirm": {"Passwords do not match"}},
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// rehashPassword stores a new hash of the password using the currently configured algorithm and parameters
+func rehashPassword(ctx context.Context, app *application.Application, userId int64, password string) error {
+	passwordHash, err := app.Passwords.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	return app.Users.UpdatePassword(ctx, userId, passwordHash)
+}
+
+// checkCurrentPassword re-checks the logged in user's password before a sensitive change, responding with 422 and
+// reporting false if it is wrong. Wrong guesses count towards the same lockout as logging in.
+func checkCurrentPassword(c echo.Context, app *application.Application, user database.User, password string) (bool, error) {
+	accountKey := accountThrottleKey(user.Username)
+	if throttled, err := checkThrottle(c, app, accountKey); throttled || err != nil {
+		return false, err
+	}
+
+	match, _, err := app.Passwords.Verify(password, user.Password)
+	if err != nil {
+		return false, err
+	}
+
+	if !match {
+		if err := recordFailure(c, app, accountKey); err != nil {
+			return false, err
+		}
+
+		return false, c.JSON(http.StatusUnprocessableEntity, application.Response{
+			Success: false,
+			Message: "Incorrect password",
+			Errors:  map[string][]string{"currentPassword": {"Incorrect password"}},
+		})
+	}
+
+	return true, nil
+}
@@ -0,0 +1,209 @@
+package AuthHandler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/jwtHelper"
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
+)
+
+const (
+	mfaTokenPurpose = "mfa"
+	mfaTokenTTL     = 5 * time.Minute
+)
+
+// completeLogin finishes a login once the user's first factor has been checked. Users with two-factor
+// authentication enabled get a short-lived MFA token to exchange at POST /login/mfa instead of a session.
+func completeLogin(c echo.Context, app *application.Application, user database.User, rememberMe bool, tokenResponse bool) error {
+	if user.TOTPEnabledAt != nil {
+		mfaToken, err := createMfaToken(app, user, rememberMe, tokenResponse)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Two-factor authentication required",
+			Data: application.ResponseData{
+				"mfaRequired": true,
+				"mfaToken":    mfaToken,
+			},
+		})
+	}
+
+	return loginSuccess(c, app, user, rememberMe, tokenResponse)
+}
+
+// completeRedirectLogin is completeLogin for login flows that finish with a browser redirect, such as OpenID Connect.
+// The user is sent to the frontend, or to its /login/mfa page with the MFA token if two-factor authentication is enabled.
+func completeRedirectLogin(c echo.Context, app *application.Application, user database.User, rememberMe bool) error {
+	if user.TOTPEnabledAt != nil {
+		mfaToken, err := createMfaToken(app, user, rememberMe, false)
+		if err != nil {
+			return err
+		}
+
+		return c.Redirect(http.StatusFound, fmt.Sprintf("%s/login/mfa?mfaToken=%s", app.Config.FrontendURL, url.QueryEscape(mfaToken)))
+	}
+
+	if _, err := startSession(c, app, user.ID, user.Username, rememberMe, false); err != nil {
+		return err
+	}
+
+	return c.Redirect(http.StatusFound, app.Config.FrontendURL)
+}
+
+func createMfaToken(app *application.Application, user database.User, rememberMe bool, tokenResponse bool) (string, error) {
+	return app.JWTService.CreatePurposeToken(mfaTokenPurpose, map[string]interface{}{
+		"userId":        user.ID,
+		"rememberMe":    rememberMe,
+		"tokenResponse": tokenResponse,
+	}, mfaTokenTTL)
+}
+
+// loginSuccess starts a session for a fully authenticated user and sends the login response
+func loginSuccess(c echo.Context, app *application.Application, user database.User, rememberMe bool, tokenResponse bool) error {
+	data, err := startSession(c, app, user.ID, user.Username, rememberMe, tokenResponse)
+	if err != nil {
+		return err
+	}
+
+	data["id"] = user.ID
+	data["username"] = user.Username
+
+	return c.JSON(http.StatusOK, application.Response{
+		Success: true,
+		Message: "Success",
+		Data:    data,
+	})
+}
+
+// sessionTokens is an access token along with the refresh token that can be used to replace it
+type sessionTokens struct {
+	accessToken        string
+	accessTokenExpiry  time.Time
+	refreshToken       string
+	refreshTokenExpiry time.Time
+}
+
+// startSession signs the user in, creating a session for this device and issuing an access token and a refresh
+// token from a brand new token family, named after the session. With tokenResponse the tokens are returned for
+// the JSON body (for Bearer clients) instead of being set as cookies.
+func startSession(c echo.Context, app *application.Application, userId int64, username string, rememberMe bool, tokenResponse bool) (application.ResponseData, error) {
+	ctx := c.Request().Context()
+	sessionId := uuid.NewString()
+	if err := app.Sessions.Create(ctx, sessionId, userId, c.Request().UserAgent(), c.RealIP(), app.JWTService.RefreshTokenExpiry(rememberMe)); err != nil {
+		return nil, err
+	}
+
+	return issueTokens(c, app, userId, username, rememberMe, sessionId, tokenResponse)
+}
+
+// signOutOtherSessions signs the user out of every session apart from the current one. Clients authenticated
+// without a session (API keys) are given a new session, which is returned for Bearer clients as in startSession.
+func signOutOtherSessions(c echo.Context, app *application.Application, user database.User) (application.ResponseData, error) {
+	ctx := c.Request().Context()
+	if sessionId, _ := c.Get("sessionId").(string); sessionId != "" {
+		return application.ResponseData{}, app.Revocations.RevokeOtherSessions(ctx, user.ID, sessionId)
+	}
+
+	rememberMe := false
+	if cookie, err := c.Cookie(jwtHelper.RefreshCookieName); err == nil {
+		if refreshToken, err := app.RefreshTokens.GetByHash(ctx, tokenHelper.Hash(cookie.Value)); err == nil && refreshToken.UserID == user.ID {
+			rememberMe = refreshToken.RememberMe
+		}
+	}
+
+	if err := app.Revocations.RevokeAllForUser(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	bearerAuth, _ := c.Get("bearerAuth").(bool)
+
+	return startSession(c, app, user.ID, user.Username, rememberMe, bearerAuth)
+}
+
+// issueTokens creates an access and refresh token for the session, persisting the refresh token under the session's family
+func issueTokens(c echo.Context, app *application.Application, userId int64, username string, rememberMe bool, sessionId string, tokenResponse bool) (application.ResponseData, error) {
+	tokens, err := createSessionTokens(c.Request().Context(), app, app.RefreshTokens, userId, username, rememberMe, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	return sendSessionTokens(c, app, tokens, rememberMe, tokenResponse)
+}
+
+// createSessionTokens creates an access and refresh token for the session, persisting the refresh token with
+// refreshTokens, which may be bound to a transaction
+func createSessionTokens(ctx context.Context, app *application.Application, refreshTokens database.RefreshTokenStore, userId int64, username string, rememberMe bool, sessionId string) (*sessionTokens, error) {
+	tokens := &sessionTokens{}
+
+	var err error
+	tokens.accessToken, tokens.accessTokenExpiry, err = app.JWTService.CreateAccessToken(userId, username, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, refreshTokenHash, err := tokenHelper.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	tokens.refreshToken = refreshToken
+	tokens.refreshTokenExpiry = app.JWTService.RefreshTokenExpiry(rememberMe)
+	if err := refreshTokens.Create(ctx, userId, sessionId, refreshTokenHash, rememberMe, tokens.refreshTokenExpiry); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// sendSessionTokens returns the tokens in the response data for Bearer clients, or sets them as cookies
+func sendSessionTokens(c echo.Context, app *application.Application, tokens *sessionTokens, rememberMe bool, tokenResponse bool) (application.ResponseData, error) {
+	if tokenResponse {
+		return application.ResponseData{
+			"tokenType":             "Bearer",
+			"accessToken":           tokens.accessToken,
+			"accessTokenExpiresAt":  tokens.accessTokenExpiry,
+			"refreshToken":          tokens.refreshToken,
+			"refreshTokenExpiresAt": tokens.refreshTokenExpiry,
+		}, nil
+	}
+
+	return application.ResponseData{}, setSessionCookies(c, app, tokens, rememberMe)
+}
+
+func setSessionCookies(c echo.Context, app *application.Application, tokens *sessionTokens, rememberMe bool) error {
+	jwtCookie, err := app.JWTService.CreateCookieFromToken(tokens.accessToken, rememberMe, tokens.accessTokenExpiry)
+	if err != nil {
+		return err
+	}
+
+	refreshCookie, err := app.JWTService.CreateRefreshCookie(tokens.refreshToken, rememberMe, tokens.refreshTokenExpiry)
+	if err != nil {
+		return err
+	}
+
+	c.SetCookie(jwtCookie)
+	c.SetCookie(refreshCookie)
+
+	return nil
+}
+
+// clearSessionCookies removes both the access and refresh token cookies from the client
+func clearSessionCookies(c echo.Context, app *application.Application) {
+	if clearCookie, err := app.JWTService.ClearCookie(); err == nil {
+		c.SetCookie(clearCookie)
+	}
+	if clearRefreshCookie, err := app.JWTService.ClearRefreshCookie(); err == nil {
+		c.SetCookie(clearRefreshCookie)
+	}
+}
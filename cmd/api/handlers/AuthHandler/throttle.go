@@ -0,0 +1,69 @@
+package AuthHandler
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+func accountThrottleKey(username string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+func mfaThrottleKey(userId int64) string {
+	return "mfa:" + strconv.FormatInt(userId, 10)
+}
+
+func ipThrottleKey(c echo.Context) string {
+	return "ip:" + c.RealIP()
+}
+
+// checkThrottle responds with 429 if the client's IP is being throttled, or 423 if the account is locked,
+// reporting whether it did. Handlers should return straight away when it returns true.
+func checkThrottle(c echo.Context, app *application.Application, accountKey string) (bool, error) {
+	ctx := c.Request().Context()
+	wait, err := app.IPThrottle.Check(ctx, ipThrottleKey(c))
+	if err != nil {
+		return false, err
+	}
+	if wait > 0 {
+		return true, throttledResponse(c, http.StatusTooManyRequests, "Too many login attempts, please try again later", wait)
+	}
+
+	wait, err = app.AccountThrottle.Check(ctx, accountKey)
+	if err != nil {
+		return false, err
+	}
+	if wait > 0 {
+		return true, throttledResponse(c, http.StatusLocked, "Too many failed login attempts, this account is temporarily locked", wait)
+	}
+
+	return false, nil
+}
+
+// recordFailure counts a failed attempt against both the account and the client's IP
+func recordFailure(c echo.Context, app *application.Application, accountKey string) error {
+	ctx := c.Request().Context()
+	if _, err := app.IPThrottle.Fail(ctx, ipThrottleKey(c)); err != nil {
+		return err
+	}
+
+	_, err := app.AccountThrottle.Fail(ctx, accountKey)
+	return err
+}
+
+func throttledResponse(c echo.Context, status int, message string, wait time.Duration) error {
+	c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	return c.JSON(status, application.Response{
+		Success: false,
+		Message: message,
+		Data: application.ResponseData{
+			"retryAfter": int(math.Ceil(wait.Seconds())),
+		},
+	})
+}
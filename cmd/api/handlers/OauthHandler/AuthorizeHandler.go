@@ -0,0 +1,105 @@
+package OauthHandler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/oauthServer"
+)
+
+type AuthorizeJsonRequest struct {
+	ResponseType        string `query:"response_type"`
+	ClientID            string `query:"client_id"`
+	RedirectURI         string `query:"redirect_uri"`
+	Scope               string `query:"scope"`
+	State               string `query:"state"`
+	CodeChallenge       string `query:"code_challenge"`
+	CodeChallengeMethod string `query:"code_challenge_method"`
+}
+
+// AuthorizeHandler is the start of the authorization code flow, where a client sends the user's browser. It checks
+// the request against the client's registration and passes it to the frontend's /oauth/consent page as a short-lived
+// signed token. The frontend logs the user in if needed, then asks them to approve with ConsentHandler.
+func AuthorizeHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		authorizeRequest := new(AuthorizeJsonRequest)
+		if err := c.Bind(authorizeRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing request",
+			})
+		}
+
+		// Until the client and redirect URI are known to be good, errors can't be sent back to the client
+		client, err := app.OauthClients.GetActive(ctx, authorizeRequest.ClientID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Unknown client",
+			})
+		}
+		if err != nil {
+			return err
+		}
+
+		redirectURI := authorizeRequest.RedirectURI
+		if redirectURI == "" && len(client.RedirectURIs) == 1 {
+			redirectURI = client.RedirectURIs[0]
+		}
+		if !slices.Contains(client.RedirectURIs, redirectURI) {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "The redirect URI is not registered for this client",
+			})
+		}
+
+		redirectError := func(code string, description string) error {
+			return c.Redirect(http.StatusFound, redirectURIWithParams(redirectURI, url.Values{
+				"error":             {code},
+				"error_description": {description},
+				"state":             {authorizeRequest.State},
+			}))
+		}
+
+		if authorizeRequest.ResponseType != "code" {
+			return redirectError("unsupported_response_type", "Only the code response type is supported")
+		}
+
+		if !hasGrant(client, oauthServer.GrantAuthorizationCode) {
+			return redirectError("unauthorized_client", "This client cannot use the authorization code flow")
+		}
+
+		if authorizeRequest.CodeChallenge == "" || authorizeRequest.CodeChallengeMethod != "S256" {
+			return redirectError("invalid_request", "PKCE with the S256 code challenge method is required")
+		}
+
+		scopes := oauthServer.ParseScope(authorizeRequest.Scope)
+		if len(scopes) == 0 {
+			scopes = client.Scopes
+		}
+		if !oauthServer.IsSubset(scopes, client.Scopes) {
+			return redirectError("invalid_scope", "This client cannot request those scopes")
+		}
+
+		requestToken, err := app.JWTService.CreatePurposeToken(authorizeRequestPurpose, map[string]interface{}{
+			"clientId":      client.ID,
+			"redirectUri":   redirectURI,
+			"scope":         strings.Join(scopes, " "),
+			"state":         authorizeRequest.State,
+			"codeChallenge": authorizeRequest.CodeChallenge,
+		}, authorizeRequestTTL)
+		if err != nil {
+			return err
+		}
+
+		return c.Redirect(http.StatusFound, fmt.Sprintf("%s/oauth/consent?request=%s", app.Config.FrontendURL, url.QueryEscape(requestToken)))
+	}
+}
@@ -0,0 +1,96 @@
+package OauthHandler
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
+)
+
+type ConsentJsonRequest struct {
+	Request string `json:"request"`
+	Approve bool   `json:"approve"`
+}
+
+// ConsentHandler records the logged in user approving or denying an authorization request. Either way it returns
+// the client's redirect URI for the frontend to send the browser to, with an authorization code if approved.
+func ConsentHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+
+		consentRequest := new(ConsentJsonRequest)
+		if err := c.Bind(consentRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing JSON",
+			})
+		}
+
+		request, client, err := parseAuthorizeRequest(ctx, app, consentRequest.Request)
+		if err != nil {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "This authorization request is invalid or has expired",
+				Errors:  map[string][]string{"request": {"This authorization request is invalid or has expired"}},
+			})
+		}
+
+		if !consentRequest.Approve {
+			return c.JSON(http.StatusOK, application.Response{
+				Success: true,
+				Message: "Authorization denied",
+				Data: application.ResponseData{
+					"redirectUri": redirectURIWithParams(request.RedirectURI, url.Values{
+						"error":             {"access_denied"},
+						"error_description": {"The user denied the request"},
+						"state":             {request.State},
+					}),
+				},
+			})
+		}
+
+		// Consent covers everything the user has allowed the client so far, so later requests for less don't ask again
+		consented, err := app.OauthConsents.GetScopes(ctx, userId, client.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := app.OauthConsents.Save(ctx, userId, client.ID, mergeScopes(consented, request.Scopes)); err != nil {
+			return err
+		}
+
+		code, codeHash, err := tokenHelper.Generate()
+		if err != nil {
+			return err
+		}
+
+		err = app.OauthCodes.Create(ctx, database.OauthAuthorizationCode{
+			CodeHash:      codeHash,
+			ClientID:      client.ID,
+			UserID:        userId,
+			RedirectURI:   request.RedirectURI,
+			Scopes:        request.Scopes,
+			CodeChallenge: request.CodeChallenge,
+			ExpiresAt:     time.Now().Add(app.Config.OAuth.AuthorizationCodeTTL),
+		})
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Authorization approved",
+			Data: application.ResponseData{
+				"redirectUri": redirectURIWithParams(request.RedirectURI, url.Values{
+					"code":  {code},
+					"state": {request.State},
+				}),
+			},
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package OauthHandler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/oauthServer"
+)
+
+type GetConsentJsonRequest struct {
+	Request string `query:"request"`
+}
+
+// GetConsentHandler describes an authorization request for the frontend's consent page: which app is asking,
+// and for what. alreadyConsented is true if the user has allowed all of it before, so the page can skip asking.
+func GetConsentHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+
+		consentRequest := new(GetConsentJsonRequest)
+		if err := c.Bind(consentRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing request",
+			})
+		}
+
+		request, client, err := parseAuthorizeRequest(ctx, app, consentRequest.Request)
+		if err != nil {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "This authorization request is invalid or has expired",
+				Errors:  map[string][]string{"request": {"This authorization request is invalid or has expired"}},
+			})
+		}
+
+		consented, err := app.OauthConsents.GetScopes(ctx, userId, client.ID)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Authorization Request Retrieved",
+			Data: application.ResponseData{
+				"client": map[string]string{
+					"id":   client.ID,
+					"name": client.Name,
+				},
+				"scopes":           describeScopes(request.Scopes),
+				"alreadyConsented": len(consented) > 0 && oauthServer.IsSubset(request.Scopes, consented),
+			},
+		})
+	}
+}
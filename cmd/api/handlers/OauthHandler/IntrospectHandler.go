@@ -0,0 +1,81 @@
+package OauthHandler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/oauthServer"
+)
+
+// IntrospectHandler lets confidential clients, such as partner resource servers, check whether an OAuth access token
+// is still active and what it may do (RFC 7662). Only tokens issued to OAuth clients are reported; our own session
+// tokens are always reported as inactive.
+func IntrospectHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		c.Response().Header().Set("Cache-Control", "no-store")
+
+		client, clientErr, err := authenticateClient(c, app)
+		if err != nil {
+			return err
+		}
+		if clientErr != nil || client.IsPublic() {
+			return oauthError(c, http.StatusUnauthorized, "invalid_client", "Client authentication failed")
+		}
+
+		inactive := func() error {
+			return c.JSON(http.StatusOK, map[string]interface{}{"active": false})
+		}
+
+		claims, err := app.JWTService.GetClaimsFromJWT(c.FormValue("token"))
+		if err != nil || claims.ClientID == "" {
+			return inactive()
+		}
+
+		revoked, err := app.Revocations.IsRevoked(ctx, claims)
+		if err != nil {
+			return err
+		}
+		if revoked {
+			return inactive()
+		}
+
+		scopes, err := oauthServer.EffectiveScopes(ctx, app.Stores, claims)
+		if err != nil {
+			return err
+		}
+		if len(scopes) == 0 {
+			return inactive()
+		}
+
+		response := map[string]interface{}{
+			"active":     true,
+			"scope":      strings.Join(scopes, " "),
+			"client_id":  claims.ClientID,
+			"token_type": "Bearer",
+			"exp":        claims.ExpiresAt.Unix(),
+			"iat":        claims.IssuedAt.Unix(),
+			"jti":        claims.ID,
+		}
+
+		if claims.UserID != 0 {
+			user, err := app.Users.FindUser(ctx, claims.UserID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return inactive()
+			}
+			if err != nil {
+				return err
+			}
+
+			response["sub"] = strconv.FormatInt(user.ID, 10)
+			response["username"] = user.Username
+		}
+
+		return c.JSON(http.StatusOK, response)
+	}
+}
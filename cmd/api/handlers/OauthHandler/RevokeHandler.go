@@ -0,0 +1,32 @@
+package OauthHandler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+// RevokeHandler lets a client revoke an access token it was issued (RFC 7009). As the spec requires, it responds
+// with 200 whether or not the token was valid.
+func RevokeHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		client, clientErr, err := authenticateClient(c, app)
+		if err != nil {
+			return err
+		}
+		if clientErr != nil {
+			return oauthError(c, http.StatusUnauthorized, clientErr.Code, clientErr.Description)
+		}
+
+		claims, err := app.JWTService.GetClaimsFromJWT(c.FormValue("token"))
+		if err == nil && claims.ClientID == client.ID {
+			if err := app.Revocations.Revoke(ctx, claims); err != nil {
+				return err
+			}
+		}
+
+		return c.NoContent(http.StatusOK)
+	}
+}
@@ -0,0 +1,97 @@
+package OauthHandler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/oauthServer"
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
+)
+
+// TokenHandler issues access tokens to OAuth clients (RFC 6749 section 3.2), for the authorization_code grant with
+// PKCE, and the client_credentials grant for confidential clients acting as themselves. Tokens are JWTs signed like
+// our own, carrying client_id and scope claims, and cannot be refreshed.
+func TokenHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		c.Response().Header().Set("Cache-Control", "no-store")
+		c.Response().Header().Set("Pragma", "no-cache")
+
+		client, clientErr, err := authenticateClient(c, app)
+		if err != nil {
+			return err
+		}
+		if clientErr != nil {
+			return oauthError(c, http.StatusUnauthorized, clientErr.Code, clientErr.Description)
+		}
+
+		var userId int64
+		var username string
+		var scopes []string
+
+		switch grantType := c.FormValue("grant_type"); grantType {
+		case oauthServer.GrantAuthorizationCode:
+			if !hasGrant(client, grantType) {
+				return oauthError(c, http.StatusBadRequest, "unauthorized_client", "This client cannot use the authorization code grant")
+			}
+
+			invalidGrant := func() error {
+				return oauthError(c, http.StatusBadRequest, "invalid_grant", "The authorization code is invalid, expired or already used")
+			}
+
+			code, err := app.OauthCodes.Redeem(ctx, tokenHelper.Hash(c.FormValue("code")))
+			if errors.Is(err, sql.ErrNoRows) {
+				return invalidGrant()
+			}
+			if err != nil {
+				return err
+			}
+
+			if code.ClientID != client.ID || code.RedirectURI != c.FormValue("redirect_uri") || !oauthServer.VerifyPKCE(c.FormValue("code_verifier"), code.CodeChallenge) {
+				return invalidGrant()
+			}
+
+			user, err := app.Users.FindUser(ctx, code.UserID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return invalidGrant()
+			}
+			if err != nil {
+				return err
+			}
+
+			userId, username, scopes = user.ID, user.Username, code.Scopes
+
+		case oauthServer.GrantClientCredentials:
+			if client.IsPublic() || !hasGrant(client, grantType) {
+				return oauthError(c, http.StatusBadRequest, "unauthorized_client", "This client cannot use the client credentials grant")
+			}
+
+			scopes = oauthServer.ParseScope(c.FormValue("scope"))
+			if len(scopes) == 0 {
+				scopes = client.Scopes
+			}
+			if !oauthServer.IsSubset(scopes, client.Scopes) {
+				return oauthError(c, http.StatusBadRequest, "invalid_scope", "This client cannot request those scopes")
+			}
+
+		default:
+			return oauthError(c, http.StatusBadRequest, "unsupported_grant_type", "Supported grant types are authorization_code and client_credentials")
+		}
+
+		accessToken, claims, err := app.JWTService.CreateOauthAccessToken(userId, username, client.ID, scopes, app.Config.OAuth.AccessTokenTTL)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"access_token": accessToken,
+			"token_type":   "Bearer",
+			"expires_in":   int(claims.ExpiresAt.Sub(claims.IssuedAt).Seconds()),
+			"scope":        strings.Join(scopes, " "),
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package OauthHandler
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/oauthServer"
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
+)
+
+const (
+	// authorizeRequestPurpose is the purpose token carrying a validated authorization request to the consent page
+	authorizeRequestPurpose = "oauth_authorize"
+	authorizeRequestTTL     = 10 * time.Minute
+)
+
+// authorizeRequest is an authorization request that has been checked against the client's registration
+type authorizeRequest struct {
+	ClientID      string
+	RedirectURI   string
+	Scopes        []string
+	State         string
+	CodeChallenge string
+}
+
+// oauthError responds with an OAuth2 error. The token, introspection and revocation endpoints are called by
+// OAuth client libraries, so they use the standard error format rather than application.Response.
+func oauthError(c echo.Context, status int, code string, description string) error {
+	if status == http.StatusUnauthorized {
+		c.Response().Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+
+	return c.JSON(status, oauthServer.Error{Code: code, Description: description})
+}
+
+// authenticateClient identifies the client calling the token, introspection or revocation endpoint, from HTTP Basic
+// auth or client_id and client_secret form fields. Public clients only send their client_id.
+func authenticateClient(c echo.Context, app *application.Application) (database.OauthClient, *oauthServer.Error, error) {
+	ctx := c.Request().Context()
+	clientId, clientSecret, hasBasicAuth := c.Request().BasicAuth()
+	if hasBasicAuth {
+		// Basic auth credentials are form-encoded first (RFC 6749 section 2.3.1)
+		clientId, _ = url.QueryUnescape(clientId)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientId = c.FormValue("client_id")
+		clientSecret = c.FormValue("client_secret")
+	}
+
+	invalidClient := &oauthServer.Error{Code: "invalid_client", Description: "Client authentication failed"}
+
+	if clientId == "" {
+		return database.OauthClient{}, invalidClient, nil
+	}
+
+	client, err := app.OauthClients.GetActive(ctx, clientId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.OauthClient{}, invalidClient, nil
+	}
+	if err != nil {
+		return database.OauthClient{}, nil, err
+	}
+
+	if client.IsPublic() {
+		if clientSecret != "" {
+			return database.OauthClient{}, invalidClient, nil
+		}
+		return client, nil, nil
+	}
+
+	if clientSecret == "" || subtle.ConstantTimeCompare([]byte(tokenHelper.Hash(clientSecret)), []byte(client.SecretHash.String)) != 1 {
+		return database.OauthClient{}, invalidClient, nil
+	}
+
+	return client, nil, nil
+}
+
+// parseAuthorizeRequest reads back a request validated by AuthorizeHandler, checking the client is still active
+func parseAuthorizeRequest(ctx context.Context, app *application.Application, token string) (authorizeRequest, database.OauthClient, error) {
+	claims, err := app.JWTService.ParsePurposeToken(token, authorizeRequestPurpose)
+	if err != nil {
+		return authorizeRequest{}, database.OauthClient{}, err
+	}
+
+	request := authorizeRequest{}
+	request.ClientID, _ = claims["clientId"].(string)
+	request.RedirectURI, _ = claims["redirectUri"].(string)
+	request.State, _ = claims["state"].(string)
+	request.CodeChallenge, _ = claims["codeChallenge"].(string)
+	scope, _ := claims["scope"].(string)
+	request.Scopes = oauthServer.ParseScope(scope)
+
+	client, err := app.OauthClients.GetActive(ctx, request.ClientID)
+	if err != nil {
+		return authorizeRequest{}, database.OauthClient{}, err
+	}
+
+	return request, client, nil
+}
+
+// redirectURIWithParams adds params to the client's redirect URI, keeping any query it already has
+func redirectURIWithParams(redirectURI string, params url.Values) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+
+	query := u.Query()
+	for key, values := range params {
+		for _, value := range values {
+			if value != "" {
+				query.Add(key, value)
+			}
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+func hasGrant(client database.OauthClient, grant string) bool {
+	return slices.Contains(client.GrantTypes, grant)
+}
+
+// describeScopes pairs each scope with its description, for the consent page
+func describeScopes(scopes []string) []map[string]string {
+	described := []map[string]string{}
+	for _, scope := range scopes {
+		described = append(described, map[string]string{
+			"scope":       scope,
+			"description": oauthServer.Scopes[scope],
+		})
+	}
+
+	return described
+}
+
+// mergeScopes returns a with any scopes from b it doesn't already have
+func mergeScopes(a []string, b []string) []string {
+	return oauthServer.ParseScope(strings.Join(append(slices.Clone(a), b...), " "))
+}
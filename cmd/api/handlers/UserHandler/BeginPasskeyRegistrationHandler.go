@@ -0,0 +1,62 @@
+package UserHandler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/webauthnHelper"
+)
+
+// BeginPasskeyRegistrationHandler starts registering a new passkey, returning the options to pass to
+// navigator.credentials.create() and a session token to send back with the result
+func BeginPasskeyRegistrationHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+
+		webAuthnUser, err := loadWebAuthnUser(ctx, app, userId)
+		if err != nil {
+			return err
+		}
+
+		// Stop the same authenticator being registered twice
+		exclusions := webauthn.Credentials(webAuthnUser.WebAuthnCredentials()).CredentialDescriptors()
+
+		creation, session, err := app.WebAuthn.BeginRegistration(webAuthnUser, webauthn.WithExclusions(exclusions))
+		if err != nil {
+			return err
+		}
+
+		sessionToken, err := webauthnHelper.SaveSession(ctx, app.WebAuthnSessions, userId, database.WebAuthnRegistration, session)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Success",
+			Data: application.ResponseData{
+				"options":      creation,
+				"sessionToken": sessionToken,
+			},
+		})
+	}
+}
+
+func loadWebAuthnUser(ctx context.Context, app *application.Application, userId int64) (*webauthnHelper.User, error) {
+	user, err := app.Users.FindUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := app.Credentials.GetByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webauthnHelper.User{User: user, Credentials: credentials}, nil
+}
@@ -0,0 +1,76 @@
+package UserHandler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/totpHelper"
+)
+
+type TotpCodeJsonRequest struct {
+	Code string `json:"code"`
+}
+
+// ConfirmTotpHandler enables two-factor authentication once the user enters a valid code from their
+// newly enrolled authenticator, returning their recovery codes. These are only ever shown this once.
+func ConfirmTotpHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+
+		confirmRequest := new(TotpCodeJsonRequest)
+		if err := c.Bind(confirmRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing JSON",
+			})
+		}
+
+		twoFactor, err := app.TwoFactor.Get(ctx, userId)
+		if err != nil {
+			return err
+		}
+
+		if twoFactor.EnabledAt.Valid {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "Two-factor authentication is already enabled",
+			})
+		}
+
+		if !twoFactor.Secret.Valid {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "Two-factor authentication has not been set up",
+			})
+		}
+
+		counter, ok := totpHelper.Validate(twoFactor.Secret.String, confirmRequest.Code, time.Now())
+		if !ok {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "Invalid authentication code",
+				Errors:  map[string][]string{"code": {"Invalid authentication code"}},
+			})
+		}
+
+		if err := app.TwoFactor.Enable(ctx, userId, counter); err != nil {
+			return err
+		}
+
+		recoveryCodes, err := replaceRecoveryCodes(ctx, app, userId)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Two-factor authentication enabled",
+			Data: application.ResponseData{
+				"recoveryCodes": recoveryCodes,
+			},
+		})
+	}
+}
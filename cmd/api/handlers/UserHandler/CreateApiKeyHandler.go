@@ -0,0 +1,79 @@
+package UserHandler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/apiKeyHelper"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+type CreateApiKeyJsonRequest struct {
+	Name      string     `json:"name"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// CreateApiKeyHandler creates a personal API key. The key itself is only ever returned in this response.
+func CreateApiKeyHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+
+		// A leaked API key must not be able to mint more keys
+		if c.Get("apiKeyId") != nil {
+			return c.JSON(http.StatusForbidden, application.Response{
+				Success: false,
+				Message: "API keys cannot be created using an API key",
+			})
+		}
+
+		createRequest := new(CreateApiKeyJsonRequest)
+		if err := c.Bind(createRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing JSON",
+			})
+		}
+
+		name := strings.TrimSpace(createRequest.Name)
+		if name == "" || len(name) > 255 {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "Name is required and must be at most 255 characters",
+				Errors:  map[string][]string{"name": {"Name is required and must be at most 255 characters"}},
+			})
+		}
+
+		if createRequest.ExpiresAt != nil && !createRequest.ExpiresAt.After(time.Now()) {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "Expiry must be in the future",
+				Errors:  map[string][]string{"expiresAt": {"Expiry must be in the future"}},
+			})
+		}
+
+		key, prefix, hash, err := apiKeyHelper.Generate()
+		if err != nil {
+			return err
+		}
+
+		id, err := app.ApiKeys.Create(ctx, userId, name, prefix, hash, createRequest.ExpiresAt)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusCreated, application.Response{
+			Success: true,
+			Message: "API key created. Copy it now, it will not be shown again",
+			Data: application.ResponseData{
+				"id":        id,
+				"name":      name,
+				"prefix":    prefix,
+				"key":       key,
+				"expiresAt": createRequest.ExpiresAt,
+			},
+		})
+	}
+}
@@ -5,10 +5,12 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
 )
 
 func DeleteAccountHandler(app *application.Application) echo.HandlerFunc {
 	return func(c echo.Context) error {
+		ctx := c.Request().Context()
 		userId := c.Get("userId").(int64)
 
 		if userId == 0 {
@@ -18,8 +20,22 @@ func DeleteAccountHandler(app *application.Application) echo.HandlerFunc {
 			})
 		}
 
-		// Delete the user from the database:
-		if err := app.DB.UserModel.Delete(int64(userId)); err != nil {
+		// The user's workout backup and feedback go with them, or if anything fails, none of it does
+		err := app.WithTx(ctx, func(tx database.Stores) error {
+			if err := tx.WorkoutBackups.DeleteForUser(ctx, userId); err != nil {
+				return err
+			}
+			if err := tx.Feedback.DeleteForUser(ctx, userId); err != nil {
+				return err
+			}
+			return tx.Users.Delete(ctx, userId)
+		})
+		if err != nil {
+			return err
+		}
+
+		// Make sure no outstanding token can still be used for the deleted account
+		if err := app.Revocations.RevokeAllForUser(ctx, userId); err != nil {
 			return err
 		}
 
@@ -29,6 +45,12 @@ func DeleteAccountHandler(app *application.Application) echo.HandlerFunc {
 			c.SetCookie(clearCookie)
 		}
 
+		clearRefreshCookie, err := app.JWTService.ClearRefreshCookie()
+
+		if err == nil {
+			c.SetCookie(clearRefreshCookie)
+		}
+
 		return c.JSON(http.StatusOK, application.Response{
 			Success: true,
 			Message: "Account Deleted",
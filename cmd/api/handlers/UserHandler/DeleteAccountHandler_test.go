@@ -0,0 +1,54 @@
+package UserHandler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/testHelper"
+)
+
+func TestDeleteAccountHandler(t *testing.T) {
+	ctx := context.Background()
+	app, _ := testHelper.NewApp(t)
+	user := testHelper.CreateUser(t, app, "user@example.com", "correct horse battery staple")
+	other := testHelper.CreateUser(t, app, "other@example.com", "correct horse battery staple")
+
+	if err := app.WorkoutBackups.CreateWorkoutBackup(ctx, user.ID, "backups/user.json"); err != nil {
+		t.Fatal(err)
+	}
+	for _, userId := range []int64{user.ID, other.ID} {
+		if err := app.Feedback.Save(ctx, "Name", userId, "bug", "Something broke"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/user", nil)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.Set("userId", user.ID)
+
+	if err := DeleteAccountHandler(app)(c); err != nil {
+		t.Fatalf("DeleteAccountHandler: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	if _, err := app.Users.FindUser(ctx, user.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("FindUser after deleting = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := app.WorkoutBackups.GetByUserId(ctx, user.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("workout backup after deleting = %v, want sql.ErrNoRows", err)
+	}
+
+	feedback := app.Feedback.(*database.MemoryFeedbackStore).All()
+	if len(feedback) != 1 || feedback[0].UserID != other.ID {
+		t.Errorf("feedback = %+v, want only the other user's", feedback)
+	}
+}
@@ -0,0 +1,41 @@
+package UserHandler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+func DeleteApiKeyHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+
+		notFound := func() error {
+			return c.JSON(http.StatusNotFound, application.Response{
+				Success: false,
+				Message: "API key not found",
+			})
+		}
+
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			return notFound()
+		}
+
+		deleted, err := app.ApiKeys.Delete(ctx, userId, id)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return notFound()
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "API key revoked",
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package UserHandler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+// DeleteOtherSessionsHandler signs the user out everywhere apart from the session making the request.
+// Requests made with an API key have no session, so every session is signed out.
+func DeleteOtherSessionsHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+		currentSessionId, _ := c.Get("sessionId").(string)
+
+		if err := app.Revocations.RevokeOtherSessions(ctx, userId, currentSessionId); err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Signed out of all other sessions",
+		})
+	}
+}
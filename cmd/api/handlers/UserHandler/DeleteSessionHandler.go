@@ -0,0 +1,43 @@
+package UserHandler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+// DeleteSessionHandler signs one of the user's sessions out. Signing out the current session also clears its cookies.
+func DeleteSessionHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+		currentSessionId, _ := c.Get("sessionId").(string)
+		sessionId := c.Param("id")
+
+		revoked, err := app.Revocations.RevokeSession(ctx, userId, sessionId)
+		if err != nil {
+			return err
+		}
+		if !revoked {
+			return c.JSON(http.StatusNotFound, application.Response{
+				Success: false,
+				Message: "Session not found",
+			})
+		}
+
+		if sessionId == currentSessionId {
+			if clearCookie, err := app.JWTService.ClearCookie(); err == nil {
+				c.SetCookie(clearCookie)
+			}
+			if clearRefreshCookie, err := app.JWTService.ClearRefreshCookie(); err == nil {
+				c.SetCookie(clearRefreshCookie)
+			}
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Session signed out",
+		})
+	}
+}
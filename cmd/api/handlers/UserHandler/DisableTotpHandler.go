@@ -0,0 +1,54 @@
+package UserHandler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+type DisableTotpJsonRequest struct {
+	Password string `json:"password"`
+}
+
+// DisableTotpHandler turns off two-factor authentication and deletes the recovery codes, after re-checking the password
+func DisableTotpHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+
+		disableRequest := new(DisableTotpJsonRequest)
+		if err := c.Bind(disableRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing JSON",
+			})
+		}
+
+		user, err := app.Users.FindUser(ctx, userId)
+		if err != nil {
+			return err
+		}
+
+		match, _, err := app.Passwords.Verify(disableRequest.Password, user.Password)
+		if err != nil {
+			return err
+		}
+		if !match {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "Incorrect password",
+				Errors:  map[string][]string{"password": {"Incorrect password"}},
+			})
+		}
+
+		if err := app.TwoFactor.Disable(ctx, userId); err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Two-factor authentication disabled",
+		})
+	}
+}
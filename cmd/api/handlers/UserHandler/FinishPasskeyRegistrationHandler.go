@@ -0,0 +1,83 @@
+package UserHandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/webauthnHelper"
+)
+
+type FinishPasskeyRegistrationJsonRequest struct {
+	SessionToken string          `json:"sessionToken"`
+	Name         string          `json:"name"`
+	Credential   json.RawMessage `json:"credential"`
+}
+
+// FinishPasskeyRegistrationHandler verifies the result of navigator.credentials.create() and saves the new passkey
+func FinishPasskeyRegistrationHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+
+		finishRequest := new(FinishPasskeyRegistrationJsonRequest)
+		if err := c.Bind(finishRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing JSON",
+			})
+		}
+
+		name := strings.TrimSpace(finishRequest.Name)
+		if name == "" {
+			name = "Passkey"
+		}
+		if len(name) > 255 {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "Name must be at most 255 characters",
+				Errors:  map[string][]string{"name": {"Name must be at most 255 characters"}},
+			})
+		}
+
+		invalidPasskey := func() error {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "Passkey registration failed, please try again",
+			})
+		}
+
+		session, sessionUserId, err := webauthnHelper.LoadSession(ctx, app.WebAuthnSessions, finishRequest.SessionToken, database.WebAuthnRegistration)
+		if err != nil || sessionUserId != userId {
+			return invalidPasskey()
+		}
+
+		parsed, err := protocol.ParseCredentialCreationResponseBytes(finishRequest.Credential)
+		if err != nil {
+			return invalidPasskey()
+		}
+
+		webAuthnUser, err := loadWebAuthnUser(ctx, app, userId)
+		if err != nil {
+			return err
+		}
+
+		credential, err := app.WebAuthn.CreateCredential(webAuthnUser, session, parsed)
+		if err != nil {
+			return invalidPasskey()
+		}
+
+		if err := app.Credentials.Create(ctx, webauthnHelper.FromWebAuthnCredential(userId, name, credential)); err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Passkey added",
+		})
+	}
+}
@@ -5,10 +5,12 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
 )
 
 func GetAccountHandler(app *application.Application) echo.HandlerFunc {
 	return func(c echo.Context) error {
+		ctx := c.Request().Context()
 		userId := c.Get("userId").(int64)
 
 		if userId == 0 {
@@ -19,17 +21,35 @@ func GetAccountHandler(app *application.Application) echo.HandlerFunc {
 		}
 
 		// Delete the user from the database:
-		user, err := app.DB.UserModel.FindUser(int64(userId))
+		user, err := app.Users.FindUser(ctx, int64(userId))
 
 		if err != nil {
 			return err
 		}
 
+		roles, err := app.Roles.GetRoleNamesForUser(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+
+		// Lets the frontend show a banner while an admin is acting as this user
+		var impersonatedBy *database.User
+		if impersonatorId, ok := c.Get("impersonatorId").(int64); ok {
+			impersonator, err := app.Users.FindUser(ctx, impersonatorId)
+			if err != nil {
+				return err
+			}
+			impersonatedBy = &impersonator
+		}
+
 		return c.JSON(http.StatusOK, application.Response{
 			Success: true,
 			Message: "Account Details Retrieved",
 			Data: application.ResponseData{
-				"user": user,
+				"user":           user,
+				"roles":          roles,
+				"impersonating":  impersonatedBy != nil,
+				"impersonatedBy": impersonatedBy,
 			},
 		})
 	}
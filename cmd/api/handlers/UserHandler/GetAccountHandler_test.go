@@ -0,0 +1,90 @@
+package UserHandler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/testHelper"
+)
+
+type accountResponse struct {
+	Success bool `json:"success"`
+	Data    struct {
+		User           database.User  `json:"user"`
+		Roles          []string       `json:"roles"`
+		Impersonating  bool           `json:"impersonating"`
+		ImpersonatedBy *database.User `json:"impersonatedBy"`
+	} `json:"data"`
+}
+
+func getAccount(t *testing.T, app *application.Application, userId int64, impersonatorId int64) accountResponse {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/user", nil)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.Set("userId", userId)
+	if impersonatorId != 0 {
+		c.Set("impersonatorId", impersonatorId)
+	}
+
+	if err := GetAccountHandler(app)(c); err != nil {
+		t.Fatalf("GetAccountHandler: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var response accountResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+
+	return response
+}
+
+func TestGetAccountHandler(t *testing.T) {
+	app, _ := testHelper.NewApp(t)
+	user := testHelper.CreateUser(t, app, "user@example.com", "correct horse battery staple")
+
+	if ok, err := app.Roles.AssignToUser(context.Background(), user.ID, "admin"); err != nil || !ok {
+		t.Fatalf("AssignToUser = %v, %v", ok, err)
+	}
+
+	response := getAccount(t, app, user.ID, 0)
+
+	if !response.Success || response.Data.User.ID != user.ID || response.Data.User.Username != "user@example.com" {
+		t.Errorf("user = %+v, want %s", response.Data.User, user.Username)
+	}
+	if !slices.Equal(response.Data.Roles, []string{"admin"}) {
+		t.Errorf("roles = %v, want [admin]", response.Data.Roles)
+	}
+	if response.Data.Impersonating || response.Data.ImpersonatedBy != nil {
+		t.Errorf("impersonating = %v, want false", response.Data.Impersonating)
+	}
+}
+
+func TestGetAccountHandlerWhileImpersonating(t *testing.T) {
+	app, _ := testHelper.NewApp(t)
+	admin := testHelper.CreateUser(t, app, "admin@example.com", "correct horse battery staple")
+	user := testHelper.CreateUser(t, app, "user@example.com", "correct horse battery staple")
+
+	response := getAccount(t, app, user.ID, admin.ID)
+
+	if response.Data.User.ID != user.ID {
+		t.Errorf("user = %d, want %d", response.Data.User.ID, user.ID)
+	}
+	if len(response.Data.Roles) != 0 {
+		t.Errorf("roles = %v, want none", response.Data.Roles)
+	}
+	if !response.Data.Impersonating || response.Data.ImpersonatedBy == nil || response.Data.ImpersonatedBy.ID != admin.ID {
+		t.Errorf("impersonatedBy = %+v, want %d", response.Data.ImpersonatedBy, admin.ID)
+	}
+}
@@ -0,0 +1,47 @@
+package UserHandler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+func ListApiKeysHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+
+		apiKeys, err := app.ApiKeys.GetByUserId(ctx, userId)
+		if err != nil {
+			return err
+		}
+
+		keys := make([]application.ResponseData, 0, len(apiKeys))
+		for _, apiKey := range apiKeys {
+			key := application.ResponseData{
+				"id":         apiKey.ID,
+				"name":       apiKey.Name,
+				"prefix":     apiKey.Prefix,
+				"createdAt":  apiKey.CreatedAt,
+				"expiresAt":  nil,
+				"lastUsedAt": nil,
+			}
+			if apiKey.ExpiresAt.Valid {
+				key["expiresAt"] = apiKey.ExpiresAt.Time
+			}
+			if apiKey.LastUsedAt.Valid {
+				key["lastUsedAt"] = apiKey.LastUsedAt.Time
+			}
+			keys = append(keys, key)
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "API Keys Retrieved",
+			Data: application.ResponseData{
+				"apiKeys": keys,
+			},
+		})
+	}
+}
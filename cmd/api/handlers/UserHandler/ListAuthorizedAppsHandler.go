@@ -0,0 +1,29 @@
+package UserHandler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+// ListAuthorizedAppsHandler returns the third-party apps the user has given access to their account through OAuth
+func ListAuthorizedAppsHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+
+		consents, err := app.OauthConsents.GetByUserId(ctx, userId)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Authorized Apps Retrieved",
+			Data: application.ResponseData{
+				"apps": consents,
+			},
+		})
+	}
+}
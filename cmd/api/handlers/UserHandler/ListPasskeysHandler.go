@@ -0,0 +1,42 @@
+package UserHandler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+func ListPasskeysHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+
+		credentials, err := app.Credentials.GetByUserId(ctx, userId)
+		if err != nil {
+			return err
+		}
+
+		passkeys := make([]application.ResponseData, 0, len(credentials))
+		for _, credential := range credentials {
+			passkey := application.ResponseData{
+				"id":         credential.ID,
+				"name":       credential.Name,
+				"createdAt":  credential.CreatedAt,
+				"lastUsedAt": nil,
+			}
+			if credential.LastUsedAt.Valid {
+				passkey["lastUsedAt"] = credential.LastUsedAt.Time
+			}
+			passkeys = append(passkeys, passkey)
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Passkeys Retrieved",
+			Data: application.ResponseData{
+				"passkeys": passkeys,
+			},
+		})
+	}
+}
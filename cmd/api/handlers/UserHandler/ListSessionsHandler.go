@@ -0,0 +1,42 @@
+package UserHandler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+// ListSessionsHandler lists the devices the user is logged in on, marking the one making the request
+func ListSessionsHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+		currentSessionId, _ := c.Get("sessionId").(string)
+
+		sessions, err := app.Sessions.GetActiveByUserId(ctx, userId)
+		if err != nil {
+			return err
+		}
+
+		data := make([]application.ResponseData, 0, len(sessions))
+		for _, session := range sessions {
+			data = append(data, application.ResponseData{
+				"id":         session.ID,
+				"userAgent":  session.UserAgent,
+				"ipAddress":  session.IPAddress,
+				"createdAt":  session.CreatedAt,
+				"lastSeenAt": session.LastSeenAt,
+				"current":    session.ID == currentSessionId,
+			})
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Sessions Retrieved",
+			Data: application.ResponseData{
+				"sessions": data,
+			},
+		})
+	}
+}
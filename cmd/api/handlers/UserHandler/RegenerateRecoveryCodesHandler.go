@@ -0,0 +1,91 @@
+package UserHandler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
+	"github.com/nathanjms/go-api-template/internal/totpHelper"
+)
+
+const recoveryCodeCount = 10
+
+// RegenerateRecoveryCodesHandler replaces the user's recovery codes, invalidating the old ones. It requires
+// a current authentication code so a stolen session alone cannot be used to get new codes.
+func RegenerateRecoveryCodesHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+
+		regenerateRequest := new(TotpCodeJsonRequest)
+		if err := c.Bind(regenerateRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, application.Response{
+				Success: false,
+				Message: "Error parsing JSON",
+			})
+		}
+
+		twoFactor, err := app.TwoFactor.Get(ctx, userId)
+		if err != nil {
+			return err
+		}
+
+		if !twoFactor.EnabledAt.Valid {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "Two-factor authentication is not enabled",
+			})
+		}
+
+		counter, ok := totpHelper.Validate(twoFactor.Secret.String, regenerateRequest.Code, time.Now())
+		fresh := false
+		if ok {
+			fresh, err = app.TwoFactor.UseCounter(ctx, userId, counter)
+			if err != nil {
+				return err
+			}
+		}
+		if !fresh {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "Invalid authentication code",
+				Errors:  map[string][]string{"code": {"Invalid authentication code"}},
+			})
+		}
+
+		recoveryCodes, err := replaceRecoveryCodes(ctx, app, userId)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Recovery codes regenerated",
+			Data: application.ResponseData{
+				"recoveryCodes": recoveryCodes,
+			},
+		})
+	}
+}
+
+// replaceRecoveryCodes generates a fresh set of recovery codes, storing only their hashes
+func replaceRecoveryCodes(ctx context.Context, app *application.Application, userId int64) ([]string, error) {
+	recoveryCodes, err := totpHelper.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	codeHashes := make([]string, 0, len(recoveryCodes))
+	for _, code := range recoveryCodes {
+		codeHashes = append(codeHashes, tokenHelper.Hash(totpHelper.NormalizeRecoveryCode(code)))
+	}
+
+	if err := app.TwoFactor.ReplaceRecoveryCodes(ctx, userId, codeHashes); err != nil {
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
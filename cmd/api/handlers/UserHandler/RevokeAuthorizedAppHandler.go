@@ -0,0 +1,33 @@
+package UserHandler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+// RevokeAuthorizedAppHandler withdraws the user's consent for an app. Its access tokens for the user stop working straight away.
+func RevokeAuthorizedAppHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+
+		deleted, err := app.OauthConsents.Delete(ctx, userId, c.Param("clientId"))
+		if err != nil {
+			return err
+		}
+
+		if !deleted {
+			return c.JSON(http.StatusNotFound, application.Response{
+				Success: false,
+				Message: "Authorized app not found",
+			})
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "App access revoked",
+		})
+	}
+}
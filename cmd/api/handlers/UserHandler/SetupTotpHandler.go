@@ -0,0 +1,48 @@
+package UserHandler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/totpHelper"
+)
+
+// SetupTotpHandler starts enrolling an authenticator app. Two-factor authentication is not enabled
+// until the user proves they have set it up by calling ConfirmTotpHandler with a code.
+func SetupTotpHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		userId := c.Get("userId").(int64)
+
+		user, err := app.Users.FindUser(ctx, userId)
+		if err != nil {
+			return err
+		}
+
+		if user.TOTPEnabledAt != nil {
+			return c.JSON(http.StatusUnprocessableEntity, application.Response{
+				Success: false,
+				Message: "Two-factor authentication is already enabled",
+			})
+		}
+
+		secret, err := totpHelper.GenerateSecret()
+		if err != nil {
+			return err
+		}
+
+		if err := app.TwoFactor.SetPendingSecret(ctx, user.ID, secret); err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, application.Response{
+			Success: true,
+			Message: "Scan the QR code with your authenticator app, then confirm with a code",
+			Data: application.ResponseData{
+				"secret": secret,
+				"uri":    totpHelper.URI(app.Config.AppName, user.Username, secret),
+			},
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package WellKnownHandler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+// JwksHandler serves the public signing keys so other services can verify our tokens
+func JwksHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Response().Header().Set("Cache-Control", "public, max-age=300")
+
+		return c.JSON(http.StatusOK, app.JWTService.JWKS())
+	}
+}
@@ -0,0 +1,40 @@
+package WellKnownHandler
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/oauthServer"
+)
+
+// OauthMetadataHandler serves the OAuth authorization server metadata (RFC 8414), so client libraries can configure themselves
+func OauthMetadataHandler(app *application.Application) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		baseURL := strings.TrimSuffix(app.Config.BaseURL, "/")
+
+		scopes := []string{}
+		for scope := range oauthServer.Scopes {
+			scopes = append(scopes, scope)
+		}
+		slices.Sort(scopes)
+
+		c.Response().Header().Set("Cache-Control", "public, max-age=300")
+
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"issuer":                                baseURL,
+			"authorization_endpoint":                baseURL + "/oauth/authorize",
+			"token_endpoint":                        baseURL + "/oauth/token",
+			"introspection_endpoint":                baseURL + "/oauth/introspect",
+			"revocation_endpoint":                   baseURL + "/oauth/revoke",
+			"jwks_uri":                              baseURL + "/.well-known/jwks.json",
+			"scopes_supported":                      scopes,
+			"response_types_supported":              []string{"code"},
+			"grant_types_supported":                 []string{oauthServer.GrantAuthorizationCode, oauthServer.GrantClientCredentials},
+			"code_challenge_methods_supported":      []string{"S256"},
+			"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		})
+	}
+}
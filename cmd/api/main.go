@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"log/slog"
@@ -21,7 +22,18 @@ func main() {
 
 	logger := slog.New(tint.NewHandler(os.Stdout, &tint.Options{Level: slog.LevelDebug}))
 
-	err := run(logger)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(logger, os.Args[2:]); err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	memory := flag.Bool("memory", false, "keep everything in memory instead of using DB_DRIVER and DB_DSN")
+	flag.Parse()
+
+	err := run(logger, application.Options{Memory: *memory})
 
 	if err != nil {
 		sentry.CaptureException(err)
@@ -41,12 +53,12 @@ func main() {
 	}
 }
 
-func run(logger *slog.Logger) error {
+func run(logger *slog.Logger, opts application.Options) error {
 	if err := godotenv.Load(".env"); err != nil {
 		log.Fatalf("Error loading .env file, proceeding with system environment variables")
 	}
 
-	app, err := application.New(logger)
+	app, err := application.New(logger, opts)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/jwtHelper"
+)
+
+func TestSkipCSRF(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		apiKey        string
+		cookie        bool
+		want          bool
+	}{
+		{name: "cookie", method: http.MethodPost, cookie: true, want: false},
+		{name: "bearer token", method: http.MethodPost, authorization: "Bearer token", cookie: true, want: true},
+		{name: "lowercase bearer", method: http.MethodPost, authorization: "bearer token", cookie: true, want: true},
+		{name: "api key", method: http.MethodPost, apiKey: "key", cookie: true, want: true},
+		// The cookie still authenticates these, so they must not bypass the check
+		{name: "basic auth", method: http.MethodPost, authorization: "Basic dXNlcjpwYXNz", cookie: true, want: false},
+		{name: "no scheme", method: http.MethodPost, authorization: "token", cookie: true, want: false},
+		{name: "no cookies", method: http.MethodPost, want: true},
+		{name: "safe method", method: http.MethodGet, authorization: "Bearer token", cookie: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/user", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			if tt.apiKey != "" {
+				req.Header.Set(apiKeyHeader, tt.apiKey)
+			}
+			if tt.cookie {
+				req.AddCookie(&http.Cookie{Name: jwtHelper.AccessCookieName, Value: "token"})
+			}
+
+			if got := skipCSRF(echo.New().NewContext(req, httptest.NewRecorder())); got != tt.want {
+				t.Errorf("skipCSRF = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
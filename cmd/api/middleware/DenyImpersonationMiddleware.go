@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+// DenyImpersonation rejects requests made with an impersonation token, for routes that change how the user
+// signs in or that could be used to keep access after the token expires. It must run after JWTAuthMiddleware.
+func DenyImpersonation() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Get("impersonatorId") != nil {
+				return c.JSON(http.StatusForbidden, application.Response{
+					Success: false,
+					Message: "Not available while impersonating a user",
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
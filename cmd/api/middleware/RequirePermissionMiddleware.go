@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+// RequirePermission rejects users whose roles do not grant the permission. It must run after JWTAuthMiddleware.
+func RequirePermission(app *application.Application, permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+			userId, _ := c.Get("userId").(int64)
+			if userId == 0 {
+				return c.JSON(http.StatusUnauthorized, application.Response{
+					Success: false,
+					Message: "Unauthorized",
+				})
+			}
+
+			allowed, err := app.Permissions.HasPermission(ctx, userId, permission)
+			if err != nil {
+				return err
+			}
+			if !allowed {
+				return c.JSON(http.StatusForbidden, application.Response{
+					Success: false,
+					Message: "Forbidden",
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
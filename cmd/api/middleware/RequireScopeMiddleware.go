@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+// RequireScope opens a route to third-party OAuth clients whose token has the scope. Our own sessions and API keys
+// have every scope. It must run after JWTAuthMiddleware, and must not be combined with FirstPartyOnly.
+func RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Get("oauthClientId") == nil {
+				return next(c)
+			}
+
+			scopes, _ := c.Get("oauthScopes").([]string)
+			if !slices.Contains(scopes, scope) {
+				c.Response().Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+				return c.JSON(http.StatusForbidden, application.Response{
+					Success: false,
+					Message: "This token does not have the " + scope + " scope",
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// FirstPartyOnly rejects tokens issued to third-party OAuth clients. It is used for every authed route that hasn't
+// opted in to OAuth access with RequireScope, so new routes are closed to clients by default.
+func FirstPartyOnly() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Get("oauthClientId") != nil {
+				return c.JSON(http.StatusForbidden, application.Response{
+					Success: false,
+					Message: "Not available to OAuth clients",
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+)
+
+// VerifiedEmailMiddleware rejects users who have not verified their email address, when REQUIRE_VERIFIED_EMAIL is on.
+// It must run after JWTAuthMiddleware.
+func VerifiedEmailMiddleware(app *application.Application) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !app.Config.RequireVerifiedEmail {
+			return next
+		}
+
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+			userId, _ := c.Get("userId").(int64)
+
+			user, err := app.Users.FindUser(ctx, userId)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, application.Response{
+					Success: false,
+					Message: "Unauthorized",
+				})
+			}
+
+			if user.EmailVerifiedAt == nil {
+				return c.JSON(http.StatusForbidden, application.Response{
+					Success: false,
+					Message: "Email address not verified",
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -4,30 +4,89 @@ import (
 	"net/http"
 
 	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/apiKeyHelper"
 	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/jwtHelper"
+	"github.com/nathanjms/go-api-template/internal/oauthServer"
+	"github.com/nathanjms/go-api-template/internal/rbac"
 )
 
-// JWTAuthMiddleware is a middleware function that verifies JWT tokens.
+// JWTAuthMiddleware is a middleware function that verifies JWT tokens, sent either
+// as an Authorization: Bearer header or in the jwt cookie. Personal API keys are also
+// accepted, as an Authorization: Bearer header or an X-API-Key header. Impersonation tokens only work while the
+// admin still has permission to impersonate. Requests made with one also get impersonatorId set, and are
+// recorded in the impersonation audit log. Tokens issued to third-party OAuth clients get oauthClientId and
+// oauthScopes set, for RequireScope and FirstPartyOnly.
 func JWTAuthMiddleware(app *application.Application) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			cookie, err := c.Cookie("jwt")
-			if err != nil || cookie.Value == "" {
+			ctx := c.Request().Context()
+			unauthorized := func() error {
 				return c.JSON(http.StatusUnauthorized, application.Response{
 					Success: false,
 					Message: "Unauthorized",
 				})
 			}
 
-			userId, err := app.JWTService.GetUserIdFromJWT(cookie.Value)
+			if apiKey := c.Request().Header.Get(apiKeyHeader); apiKey != "" {
+				return authenticateApiKey(c, app, apiKey, next, unauthorized)
+			}
+
+			token, fromHeader := jwtHelper.TokenFromRequest(c.Request())
+			if token == "" {
+				return unauthorized()
+			}
+
+			if fromHeader && apiKeyHelper.IsApiKey(token) {
+				return authenticateApiKey(c, app, token, next, unauthorized)
+			}
+
+			claims, err := app.JWTService.GetClaimsFromJWT(token)
+			// Tokens an OAuth client was issued for itself don't act for any user
+			if err != nil || claims.UserID == 0 {
+				return unauthorized()
+			}
+
+			revoked, err := app.Revocations.IsRevoked(ctx, claims)
 			if err != nil {
-				return c.JSON(http.StatusUnauthorized, application.Response{
-					Success: false,
-					Message: "Unauthorized",
-				})
+				return err
+			}
+			if revoked {
+				return unauthorized()
+			}
+
+			c.Set("userId", claims.UserID)
+			c.Set("tokenClaims", claims)
+			c.Set("sessionId", claims.SessionID)
+			c.Set("bearerAuth", fromHeader)
+
+			if claims.ClientID != "" {
+				scopes, err := oauthServer.EffectiveScopes(ctx, app.Stores, claims)
+				if err != nil {
+					return err
+				}
+				if len(scopes) == 0 {
+					return unauthorized()
+				}
+
+				c.Set("oauthClientId", claims.ClientID)
+				c.Set("oauthScopes", scopes)
+			}
+
+			if claims.ImpersonatorID != 0 {
+				// The admin may have lost the permission since they started impersonating
+				allowed, err := app.Permissions.HasPermission(ctx, claims.ImpersonatorID, rbac.PermissionUsersImpersonate)
+				if err != nil {
+					return err
+				}
+				if !allowed {
+					return unauthorized()
+				}
+
+				c.Set("impersonatorId", claims.ImpersonatorID)
+				return auditImpersonatedRequest(c, app, claims, next)
 			}
 
-			c.Set("userId", userId)
 			return next(c)
 		}
 	}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/rbac"
+	"github.com/nathanjms/go-api-template/internal/testHelper"
+)
+
+func TestJWTAuthMiddlewareChecksImpersonatorPermission(t *testing.T) {
+	ctx := context.Background()
+	app, _ := testHelper.NewApp(t)
+	admin := testHelper.CreateUser(t, app, "admin@example.com", "correct horse battery staple")
+	user := testHelper.CreateUser(t, app, "user@example.com", "correct horse battery staple")
+	if _, err := app.Roles.AssignToUser(ctx, admin.ID, rbac.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	token, _, err := app.JWTService.CreateImpersonationToken(user.ID, user.Username, admin.ID, app.Config.ImpersonationTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authenticate := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/user", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		rec := httptest.NewRecorder()
+
+		handler := JWTAuthMiddleware(app)(func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+		if err := handler(echo.New().NewContext(req, rec)); err != nil {
+			t.Fatalf("JWTAuthMiddleware: %v", err)
+		}
+
+		return rec.Code
+	}
+
+	if status := authenticate(); status != http.StatusOK {
+		t.Fatalf("impersonating = %d, want %d", status, http.StatusOK)
+	}
+
+	if _, err := app.Roles.RemoveFromUser(ctx, admin.ID, rbac.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	app.Permissions.Invalidate(admin.ID)
+
+	if status := authenticate(); status != http.StatusUnauthorized {
+		t.Errorf("impersonating after losing the permission = %d, want %d", status, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+// authenticateApiKey authenticates the request as the owner of a personal API key. API key requests
+// carry no tokenClaims; handlers can check for apiKeyId to tell them apart from a logged in session.
+func authenticateApiKey(c echo.Context, app *application.Application, key string, next echo.HandlerFunc, unauthorized func() error) error {
+	ctx := c.Request().Context()
+	apiKey, err := app.ApiKeys.GetValid(ctx, tokenHelper.Hash(key))
+	if err != nil {
+		return unauthorized()
+	}
+
+	if err := app.ApiKeys.RecordUse(ctx, apiKey.ID); err != nil {
+		app.ReportError(err)
+	}
+
+	c.Set("userId", apiKey.UserID)
+	c.Set("apiKeyId", apiKey.ID)
+	c.Set("bearerAuth", true)
+	return next(c)
+}
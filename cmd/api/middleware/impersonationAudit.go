@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/jwtHelper"
+)
+
+// auditImpersonatedRequest records a request made with an impersonation token before handling it, and its
+// response status afterwards. If the request can't be recorded it is refused, so nothing goes unaudited.
+func auditImpersonatedRequest(c echo.Context, app *application.Application, claims *jwtHelper.TokenClaims, next echo.HandlerFunc) error {
+	ctx := c.Request().Context()
+
+	// The path is recorded without the query string, which may hold tokens
+	entryId, err := app.ImpersonationAudit.Create(ctx, database.ImpersonationAuditEntry{
+		ImpersonatorID: claims.ImpersonatorID,
+		UserID:         claims.UserID,
+		TokenID:        claims.ID,
+		Action:         database.ImpersonationActionRequest,
+		Method:         c.Request().Method,
+		Path:           c.Request().URL.Path,
+		IPAddress:      c.RealIP(),
+		UserAgent:      c.Request().UserAgent(),
+	})
+	if err != nil {
+		return err
+	}
+
+	handlerErr := next(c)
+
+	status := c.Response().Status
+	if handlerErr != nil {
+		status = http.StatusInternalServerError
+		var httpErr *echo.HTTPError
+		if errors.As(handlerErr, &httpErr) {
+			status = httpErr.Code
+		}
+	}
+
+	// Record the status even if the client has gone away, so the entry is complete
+	if err := app.ImpersonationAudit.SetStatusCode(context.WithoutCancel(ctx), entryId, status); err != nil {
+		app.ReportError(err)
+	}
+
+	return handlerErr
+}
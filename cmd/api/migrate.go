@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/migrator"
+)
+
+// migrationsDir holds a directory of migrations per dialect, which is where `migrate create` writes new ones.
+// They are embedded in the binary, so it needs rebuilding before it can apply them.
+const migrationsDir = "database/migrations"
+
+const migrateUsage = `usage: api migrate <command>
+
+commands:
+  up                 apply all pending migrations
+  down [steps]       roll back the latest migration, or the latest steps migrations
+  status             list migrations and whether they have been applied
+  create <name>      write empty up and down files for a new migration to each dialect's directory in ` + migrationsDir + `
+  baseline <version> record migrations up to version as applied without running them, for databases set up by hand`
+
+// runMigrate runs the `migrate` subcommand with the arguments after it
+func runMigrate(logger *slog.Logger, args []string) error {
+	// The environment may be configured without a .env file, for example in production
+	_ = godotenv.Load(".env")
+
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	// Creating a migration only touches the source tree, so doesn't need a database
+	if args[0] == "create" {
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+
+		dirs := []string{}
+		for _, dialect := range []database.Dialect{database.DialectMySQL, database.DialectPostgres, database.DialectSQLite} {
+			dirs = append(dirs, filepath.Join(migrationsDir, string(dialect)))
+		}
+
+		paths, err := migrator.Create(dirs, args[1])
+		for _, path := range paths {
+			fmt.Printf("Created %s\n", path)
+		}
+		return err
+	}
+
+	dialect, err := database.ParseDialect(application.DatabaseDriver())
+	if err != nil {
+		return err
+	}
+
+	m, closeDB, err := application.NewMigrator(dialect, application.DatabaseDSN(), logger)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		ran, err := m.Up(ctx)
+		return printMigrations("Applied", ran, err)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("steps must be a positive number, got %q", args[1])
+			}
+		}
+
+		ran, err := m.Down(ctx, steps)
+		return printMigrations("Rolled back", ran, err)
+	case "baseline":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("version must be a number, got %q", args[1])
+		}
+
+		recorded, err := m.Baseline(ctx, version)
+		return printMigrations("Recorded", recorded, err)
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT\tNOTE")
+		for _, status := range statuses {
+			appliedAt, note := "pending", ""
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			if status.Modified {
+				note = "modified since applied"
+			}
+			if status.Missing {
+				note = "file missing"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Version, status.Name, appliedAt, note)
+		}
+		return w.Flush()
+	default:
+		return errors.New(migrateUsage)
+	}
+}
+
+// printMigrations lists what was done before returning err, since a failure can come after some migrations ran
+func printMigrations(verb string, migrations []migrator.Migration, err error) error {
+	if len(migrations) == 0 && err == nil {
+		fmt.Println("Nothing to do")
+	}
+
+	for _, migration := range migrations {
+		fmt.Printf("%s %d_%s\n", verb, migration.Version, migration.Name)
+	}
+
+	return err
+}
@@ -4,10 +4,15 @@ import (
 	"net/http"
 
 	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/cmd/api/handlers/AdminHandler"
 	"github.com/nathanjms/go-api-template/cmd/api/handlers/AuthHandler"
+	"github.com/nathanjms/go-api-template/cmd/api/handlers/OauthHandler"
 	"github.com/nathanjms/go-api-template/cmd/api/handlers/UserHandler"
+	"github.com/nathanjms/go-api-template/cmd/api/handlers/WellKnownHandler"
 	"github.com/nathanjms/go-api-template/cmd/api/middleware"
 	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/oauthServer"
+	"github.com/nathanjms/go-api-template/internal/rbac"
 )
 
 func InitRoutes(e *echo.Echo, app *application.Application) {
@@ -24,17 +29,98 @@ func InitRoutes(e *echo.Echo, app *application.Application) {
 		})
 	})
 
+	e.GET(".well-known/jwks.json", WellKnownHandler.JwksHandler(app))
+	e.GET(".well-known/oauth-authorization-server", WellKnownHandler.OauthMetadataHandler(app))
+	e.GET("csrf-token", AuthHandler.CsrfTokenHandler(app))
+
 	e.POST("login", AuthHandler.LoginHandler(app))
+	e.POST("login/mfa", AuthHandler.LoginMfaHandler(app))
+	e.POST("login/passkey/begin", AuthHandler.BeginPasskeyLoginHandler(app))
+	e.POST("login/passkey/finish", AuthHandler.FinishPasskeyLoginHandler(app))
+	e.POST("login/magic-link", AuthHandler.MagicLinkHandler(app))
+	e.GET("login/magic-link/callback", AuthHandler.MagicLinkCallbackHandler(app))
+	e.GET("login/oidc/:provider", AuthHandler.OidcLoginHandler(app))
+	e.GET("login/oidc/:provider/callback", AuthHandler.OidcCallbackHandler(app))
 	e.POST("register", AuthHandler.RegisterHandler(app))
 	e.POST("logout", AuthHandler.LogoutHandler(app))
+	e.POST("refresh", AuthHandler.RefreshHandler(app))
+	e.POST("password/forgot", AuthHandler.ForgotPasswordHandler(app))
+	e.POST("password/reset", AuthHandler.ResetPasswordHandler(app))
+	e.GET("verify-email", AuthHandler.VerifyEmailHandler(app))
+	e.POST("verify-email", AuthHandler.VerifyEmailHandler(app))
+
+	// OAuth2 authorization server, for third-party apps
+	e.GET("oauth/authorize", OauthHandler.AuthorizeHandler(app))
+	e.POST("oauth/token", OauthHandler.TokenHandler(app))
+	e.POST("oauth/introspect", OauthHandler.IntrospectHandler(app))
+	e.POST("oauth/revoke", OauthHandler.RevokeHandler(app))
 
 	authed := e.Group("")
 	authed.Use(middleware.JWTAuthMiddleware(app))
 
 	// --- AUTHED ROUTES ---
 
+	// Routes third-party OAuth clients can use, with the scope they need
+	authed.GET("user", UserHandler.GetAccountHandler(app), middleware.RequireScope(oauthServer.ScopeProfile))
+
+	// Everything else is only for our own sessions and API keys.
+	// Routes that change how the user signs in are not available with an impersonation token either.
+	firstParty := authed.Group("")
+	firstParty.Use(middleware.FirstPartyOnly())
+
 	// User Routes
-	authed.GET("user", UserHandler.GetAccountHandler(app))
-	authed.DELETE("user", UserHandler.DeleteAccountHandler(app))
+	firstParty.DELETE("user", UserHandler.DeleteAccountHandler(app), middleware.DenyImpersonation())
+	firstParty.POST("verify-email/resend", AuthHandler.ResendVerificationEmailHandler(app))
+	firstParty.PUT("user/password", AuthHandler.ChangePasswordHandler(app), middleware.DenyImpersonation())
+	firstParty.POST("user/email", AuthHandler.ChangeEmailHandler(app), middleware.DenyImpersonation())
+	firstParty.POST("user/email/confirm", AuthHandler.ConfirmEmailChangeHandler(app), middleware.DenyImpersonation())
+
+	// Sessions
+	firstParty.GET("user/sessions", UserHandler.ListSessionsHandler(app))
+	firstParty.DELETE("user/sessions", UserHandler.DeleteOtherSessionsHandler(app), middleware.DenyImpersonation())
+	firstParty.DELETE("user/sessions/:id", UserHandler.DeleteSessionHandler(app), middleware.DenyImpersonation())
+
+	// --- VERIFIED ROUTES ---
+	// Routes that add ways to sign in or give other apps access, which need a verified email address when
+	// REQUIRE_VERIFIED_EMAIL is on. Those above stay available so an unverified user can still fix their address,
+	// resend the verification email, or delete their account.
+	verified := firstParty.Group("")
+	verified.Use(middleware.VerifiedEmailMiddleware(app))
+
+	// Two-factor authentication
+	verified.POST("user/2fa/totp", UserHandler.SetupTotpHandler(app), middleware.DenyImpersonation())
+	verified.POST("user/2fa/totp/confirm", UserHandler.ConfirmTotpHandler(app), middleware.DenyImpersonation())
+	verified.DELETE("user/2fa/totp", UserHandler.DisableTotpHandler(app), middleware.DenyImpersonation())
+	verified.POST("user/2fa/recovery-codes", UserHandler.RegenerateRecoveryCodesHandler(app), middleware.DenyImpersonation())
+
+	// Passkeys
+	verified.GET("user/passkeys", UserHandler.ListPasskeysHandler(app))
+	verified.POST("user/passkeys/register/begin", UserHandler.BeginPasskeyRegistrationHandler(app), middleware.DenyImpersonation())
+	verified.POST("user/passkeys/register/finish", UserHandler.FinishPasskeyRegistrationHandler(app), middleware.DenyImpersonation())
+	verified.DELETE("user/passkeys/:id", UserHandler.DeletePasskeyHandler(app), middleware.DenyImpersonation())
+
+	// API keys
+	verified.GET("user/api-keys", UserHandler.ListApiKeysHandler(app))
+	verified.POST("user/api-keys", UserHandler.CreateApiKeyHandler(app), middleware.DenyImpersonation())
+	verified.DELETE("user/api-keys/:id", UserHandler.DeleteApiKeyHandler(app), middleware.DenyImpersonation())
+
+	// Third-party apps the user has authorized through OAuth
+	verified.GET("user/authorized-apps", UserHandler.ListAuthorizedAppsHandler(app))
+	verified.DELETE("user/authorized-apps/:clientId", UserHandler.RevokeAuthorizedAppHandler(app), middleware.DenyImpersonation())
+	verified.GET("oauth/consent", OauthHandler.GetConsentHandler(app))
+	verified.POST("oauth/consent", OauthHandler.ConsentHandler(app), middleware.DenyImpersonation())
 
+	// --- ADMIN ROUTES ---
+	// Each route checks its own permission, granted through the user's roles
+	admin := firstParty.Group("/admin")
+	admin.GET("/users", AdminHandler.ListUsersHandler(app), middleware.RequirePermission(app, rbac.PermissionUsersView))
+	admin.GET("/users/:id", AdminHandler.GetUserHandler(app), middleware.RequirePermission(app, rbac.PermissionUsersView))
+	admin.GET("/roles", AdminHandler.ListRolesHandler(app), middleware.RequirePermission(app, rbac.PermissionUsersManageRoles))
+	admin.POST("/users/:id/roles", AdminHandler.AssignRoleHandler(app), middleware.RequirePermission(app, rbac.PermissionUsersManageRoles))
+	admin.DELETE("/users/:id/roles/:role", AdminHandler.RemoveRoleHandler(app), middleware.RequirePermission(app, rbac.PermissionUsersManageRoles))
+	admin.POST("/users/:id/impersonate", AdminHandler.ImpersonateUserHandler(app), middleware.RequirePermission(app, rbac.PermissionUsersImpersonate), middleware.DenyImpersonation())
+	admin.GET("/impersonation-audit-log", AdminHandler.ListImpersonationAuditHandler(app), middleware.RequirePermission(app, rbac.PermissionUsersView))
+	admin.GET("/oauth/clients", AdminHandler.ListOauthClientsHandler(app), middleware.RequirePermission(app, rbac.PermissionOauthManageClients))
+	admin.POST("/oauth/clients", AdminHandler.CreateOauthClientHandler(app), middleware.RequirePermission(app, rbac.PermissionOauthManageClients))
+	admin.DELETE("/oauth/clients/:id", AdminHandler.RevokeOauthClientHandler(app), middleware.RequirePermission(app, rbac.PermissionOauthManageClients))
 }
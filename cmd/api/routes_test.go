@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nathanjms/go-api-template/internal/testHelper"
+)
+
+func TestVerifiedRoutesRequireVerifiedEmail(t *testing.T) {
+	cfg := testHelper.Config(t)
+	cfg.RequireVerifiedEmail = true
+	app, _ := testHelper.NewAppWithConfig(t, cfg)
+
+	e := echo.New()
+	InitRoutes(e, app)
+
+	user := testHelper.CreateUser(t, app, "user@example.com", "correct horse battery staple")
+	token := testHelper.AccessToken(t, app, user)
+
+	get := func(path string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if status := get("/user/api-keys"); status != http.StatusForbidden {
+		t.Errorf("unverified GET /user/api-keys = %d, want %d", status, http.StatusForbidden)
+	}
+	// Routes outside the group stay available, so the user can still manage their account
+	if status := get("/user/sessions"); status != http.StatusOK {
+		t.Errorf("unverified GET /user/sessions = %d, want %d", status, http.StatusOK)
+	}
+
+	if err := app.Users.MarkEmailVerified(context.Background(), user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if status := get("/user/api-keys"); status != http.StatusOK {
+		t.Errorf("verified GET /user/api-keys = %d, want %d", status, http.StatusOK)
+	}
+}
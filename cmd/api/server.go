@@ -1,13 +1,17 @@
 package main
 
 import (
+	"errors"
+	"net/http"
 	"strconv"
 	"strings"
 
 	sentryecho "github.com/getsentry/sentry-go/echo"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	apiMiddleware "github.com/nathanjms/go-api-template/cmd/api/middleware"
 	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
 	"github.com/nathanjms/go-api-template/internal/env"
 )
 
@@ -15,10 +19,28 @@ func serveHttp(app *application.Application) error {
 	e := echo.New()
 
 	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		var canceledErr *database.CanceledError
+		if errors.As(err, &canceledErr) {
+			if !canceledErr.Timeout() {
+				// The client went away, so there is nobody to respond to and nothing went wrong
+				app.Logger.Debug("Request canceled by client", "path", c.Request().URL.Path)
+				return
+			}
+			err = echo.NewHTTPError(http.StatusServiceUnavailable, "The request took too long, please try again").SetInternal(err)
+		}
+
 		app.ReportError(err)
 		e.DefaultHTTPErrorHandler(err, c)
 	}
 
+	// Only trust X-Forwarded-For when running behind a proxy, otherwise clients could pick their own IP
+	// and get around per-IP login throttling
+	if env.GetBool("TRUST_PROXY_HEADERS", false) {
+		e.IPExtractor = echo.ExtractIPFromXFFHeader()
+	} else {
+		e.IPExtractor = echo.ExtractIPDirect()
+	}
+
 	e.Use(middleware.Recover())
 	e.Use(sentryecho.New(sentryecho.Options{}))
 	// Once it's done, you can attach the handler as one of your middleware
@@ -32,9 +54,10 @@ func serveHttp(app *application.Application) error {
 
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins:     allowedOrigins,
-		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderContentEncoding},
+		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderContentEncoding, echo.HeaderAuthorization, "X-API-Key", echo.HeaderXCSRFToken},
 		AllowCredentials: true,
 	}))
+	e.Use(apiMiddleware.CSRFMiddleware(app))
 	InitRoutes(e, app)
 
 	app.Logger.Info("Starting server on port " + strconv.Itoa(app.Config.HTTPPort))
@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migrations so the binary can apply them without the source tree
+package migrations
+
+import "embed"
+
+// FS holds a directory per dialect (mysql, postgres and sqlite) of <version>_<name>.up.sql and .down.sql files.
+// Every dialect has the same migrations, written in its own SQL.
+//
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var FS embed.FS
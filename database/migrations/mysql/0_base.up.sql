@@ -5,4 +5,4 @@ CREATE TABLE `users` (
     `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
     PRIMARY KEY (`id`),
     UNIQUE KEY `users_username_unique` (`username`)
-);
\ No newline at end of file
+);
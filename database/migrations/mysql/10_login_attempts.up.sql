@@ -0,0 +1,8 @@
+CREATE TABLE `login_attempts` (
+    `throttle_key` varchar(255) NOT NULL,
+    `failures` int unsigned NOT NULL DEFAULT 0,
+    `last_failure_at` timestamp NOT NULL,
+    `locked_until` timestamp NULL DEFAULT NULL,
+    PRIMARY KEY (`throttle_key`),
+    KEY `login_attempts_last_failure_at_index` (`last_failure_at`)
+);
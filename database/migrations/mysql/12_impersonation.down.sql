@@ -0,0 +1,3 @@
+DROP TABLE `impersonation_audit_log`;
+
+DELETE FROM `permissions` WHERE `name` = 'users.impersonate';
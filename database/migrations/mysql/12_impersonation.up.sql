@@ -0,0 +1,23 @@
+INSERT INTO `permissions` (`name`, `description`) VALUES ('users.impersonate', 'Act as another user, for support');
+
+INSERT INTO `role_permissions` (`role_id`, `permission_id`)
+SELECT `roles`.`id`, `permissions`.`id` FROM `roles` CROSS JOIN `permissions`
+WHERE `roles`.`name` = 'admin' AND `permissions`.`name` = 'users.impersonate';
+
+CREATE TABLE `impersonation_audit_log` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `impersonator_id` bigint unsigned NOT NULL,
+    `user_id` bigint unsigned NOT NULL,
+    `token_id` char(36) NOT NULL,
+    `action` varchar(16) NOT NULL,
+    `method` varchar(10) NOT NULL DEFAULT '',
+    `path` varchar(1024) NOT NULL DEFAULT '',
+    `status_code` smallint unsigned NULL DEFAULT NULL,
+    `ip_address` varchar(45) NOT NULL DEFAULT '',
+    `user_agent` varchar(512) NOT NULL DEFAULT '',
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `impersonation_audit_log_impersonator_id_index` (`impersonator_id`),
+    KEY `impersonation_audit_log_user_id_index` (`user_id`),
+    KEY `impersonation_audit_log_token_id_index` (`token_id`)
+);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS `user_workout_backups` (
+    `user_id` bigint unsigned NOT NULL,
+    `backup_path` varchar(1024) NOT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`user_id`),
+    CONSTRAINT `user_workout_backups_user_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS `feedback` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `name` varchar(255) NOT NULL,
+    `user_id` bigint unsigned NOT NULL,
+    `type` varchar(64) NOT NULL,
+    `description` text NOT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `feedback_user_id_index` (`user_id`)
+);
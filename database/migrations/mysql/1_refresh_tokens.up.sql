@@ -0,0 +1,15 @@
+CREATE TABLE `refresh_tokens` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` bigint unsigned NOT NULL,
+    `family_id` char(36) NOT NULL,
+    `token_hash` char(64) NOT NULL,
+    `remember_me` tinyint(1) NOT NULL DEFAULT 0,
+    `expires_at` timestamp NOT NULL,
+    `used_at` timestamp NULL DEFAULT NULL,
+    `revoked_at` timestamp NULL DEFAULT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `refresh_tokens_token_hash_unique` (`token_hash`),
+    KEY `refresh_tokens_family_id_index` (`family_id`),
+    CONSTRAINT `refresh_tokens_user_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+);
@@ -0,0 +1,14 @@
+CREATE TABLE `user_tokens` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` bigint unsigned NOT NULL,
+    `purpose` varchar(32) NOT NULL,
+    `token_hash` char(64) NOT NULL,
+    `payload` varchar(255) NULL DEFAULT NULL,
+    `expires_at` timestamp NOT NULL,
+    `used_at` timestamp NULL DEFAULT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `user_tokens_token_hash_unique` (`token_hash`),
+    KEY `user_tokens_user_id_purpose_index` (`user_id`, `purpose`),
+    CONSTRAINT `user_tokens_user_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+);
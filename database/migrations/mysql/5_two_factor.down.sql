@@ -0,0 +1,6 @@
+DROP TABLE `recovery_codes`;
+
+ALTER TABLE `users`
+    DROP COLUMN `totp_secret`,
+    DROP COLUMN `totp_enabled_at`,
+    DROP COLUMN `totp_last_counter`;
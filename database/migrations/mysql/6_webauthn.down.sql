@@ -0,0 +1,3 @@
+DROP TABLE `webauthn_sessions`;
+
+DROP TABLE `user_credentials`;
@@ -0,0 +1,31 @@
+CREATE TABLE `user_credentials` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` bigint unsigned NOT NULL,
+    `name` varchar(255) NOT NULL,
+    `credential_id` varbinary(1023) NOT NULL,
+    `public_key` blob NOT NULL,
+    `attestation_type` varchar(32) NOT NULL,
+    `aaguid` varbinary(16) NOT NULL,
+    `sign_count` int unsigned NOT NULL DEFAULT 0,
+    `flags` tinyint unsigned NOT NULL DEFAULT 0,
+    `transports` varchar(255) NOT NULL DEFAULT '',
+    `last_used_at` timestamp NULL DEFAULT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `user_credentials_credential_id_unique` (`credential_id`),
+    KEY `user_credentials_user_id_index` (`user_id`),
+    CONSTRAINT `user_credentials_user_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+);
+
+CREATE TABLE `webauthn_sessions` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `token_hash` char(64) NOT NULL,
+    `user_id` bigint unsigned NULL DEFAULT NULL,
+    `ceremony` varchar(16) NOT NULL,
+    `data` text NOT NULL,
+    `expires_at` timestamp NOT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `webauthn_sessions_token_hash_unique` (`token_hash`),
+    CONSTRAINT `webauthn_sessions_user_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+);
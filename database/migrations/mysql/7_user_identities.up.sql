@@ -0,0 +1,13 @@
+CREATE TABLE `user_identities` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` bigint unsigned NOT NULL,
+    `provider` varchar(64) NOT NULL,
+    `subject` varchar(255) NOT NULL,
+    `email` varchar(255) NOT NULL DEFAULT '',
+    `last_login_at` timestamp NULL DEFAULT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `user_identities_provider_subject_unique` (`provider`, `subject`),
+    KEY `user_identities_user_id_index` (`user_id`),
+    CONSTRAINT `user_identities_user_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+);
@@ -0,0 +1,14 @@
+CREATE TABLE `api_keys` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `user_id` bigint unsigned NOT NULL,
+    `name` varchar(255) NOT NULL,
+    `prefix` varchar(16) NOT NULL,
+    `key_hash` char(64) NOT NULL,
+    `expires_at` timestamp NULL DEFAULT NULL,
+    `last_used_at` timestamp NULL DEFAULT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `api_keys_key_hash_unique` (`key_hash`),
+    KEY `api_keys_user_id_index` (`user_id`),
+    CONSTRAINT `api_keys_user_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+);
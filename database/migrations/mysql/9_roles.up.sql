@@ -0,0 +1,43 @@
+CREATE TABLE `roles` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `name` varchar(64) NOT NULL,
+    `description` varchar(255) NOT NULL DEFAULT '',
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `roles_name_unique` (`name`)
+);
+
+CREATE TABLE `permissions` (
+    `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+    `name` varchar(64) NOT NULL,
+    `description` varchar(255) NOT NULL DEFAULT '',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `permissions_name_unique` (`name`)
+);
+
+CREATE TABLE `role_permissions` (
+    `role_id` bigint unsigned NOT NULL,
+    `permission_id` bigint unsigned NOT NULL,
+    PRIMARY KEY (`role_id`, `permission_id`),
+    CONSTRAINT `role_permissions_role_id_foreign` FOREIGN KEY (`role_id`) REFERENCES `roles` (`id`) ON DELETE CASCADE,
+    CONSTRAINT `role_permissions_permission_id_foreign` FOREIGN KEY (`permission_id`) REFERENCES `permissions` (`id`) ON DELETE CASCADE
+);
+
+CREATE TABLE `user_roles` (
+    `user_id` bigint unsigned NOT NULL,
+    `role_id` bigint unsigned NOT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`user_id`, `role_id`),
+    KEY `user_roles_role_id_index` (`role_id`),
+    CONSTRAINT `user_roles_user_id_foreign` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+    CONSTRAINT `user_roles_role_id_foreign` FOREIGN KEY (`role_id`) REFERENCES `roles` (`id`) ON DELETE CASCADE
+);
+
+INSERT INTO `roles` (`name`, `description`) VALUES ('admin', 'Full access to the admin API');
+
+INSERT INTO `permissions` (`name`, `description`) VALUES
+    ('users.view', 'View any user account'),
+    ('users.manage_roles', 'Assign and remove roles');
+
+INSERT INTO `role_permissions` (`role_id`, `permission_id`)
+SELECT `roles`.`id`, `permissions`.`id` FROM `roles` CROSS JOIN `permissions` WHERE `roles`.`name` = 'admin';
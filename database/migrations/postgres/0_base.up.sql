@@ -0,0 +1,10 @@
+CREATE EXTENSION IF NOT EXISTS citext;
+
+CREATE TABLE users (
+    id bigint GENERATED BY DEFAULT AS IDENTITY,
+    username citext NOT NULL,
+    password varchar(255) NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT users_username_unique UNIQUE (username)
+);
@@ -0,0 +1,9 @@
+CREATE TABLE login_attempts (
+    throttle_key varchar(255) NOT NULL,
+    failures integer NOT NULL DEFAULT 0,
+    last_failure_at timestamp NOT NULL,
+    locked_until timestamp NULL DEFAULT NULL,
+    PRIMARY KEY (throttle_key)
+);
+
+CREATE INDEX login_attempts_last_failure_at_index ON login_attempts (last_failure_at);
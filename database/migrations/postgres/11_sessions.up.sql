@@ -0,0 +1,14 @@
+CREATE TABLE sessions (
+    id char(36) NOT NULL,
+    user_id bigint NOT NULL,
+    user_agent varchar(512) NOT NULL DEFAULT '',
+    ip_address varchar(45) NOT NULL DEFAULT '',
+    expires_at timestamp NOT NULL,
+    last_seen_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at timestamp NULL DEFAULT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT sessions_user_id_foreign FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX sessions_user_id_index ON sessions (user_id);
@@ -0,0 +1,48 @@
+CREATE TABLE oauth_clients (
+    id varchar(64) NOT NULL,
+    name varchar(255) NOT NULL,
+    secret_hash char(64) NULL DEFAULT NULL,
+    redirect_uris text NOT NULL,
+    scopes varchar(1024) NOT NULL DEFAULT '',
+    grant_types varchar(255) NOT NULL DEFAULT '',
+    created_by bigint NULL DEFAULT NULL,
+    revoked_at timestamp NULL DEFAULT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE oauth_authorization_codes (
+    code_hash char(64) NOT NULL,
+    client_id varchar(64) NOT NULL,
+    user_id bigint NOT NULL,
+    redirect_uri varchar(2048) NOT NULL,
+    scopes varchar(1024) NOT NULL DEFAULT '',
+    code_challenge varchar(128) NOT NULL,
+    expires_at timestamp NOT NULL,
+    used_at timestamp NULL DEFAULT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (code_hash),
+    CONSTRAINT oauth_authorization_codes_client_id_foreign FOREIGN KEY (client_id) REFERENCES oauth_clients (id) ON DELETE CASCADE,
+    CONSTRAINT oauth_authorization_codes_user_id_foreign FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX oauth_authorization_codes_expires_at_index ON oauth_authorization_codes (expires_at);
+
+CREATE TABLE oauth_consents (
+    user_id bigint NOT NULL,
+    client_id varchar(64) NOT NULL,
+    scopes varchar(1024) NOT NULL DEFAULT '',
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, client_id),
+    CONSTRAINT oauth_consents_user_id_foreign FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT oauth_consents_client_id_foreign FOREIGN KEY (client_id) REFERENCES oauth_clients (id) ON DELETE CASCADE
+);
+
+CREATE INDEX oauth_consents_client_id_index ON oauth_consents (client_id);
+
+INSERT INTO permissions (name, description) VALUES ('oauth.manage_clients', 'Register and revoke OAuth clients');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id FROM roles CROSS JOIN permissions
+WHERE roles.name = 'admin' AND permissions.name = 'oauth.manage_clients';
@@ -0,0 +1,16 @@
+CREATE TABLE refresh_tokens (
+    id bigint GENERATED BY DEFAULT AS IDENTITY,
+    user_id bigint NOT NULL,
+    family_id char(36) NOT NULL,
+    token_hash char(64) NOT NULL,
+    remember_me boolean NOT NULL DEFAULT false,
+    expires_at timestamp NOT NULL,
+    used_at timestamp NULL DEFAULT NULL,
+    revoked_at timestamp NULL DEFAULT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT refresh_tokens_token_hash_unique UNIQUE (token_hash),
+    CONSTRAINT refresh_tokens_user_id_foreign FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX refresh_tokens_family_id_index ON refresh_tokens (family_id);
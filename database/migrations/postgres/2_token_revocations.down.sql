@@ -0,0 +1,3 @@
+DROP TABLE user_token_revocations;
+
+DROP TABLE revoked_tokens;
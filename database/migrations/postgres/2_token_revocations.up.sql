@@ -0,0 +1,15 @@
+CREATE TABLE revoked_tokens (
+    jti char(36) NOT NULL,
+    user_id bigint NOT NULL,
+    expires_at timestamp NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (jti)
+);
+
+CREATE INDEX revoked_tokens_expires_at_index ON revoked_tokens (expires_at);
+
+CREATE TABLE user_token_revocations (
+    user_id bigint NOT NULL,
+    revoked_before timestamp NOT NULL,
+    PRIMARY KEY (user_id)
+);
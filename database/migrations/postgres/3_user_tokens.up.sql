@@ -0,0 +1,15 @@
+CREATE TABLE user_tokens (
+    id bigint GENERATED BY DEFAULT AS IDENTITY,
+    user_id bigint NOT NULL,
+    purpose varchar(32) NOT NULL,
+    token_hash char(64) NOT NULL,
+    payload varchar(255) NULL DEFAULT NULL,
+    expires_at timestamp NOT NULL,
+    used_at timestamp NULL DEFAULT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT user_tokens_token_hash_unique UNIQUE (token_hash),
+    CONSTRAINT user_tokens_user_id_foreign FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX user_tokens_user_id_purpose_index ON user_tokens (user_id, purpose);
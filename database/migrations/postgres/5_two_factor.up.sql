@@ -0,0 +1,16 @@
+ALTER TABLE users
+    ADD COLUMN totp_secret varchar(64) NULL DEFAULT NULL,
+    ADD COLUMN totp_enabled_at timestamp NULL DEFAULT NULL,
+    ADD COLUMN totp_last_counter bigint NULL DEFAULT NULL;
+
+CREATE TABLE recovery_codes (
+    id bigint GENERATED BY DEFAULT AS IDENTITY,
+    user_id bigint NOT NULL,
+    code_hash char(64) NOT NULL,
+    used_at timestamp NULL DEFAULT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT recovery_codes_user_id_foreign FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX recovery_codes_user_id_index ON recovery_codes (user_id);
@@ -0,0 +1,32 @@
+CREATE TABLE user_credentials (
+    id bigint GENERATED BY DEFAULT AS IDENTITY,
+    user_id bigint NOT NULL,
+    name varchar(255) NOT NULL,
+    credential_id bytea NOT NULL,
+    public_key bytea NOT NULL,
+    attestation_type varchar(32) NOT NULL,
+    aaguid bytea NOT NULL,
+    sign_count bigint NOT NULL DEFAULT 0,
+    flags smallint NOT NULL DEFAULT 0,
+    transports varchar(255) NOT NULL DEFAULT '',
+    last_used_at timestamp NULL DEFAULT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT user_credentials_credential_id_unique UNIQUE (credential_id),
+    CONSTRAINT user_credentials_user_id_foreign FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX user_credentials_user_id_index ON user_credentials (user_id);
+
+CREATE TABLE webauthn_sessions (
+    id bigint GENERATED BY DEFAULT AS IDENTITY,
+    token_hash char(64) NOT NULL,
+    user_id bigint NULL DEFAULT NULL,
+    ceremony varchar(16) NOT NULL,
+    data text NOT NULL,
+    expires_at timestamp NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT webauthn_sessions_token_hash_unique UNIQUE (token_hash),
+    CONSTRAINT webauthn_sessions_user_id_foreign FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
@@ -0,0 +1,9 @@
+CREATE TABLE login_attempts (
+    throttle_key TEXT NOT NULL,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY (throttle_key)
+);
+
+CREATE INDEX login_attempts_last_failure_at_index ON login_attempts (last_failure_at);
@@ -0,0 +1,25 @@
+INSERT INTO permissions (name, description) VALUES ('users.impersonate', 'Act as another user, for support');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id FROM roles CROSS JOIN permissions
+WHERE roles.name = 'admin' AND permissions.name = 'users.impersonate';
+
+CREATE TABLE impersonation_audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    impersonator_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    token_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    method TEXT NOT NULL DEFAULT '',
+    path TEXT NOT NULL DEFAULT '',
+    status_code INTEGER NULL DEFAULT NULL,
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX impersonation_audit_log_impersonator_id_index ON impersonation_audit_log (impersonator_id);
+
+CREATE INDEX impersonation_audit_log_user_id_index ON impersonation_audit_log (user_id);
+
+CREATE INDEX impersonation_audit_log_token_id_index ON impersonation_audit_log (token_id);
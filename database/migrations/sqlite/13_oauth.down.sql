@@ -0,0 +1,7 @@
+DELETE FROM permissions WHERE name = 'oauth.manage_clients';
+
+DROP TABLE oauth_consents;
+
+DROP TABLE oauth_authorization_codes;
+
+DROP TABLE oauth_clients;
@@ -0,0 +1,3 @@
+DROP TABLE feedback;
+
+DROP TABLE user_workout_backups;
@@ -0,0 +1,15 @@
+CREATE TABLE revoked_tokens (
+    jti TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (jti)
+);
+
+CREATE INDEX revoked_tokens_expires_at_index ON revoked_tokens (expires_at);
+
+CREATE TABLE user_token_revocations (
+    user_id INTEGER NOT NULL,
+    revoked_before TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id)
+);
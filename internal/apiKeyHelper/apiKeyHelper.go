@@ -0,0 +1,36 @@
+package apiKeyHelper
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
+)
+
+// Prefix marks a bearer token as an API key rather than a JWT, and makes leaked keys easy to scan for
+const Prefix = "gak_"
+
+// Generate creates a new API key of the form gak_<id>_<secret>. The returned prefix (gak_<id>) is safe to
+// display, and the hash is what should be stored.
+func Generate() (key string, prefix string, hash string, err error) {
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", "", err
+	}
+
+	secret, _, err := tokenHelper.Generate()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	prefix = Prefix + hex.EncodeToString(id)
+	key = prefix + "_" + secret
+
+	return key, prefix, tokenHelper.Hash(key), nil
+}
+
+// IsApiKey reports whether a bearer token looks like an API key
+func IsApiKey(token string) bool {
+	return strings.HasPrefix(token, Prefix)
+}
@@ -1,37 +1,121 @@
 package application
 
 import (
+	"cmp"
+	"context"
+	"fmt"
 	"log/slog"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/getsentry/sentry-go"
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/nathanjms/go-api-template/internal/awsHelper"
 	"github.com/nathanjms/go-api-template/internal/database"
 	"github.com/nathanjms/go-api-template/internal/env"
 	"github.com/nathanjms/go-api-template/internal/jwtHelper"
+	"github.com/nathanjms/go-api-template/internal/mailer"
+	"github.com/nathanjms/go-api-template/internal/oidcHelper"
+	"github.com/nathanjms/go-api-template/internal/passwordHasher"
+	"github.com/nathanjms/go-api-template/internal/passwordPolicy"
+	"github.com/nathanjms/go-api-template/internal/rbac"
+	"github.com/nathanjms/go-api-template/internal/revocation"
+	"github.com/nathanjms/go-api-template/internal/throttle"
+	"github.com/nathanjms/go-api-template/internal/webauthnHelper"
 )
 
+// maxImpersonationTTL caps IMPERSONATION_TTL, so an admin's access to another user's account is always short-lived
+const maxImpersonationTTL = time.Hour
+
 type Config struct {
-	BaseURL  string
-	HTTPPort int
-	JWT      struct {
-		SecretKey string
+	AppName     string
+	BaseURL     string
+	FrontendURL string
+	HTTPPort    int
+	DB          struct {
+		Driver         string
+		DSN            string
+		MigrateOnStart bool
+		QueryTimeout   time.Duration
+	}
+	JWT struct {
+		SecretKey       string
+		KeysDir         string
+		SigningKeyID    string
+		AccessTokenTTL  time.Duration
+		RefreshTokenTTL time.Duration
+		RememberMeTTL   time.Duration
+		RevocationTTL   time.Duration
+	}
+	Passwords struct {
+		Algorithm  string
+		Argon2     passwordHasher.Argon2Params
+		BcryptCost int
+		Policy     passwordPolicy.Config
+	}
+	RBAC struct {
+		CacheTTL time.Duration
+	}
+	OAuth struct {
+		AccessTokenTTL       time.Duration
+		AuthorizationCodeTTL time.Duration
+	}
+	LoginThrottle struct {
+		Backend  string
+		Accounts throttle.Policy
+		IPs      throttle.Policy
 	}
 	AWS struct {
 		Bucket string
 	}
+	WebAuthn struct {
+		RPID    string
+		Origins []string
+	}
+	OIDC                 []oidcHelper.ProviderConfig
+	Mail                 mailer.Config
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+	MagicLinkTTL         time.Duration
+	MagicLinkBindBrowser bool
+	RequireVerifiedEmail bool
+	ImpersonationTTL     time.Duration
 }
 
 type Application struct {
-	Config            Config
-	DB                *database.DB
+	Config Config
+	// DB is the connection pool, which is nil when running on the memory stores
+	DB *database.DB
+	// Stores are how handlers reach the data, whether it is kept in the database or in memory
+	database.Stores
 	sentryInitialized bool
 	Logger            *slog.Logger
 	S3                *awsHelper.S3Helper
 	JWTService        *jwtHelper.JWTService
+	Passwords         *passwordHasher.Hasher
+	PasswordPolicy    *passwordPolicy.Policy
+	Revocations       *revocation.Store
+	Permissions       *rbac.Store
+	AccountThrottle   *throttle.Limiter
+	IPThrottle        *throttle.Limiter
+	Mailer            mailer.Mailer
+	WebAuthn          *webauthn.WebAuthn
+	OIDCProviders     map[string]*oidcHelper.Provider
+	background        sync.WaitGroup
+	cleanupStop       chan struct{}
+	cleanupDone       chan struct{}
+}
+
+// Options are the settings chosen on the command line rather than in the environment
+type Options struct {
+	// Memory keeps everything in memory rather than in a database, so the API can be tried out without setting one
+	// up. Everything is lost when it exits.
+	Memory bool
 }
 
-func New(logger *slog.Logger) (*Application, error) {
+func New(logger *slog.Logger, opts Options) (*Application, error) {
 	app := &Application{}
 	// --- Initialize Sentry Error reporting ---
 	if err := initSentry(logger, app); err != nil {
@@ -39,30 +123,149 @@ func New(logger *slog.Logger) (*Application, error) {
 	}
 
 	// --- Config ---
-	cfg := initConfig()
+	cfg := LoadConfig()
+
+	if opts.Memory {
+		logger.Warn("Keeping everything in memory, nothing will be kept after exiting")
+		// There is no database to share login attempts through
+		cfg.LoginThrottle.Backend = "memory"
+
+		if err := app.init(logger, cfg, nil, database.NewMemoryStores()); err != nil {
+			return nil, err
+		}
+		return app, nil
+	}
 
 	// --- DB ---
-	db, err := database.New(env.GetString("DB_DSN", "root:password@tcp(localhost:3306)/api-db"))
+	dialect, err := database.ParseDialect(cfg.DB.Driver)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := database.New(dialect, cfg.DB.DSN, cfg.DB.QueryTimeout)
 	if err != nil {
 		return nil, err
 	}
 
+	if cfg.DB.MigrateOnStart {
+		if err := migrateUp(dialect, cfg.DB.DSN, logger); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	if err := app.init(logger, cfg, db, db.Stores); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return app, nil
+}
+
+// NewWithStores creates an application that uses the given stores instead of connecting to a database, so handlers
+// can be tested on database.NewMemoryStores. Sentry is not set up.
+func NewWithStores(logger *slog.Logger, cfg Config, stores database.Stores) (*Application, error) {
+	app := &Application{}
+	if err := app.init(logger, cfg, nil, stores); err != nil {
+		return nil, err
+	}
+
+	return app, nil
+}
+
+// init sets up everything apart from Sentry and the database. db is nil when not using one.
+func (app *Application) init(logger *slog.Logger, cfg Config, db *database.DB, stores database.Stores) error {
 	// --- AWS ---
 	s3 := initS3(cfg.AWS.Bucket)
 
 	// --- JWT ---
-	jwtService, err := jwtHelper.NewJWTService(cfg.JWT.SecretKey)
+	keyring, err := jwtHelper.LoadKeyring(cfg.JWT.KeysDir, cfg.JWT.SigningKeyID, cfg.JWT.SecretKey)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	jwtService, err := jwtHelper.NewJWTService(keyring, cfg.JWT.AccessTokenTTL, cfg.JWT.RefreshTokenTTL, cfg.JWT.RememberMeTTL)
+	if err != nil {
+		return err
+	}
+
+	// --- Passwords ---
+	passwords, err := passwordHasher.New(cfg.Passwords.Algorithm, cfg.Passwords.Argon2, cfg.Passwords.BcryptCost)
+	if err != nil {
+		return err
+	}
+
+	policy, err := passwordPolicy.New(cfg.Passwords.Policy)
+	if err != nil {
+		return err
 	}
 
+	// --- Impersonation ---
+	if cfg.ImpersonationTTL <= 0 || cfg.ImpersonationTTL > maxImpersonationTTL {
+		return fmt.Errorf("IMPERSONATION_TTL must be more than 0 and at most %s, got %s", maxImpersonationTTL, cfg.ImpersonationTTL)
+	}
+
+	// --- Mail ---
+	// The log and file drivers write whole emails, with the links and tokens in them, to the logs or to disk
+	if (cfg.Mail.Driver == "log" || cfg.Mail.Driver == "" || cfg.Mail.Driver == "file") && os.Getenv("ENV") != "local" {
+		return fmt.Errorf("MAIL_DRIVER %s is only allowed with ENV=local", cmp.Or(cfg.Mail.Driver, "log"))
+	}
+	mail, err := mailer.New(cfg.Mail, logger)
+	if err != nil {
+		return err
+	}
+
+	// --- WebAuthn ---
+	webAuthn, err := webauthnHelper.New(cfg.WebAuthn.RPID, cfg.AppName, cfg.WebAuthn.Origins)
+	if err != nil {
+		return err
+	}
+
+	// --- OpenID Connect ---
+	oidcProviders := map[string]*oidcHelper.Provider{}
+	for _, providerConfig := range cfg.OIDC {
+		oidcProviders[providerConfig.Name] = oidcHelper.NewProvider(providerConfig)
+	}
+
+	// --- Token revocation ---
+	revocations := revocation.New(stores, cfg.JWT.RevocationTTL)
+
+	// --- Login throttling ---
+	var throttleBackend throttle.Backend
+	switch cfg.LoginThrottle.Backend {
+	case "memory":
+		throttleBackend = throttle.NewMemoryBackend()
+	case "database":
+		if db == nil {
+			return fmt.Errorf("LOGIN_THROTTLE_BACKEND %s needs a database", cfg.LoginThrottle.Backend)
+		}
+		throttleBackend = db.LoginAttempts
+	default:
+		return fmt.Errorf("unknown LOGIN_THROTTLE_BACKEND: %s", cfg.LoginThrottle.Backend)
+	}
+
+	// --- Roles and permissions ---
+	permissions := rbac.New(stores.Roles, cfg.RBAC.CacheTTL)
+
 	app.Config = cfg
 	app.DB = db
+	app.Stores = stores
 	app.Logger = logger
 	app.S3 = s3
 	app.JWTService = jwtService
+	app.Passwords = passwords
+	app.PasswordPolicy = policy
+	app.Revocations = revocations
+	app.Permissions = permissions
+	app.AccountThrottle = throttle.NewLimiter(throttleBackend, cfg.LoginThrottle.Accounts)
+	app.IPThrottle = throttle.NewLimiter(throttleBackend, cfg.LoginThrottle.IPs)
+	app.Mailer = mail
+	app.WebAuthn = webAuthn
+	app.OIDCProviders = oidcProviders
 
-	return app, nil
+	app.startCleanup()
+
+	return nil
 }
 
 func initSentry(logger *slog.Logger, app *Application) error {
@@ -84,17 +287,113 @@ func initSentry(logger *slog.Logger, app *Application) error {
 	return nil
 }
 
-func initConfig() Config {
+// LoadConfig reads the configuration from the environment, using defaults for anything not set
+func LoadConfig() Config {
 	var cfg Config
 
+	cfg.AppName = env.GetString("APP_NAME", "Go API Template")
 	cfg.BaseURL = env.GetString("BASE_URL", "http://localhost")
+	cfg.FrontendURL = env.GetString("FRONTEND_URL", "http://localhost:3000")
 	cfg.HTTPPort = env.GetInt("PORT", 3000)
+	cfg.DB.Driver = DatabaseDriver()
+	cfg.DB.DSN = DatabaseDSN()
+	cfg.DB.MigrateOnStart = env.GetBool("MIGRATE_ON_START", false)
+	cfg.DB.QueryTimeout = env.GetDuration("DB_QUERY_TIMEOUT", 5*time.Second)
 	cfg.JWT.SecretKey = env.GetString("RSA_PRIVATE_KEY", "secret")
+	cfg.JWT.KeysDir = env.GetString("JWT_KEYS_DIR", "")
+	cfg.JWT.SigningKeyID = env.GetString("JWT_SIGNING_KEY_ID", "")
+	cfg.JWT.AccessTokenTTL = env.GetDuration("JWT_ACCESS_TOKEN_TTL", 15*time.Minute)
+	cfg.JWT.RefreshTokenTTL = env.GetDuration("JWT_REFRESH_TOKEN_TTL", 24*time.Hour)
+	cfg.JWT.RememberMeTTL = env.GetDuration("JWT_REMEMBER_ME_TTL", 28*24*time.Hour)
+	cfg.JWT.RevocationTTL = env.GetDuration("JWT_REVOCATION_CACHE_TTL", 30*time.Second)
+	cfg.Passwords.Algorithm = env.GetString("PASSWORD_HASH_ALGORITHM", passwordHasher.Argon2id)
+	cfg.Passwords.Argon2 = passwordHasher.Argon2Params{
+		Memory:      uint32(env.GetInt("ARGON2_MEMORY_KIB", 64*1024)),
+		Iterations:  uint32(env.GetInt("ARGON2_ITERATIONS", 3)),
+		Parallelism: uint8(env.GetInt("ARGON2_PARALLELISM", 2)),
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+	cfg.Passwords.BcryptCost = env.GetInt("BCRYPT_COST", 10)
+	cfg.Passwords.Policy = passwordPolicy.Config{
+		MinLength:        env.GetInt("PASSWORD_MIN_LENGTH", 8),
+		MaxLength:        env.GetInt("PASSWORD_MAX_LENGTH", 128),
+		RequireUppercase: env.GetBool("PASSWORD_REQUIRE_UPPERCASE", false),
+		RequireLowercase: env.GetBool("PASSWORD_REQUIRE_LOWERCASE", false),
+		RequireNumber:    env.GetBool("PASSWORD_REQUIRE_NUMBER", false),
+		RequireSymbol:    env.GetBool("PASSWORD_REQUIRE_SYMBOL", false),
+		RejectEmail:      env.GetBool("PASSWORD_REJECT_EMAIL", true),
+		RejectCommon:     env.GetBool("PASSWORD_REJECT_COMMON", true),
+		BreachedListPath: env.GetString("PASSWORD_BREACHED_LIST_PATH", ""),
+	}
+
+	cfg.RBAC.CacheTTL = env.GetDuration("RBAC_CACHE_TTL", 30*time.Second)
+	cfg.OAuth.AccessTokenTTL = env.GetDuration("OAUTH_ACCESS_TOKEN_TTL", time.Hour)
+	cfg.OAuth.AuthorizationCodeTTL = env.GetDuration("OAUTH_AUTHORIZATION_CODE_TTL", time.Minute)
+	cfg.LoginThrottle.Backend = env.GetString("LOGIN_THROTTLE_BACKEND", "memory")
+	cfg.LoginThrottle.Accounts = throttle.Policy{
+		FreeAttempts: env.GetInt("LOGIN_MAX_ATTEMPTS_PER_ACCOUNT", 5),
+		BaseDelay:    env.GetDuration("LOGIN_LOCKOUT_BASE_DELAY", 30*time.Second),
+		MaxDelay:     env.GetDuration("LOGIN_LOCKOUT_MAX_DELAY", 15*time.Minute),
+		ResetAfter:   env.GetDuration("LOGIN_ATTEMPTS_RESET_AFTER", time.Hour),
+	}
+	cfg.LoginThrottle.IPs = cfg.LoginThrottle.Accounts
+	cfg.LoginThrottle.IPs.FreeAttempts = env.GetInt("LOGIN_MAX_ATTEMPTS_PER_IP", 20)
 	cfg.AWS.Bucket = env.GetString("AWS_BUCKET", "bucket")
 
+	cfg.Mail.Driver = env.GetString("MAIL_DRIVER", "log")
+	cfg.Mail.From = env.GetString("MAIL_FROM", "no-reply@localhost")
+	cfg.Mail.Host = env.GetString("MAIL_HOST", "localhost")
+	cfg.Mail.Port = env.GetInt("MAIL_PORT", 587)
+	cfg.Mail.Username = env.GetString("MAIL_USERNAME", "")
+	cfg.Mail.Password = env.GetString("MAIL_PASSWORD", "")
+	cfg.Mail.Dir = env.GetString("MAIL_DIR", "tmp/mail")
+
+	cfg.WebAuthn.RPID = env.GetString("WEBAUTHN_RP_ID", "localhost")
+	cfg.WebAuthn.Origins = splitByComma(env.GetString("WEBAUTHN_RP_ORIGINS_BY_COMMA", cfg.FrontendURL))
+
+	cfg.OIDC = initOIDCConfig(cfg.BaseURL)
+
+	cfg.PasswordResetTTL = env.GetDuration("PASSWORD_RESET_TTL", time.Hour)
+	cfg.EmailVerificationTTL = env.GetDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour)
+	cfg.MagicLinkTTL = env.GetDuration("MAGIC_LINK_TTL", 15*time.Minute)
+	cfg.MagicLinkBindBrowser = env.GetBool("MAGIC_LINK_BIND_BROWSER", true)
+	cfg.RequireVerifiedEmail = env.GetBool("REQUIRE_VERIFIED_EMAIL", false)
+	cfg.ImpersonationTTL = env.GetDuration("IMPERSONATION_TTL", 15*time.Minute)
+
 	return cfg
 }
 
+// initOIDCConfig reads the providers named in OIDC_PROVIDERS_BY_COMMA, each configured with
+// OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID and OIDC_<NAME>_CLIENT_SECRET
+func initOIDCConfig(baseURL string) []oidcHelper.ProviderConfig {
+	providers := []oidcHelper.ProviderConfig{}
+	for _, name := range splitByComma(env.GetString("OIDC_PROVIDERS_BY_COMMA", "")) {
+		name = strings.ToLower(name)
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		providers = append(providers, oidcHelper.ProviderConfig{
+			Name:         name,
+			Issuer:       env.GetString(prefix+"ISSUER", ""),
+			ClientID:     env.GetString(prefix+"CLIENT_ID", ""),
+			ClientSecret: env.GetString(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  env.GetString(prefix+"REDIRECT_URL", strings.TrimSuffix(baseURL, "/")+"/login/oidc/"+name+"/callback"),
+			Scopes:       strings.Fields(env.GetString(prefix+"SCOPES", "openid email profile")),
+		})
+	}
+	return providers
+}
+
+func splitByComma(value string) []string {
+	values := []string{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 func initS3(bucket string) *awsHelper.S3Helper {
 	accessKeyId := env.GetString("AWS_ACCESS_KEY", "secret")
 	accessKeySecret := env.GetString("AWS_SECRET_KEY", "secret")
@@ -102,8 +401,31 @@ func initS3(bucket string) *awsHelper.S3Helper {
 	return awsHelper.New(accessKeyId, accessKeySecret, awsAccountId, bucket)
 }
 
+// Background runs fn after the response has been sent, with a context that keeps the request's values but is not
+// cancelled when the request ends. Work that only happens for some requests, like emailing an account that exists,
+// goes here so how long the response takes doesn't give it away. Errors fn returns are reported.
+func (app *Application) Background(ctx context.Context, fn func(ctx context.Context) error) {
+	ctx = context.WithoutCancel(ctx)
+
+	app.background.Add(1)
+	go func() {
+		defer app.background.Done()
+
+		if err := fn(ctx); err != nil {
+			app.ReportError(err)
+		}
+	}()
+}
+
+// Wait blocks until the work started with Background has finished
+func (app *Application) Wait() {
+	app.background.Wait()
+}
+
 // Add a new Close method to Application
 func (app *Application) Close() {
+	app.stopCleanup()
+	app.Wait()
 	if app.DB != nil {
 		app.DB.Close()
 	}
@@ -0,0 +1,85 @@
+package application_test
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/testHelper"
+)
+
+func TestImpersonationTTLIsCapped(t *testing.T) {
+	tests := map[time.Duration]bool{
+		15 * time.Minute: true,
+		time.Hour:        true,
+		2 * time.Hour:    false,
+		0:                false,
+	}
+
+	for ttl, valid := range tests {
+		t.Run(ttl.String(), func(t *testing.T) {
+			cfg := testHelper.Config(t)
+			cfg.ImpersonationTTL = ttl
+
+			app, err := application.NewWithStores(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg, database.NewMemoryStores())
+			if err == nil {
+				app.Close()
+			}
+			if (err == nil) != valid {
+				t.Errorf("NewWithStores = %v, want valid %v", err, valid)
+			}
+		})
+	}
+}
+
+func TestMailDriversWritingTokensNeedLocalEnv(t *testing.T) {
+	tests := map[string]bool{
+		"log":  false,
+		"file": false,
+		"smtp": true,
+	}
+
+	for driver, allowed := range tests {
+		t.Run(driver, func(t *testing.T) {
+			cfg := testHelper.Config(t)
+			t.Setenv("ENV", "production")
+			cfg.Mail.Driver = driver
+			cfg.Mail.Dir = t.TempDir()
+			cfg.Mail.Host = "localhost"
+
+			app, err := application.NewWithStores(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg, database.NewMemoryStores())
+			if err == nil {
+				app.Close()
+			}
+			if (err == nil) != allowed {
+				t.Errorf("NewWithStores = %v, want allowed %v", err, allowed)
+			}
+		})
+	}
+}
+
+func TestLoginThrottleBackendNames(t *testing.T) {
+	tests := map[string]bool{
+		"memory": true,
+		"mysql":  false,
+		"redis":  false,
+	}
+
+	for backend, valid := range tests {
+		t.Run(backend, func(t *testing.T) {
+			cfg := testHelper.Config(t)
+			cfg.LoginThrottle.Backend = backend
+
+			app, err := application.NewWithStores(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg, database.NewMemoryStores())
+			if err == nil {
+				app.Close()
+			}
+			if (err == nil) != valid {
+				t.Errorf("NewWithStores = %v, want valid %v", err, valid)
+			}
+		})
+	}
+}
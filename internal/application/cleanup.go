@@ -0,0 +1,65 @@
+package application
+
+import (
+	"context"
+	"time"
+)
+
+// cleanupInterval is how often expired rows that nothing else removes are deleted
+const cleanupInterval = time.Hour
+
+// startCleanup deletes expired sessions, revocations, OAuth authorization codes, WebAuthn ceremonies and login
+// failures every cleanupInterval, off the request path, until Close stops it
+func (app *Application) startCleanup() {
+	stop, done := make(chan struct{}), make(chan struct{})
+	app.cleanupStop, app.cleanupDone = stop, done
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(cleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				app.cleanUp(context.Background())
+			}
+		}
+	}()
+}
+
+// cleanUp runs one round of deleting expired rows. Failing is harmless, the rows are removed next time.
+func (app *Application) cleanUp(ctx context.Context) {
+	if err := app.Sessions.DeleteExpired(ctx); err != nil {
+		app.Logger.Warn("Error deleting expired sessions", "error", err)
+	}
+	if err := app.RevokedTokens.DeleteExpired(ctx); err != nil {
+		app.Logger.Warn("Error deleting expired token revocations", "error", err)
+	}
+	if err := app.OauthCodes.DeleteExpired(ctx); err != nil {
+		app.Logger.Warn("Error deleting expired OAuth authorization codes", "error", err)
+	}
+	if err := app.WebAuthnSessions.DeleteExpired(ctx); err != nil {
+		app.Logger.Warn("Error deleting expired WebAuthn sessions", "error", err)
+	}
+	if err := app.AccountThrottle.Prune(ctx); err != nil {
+		app.Logger.Warn("Error deleting forgotten login failures", "error", err)
+	}
+	if err := app.IPThrottle.Prune(ctx); err != nil {
+		app.Logger.Warn("Error deleting forgotten login failures", "error", err)
+	}
+}
+
+// stopCleanup stops the job started by startCleanup, waiting for a round in progress to finish
+func (app *Application) stopCleanup() {
+	if app.cleanupStop == nil {
+		return
+	}
+
+	close(app.cleanupStop)
+	<-app.cleanupDone
+	app.cleanupStop = nil
+}
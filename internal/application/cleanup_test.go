@@ -0,0 +1,47 @@
+package application
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/throttle"
+)
+
+func TestCleanUpDeletesExpiredRows(t *testing.T) {
+	ctx := context.Background()
+	backend := throttle.NewMemoryBackend()
+	policy := throttle.Policy{FreeAttempts: 5, BaseDelay: time.Second, MaxDelay: time.Minute, ResetAfter: time.Hour}
+	app := &Application{
+		Stores:          database.NewMemoryStores(),
+		Logger:          slog.New(slog.NewTextHandler(io.Discard, nil)),
+		AccountThrottle: throttle.NewLimiter(backend, policy),
+		IPThrottle:      throttle.NewLimiter(backend, policy),
+	}
+
+	expired := time.Now().Add(-time.Minute)
+	if err := app.RevokedTokens.Revoke(ctx, "expired", 1, expired); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.RevokedTokens.Revoke(ctx, "live", 1, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backend.Fail(ctx, "forgotten", time.Now().Add(-2*time.Hour), time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	app.cleanUp(ctx)
+
+	if revoked, _ := app.RevokedTokens.IsRevoked(ctx, "expired"); revoked {
+		t.Error("expired revocation not deleted")
+	}
+	if revoked, _ := app.RevokedTokens.IsRevoked(ctx, "live"); !revoked {
+		t.Error("revocation deleted before the token expired")
+	}
+	if failures, _ := backend.Fail(ctx, "forgotten", time.Now(), time.Time{}); failures != 1 {
+		t.Errorf("forgotten key has %d failures after another, want 1", failures)
+	}
+}
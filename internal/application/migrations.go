@@ -0,0 +1,62 @@
+package application
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+
+	"github.com/nathanjms/go-api-template/database/migrations"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/env"
+	"github.com/nathanjms/go-api-template/internal/migrator"
+)
+
+// DatabaseDriver returns which database to use, from DB_DRIVER: mysql, postgres or sqlite
+func DatabaseDriver() string {
+	return env.GetString("DB_DRIVER", string(database.DialectMySQL))
+}
+
+// DatabaseDSN returns the DSN of the database, from DB_DSN
+func DatabaseDSN() string {
+	return env.GetString("DB_DSN", "root:password@tcp(localhost:3306)/api-db")
+}
+
+// NewMigrator returns a migrator for the dialect's migrations embedded in the binary, along with a func that
+// closes its database connection
+func NewMigrator(dialect database.Dialect, dsn string, logger *slog.Logger) (*migrator.Migrator, func(), error) {
+	fsys, err := fs.Sub(migrations.FS, string(dialect))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := database.OpenForMigrations(dialect, dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m, err := migrator.New(db, dialect, fsys, logger)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return m, func() { db.Close() }, nil
+}
+
+// migrateUp applies any pending migrations. Instances starting at the same time wait for each other,
+// so only one of them runs each migration.
+func migrateUp(dialect database.Dialect, dsn string, logger *slog.Logger) error {
+	m, closeDB, err := NewMigrator(dialect, dsn, logger)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	ran, err := m.Up(context.Background())
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Migrations up to date", "applied", len(ran))
+	return nil
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// ApiKey is a long-lived personal key for scripts and integrations. Only the hash of the key is stored,
+// along with its prefix so the user can tell their keys apart.
+type ApiKey struct {
+	ID         int64        `json:"id"`
+	UserID     int64        `json:"-"`
+	Name       string       `json:"name"`
+	Prefix     string       `json:"prefix"`
+	KeyHash    string       `json:"-"`
+	ExpiresAt  sql.NullTime `json:"-"`
+	LastUsedAt sql.NullTime `json:"-"`
+	CreatedAt  time.Time    `json:"createdAt"`
+}
+
+type ApiKeyModel struct {
+	*conn
+}
+
+// apiKeyUseInterval limits how often last_used_at is written, so busy keys don't cause a write per request
+const apiKeyUseInterval = time.Minute
+
+const apiKeyColumns = "id, user_id, name, prefix, key_hash, expires_at, last_used_at, created_at"
+
+func scanApiKey(scanner interface{ Scan(...any) error }) (ApiKey, error) {
+	k := new(ApiKey)
+
+	err := scanner.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.KeyHash, &k.ExpiresAt, &k.LastUsedAt, &k.CreatedAt)
+	if err != nil {
+		return ApiKey{}, err
+	}
+
+	return *k, nil
+}
+
+func (model *ApiKeyModel) Create(ctx context.Context, userID int64, name string, prefix string, keyHash string, expiresAt *time.Time) (int64, error) {
+	expires := sql.NullTime{}
+	if expiresAt != nil {
+		expires = sql.NullTime{Time: expiresAt.UTC(), Valid: true}
+	}
+
+	return model.InsertReturningID(ctx, "INSERT INTO api_keys (user_id, name, prefix, key_hash, expires_at) VALUES (?, ?, ?, ?, ?)", userID, name, prefix, keyHash, expires)
+}
+
+func (model *ApiKeyModel) GetByUserId(ctx context.Context, userID int64) ([]ApiKey, error) {
+	rows, err := model.Query(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE user_id = ? ORDER BY created_at", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	apiKeys := []ApiKey{}
+	for rows.Next() {
+		apiKey, err := scanApiKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	return apiKeys, rows.Err()
+}
+
+// GetValid finds an unexpired key by its hash
+func (model *ApiKeyModel) GetValid(ctx context.Context, keyHash string) (ApiKey, error) {
+	return scanApiKey(model.QueryRow(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE key_hash = ? AND (expires_at IS NULL OR expires_at > ?)", keyHash, time.Now().UTC()))
+}
+
+// RecordUse updates when the key was last used, at most once a minute
+func (model *ApiKeyModel) RecordUse(ctx context.Context, id int64) error {
+	now := time.Now().UTC()
+	_, err := model.Exec(ctx, "UPDATE api_keys SET last_used_at = ? WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?)", now, id, now.Add(-apiKeyUseInterval))
+
+	return err
+}
+
+// Delete revokes one of the user's keys, returning false if the user has no key with that id
+func (model *ApiKeyModel) Delete(ctx context.Context, userID int64, id int64) (bool, error) {
+	result, err := model.Exec(ctx, "DELETE FROM api_keys WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
@@ -1,6 +1,6 @@
 package database
 
-import "github.com/jmoiron/sqlx"
+import "context"
 
 type Feedback struct {
 	ID          int64  `json:"id"`
@@ -12,11 +12,18 @@ type Feedback struct {
 }
 
 type FeedbackModel struct {
-	*sqlx.DB
+	*conn
 }
 
-func (model *FeedbackModel) Save(name string, userID int64, feedbackType string, description string) error {
-	_, err := model.DB.Exec("INSERT INTO feedback (name, user_id, type, description) VALUES (?, ?, ?, ?)", name, userID, feedbackType, description)
+func (model *FeedbackModel) Save(ctx context.Context, name string, userID int64, feedbackType string, description string) error {
+	_, err := model.Exec(ctx, "INSERT INTO feedback (name, user_id, type, description) VALUES (?, ?, ?, ?)", name, userID, feedbackType, description)
+
+	return err
+}
+
+// DeleteForUser deletes the feedback the user sent. Feedback is kept when its user is deleted unless this is called.
+func (model *FeedbackModel) DeleteForUser(ctx context.Context, userID int64) error {
+	_, err := model.Exec(ctx, "DELETE FROM feedback WHERE user_id = ?", userID)
 
 	return err
 }
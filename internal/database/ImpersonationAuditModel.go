@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// Impersonation audit log actions
+const (
+	ImpersonationActionStart   = "start"
+	ImpersonationActionRequest = "request"
+)
+
+// ImpersonationAuditEntry records an admin starting to impersonate a user, or a request made while impersonating.
+// There are no foreign keys, so the trail outlives the accounts involved.
+type ImpersonationAuditEntry struct {
+	ID             int64     `json:"id"`
+	ImpersonatorID int64     `json:"impersonatorId"`
+	UserID         int64     `json:"userId"`
+	TokenID        string    `json:"tokenId"`
+	Action         string    `json:"action"`
+	Method         string    `json:"method"`
+	Path           string    `json:"path"`
+	StatusCode     *int      `json:"statusCode"`
+	IPAddress      string    `json:"ipAddress"`
+	UserAgent      string    `json:"userAgent"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+type ImpersonationAuditModel struct {
+	*conn
+}
+
+const maxAuditPathLength = 1024
+
+// Create records an entry, returning its id so the response status can be filled in later
+func (model *ImpersonationAuditModel) Create(ctx context.Context, entry ImpersonationAuditEntry) (int64, error) {
+	if len(entry.Path) > maxAuditPathLength {
+		entry.Path = entry.Path[:maxAuditPathLength]
+	}
+	if len(entry.UserAgent) > maxUserAgentLength {
+		entry.UserAgent = entry.UserAgent[:maxUserAgentLength]
+	}
+
+	return model.InsertReturningID(ctx,
+		"INSERT INTO impersonation_audit_log (impersonator_id, user_id, token_id, action, method, path, status_code, ip_address, user_agent, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		entry.ImpersonatorID, entry.UserID, entry.TokenID, entry.Action, entry.Method, entry.Path, entry.StatusCode, entry.IPAddress, entry.UserAgent, time.Now().UTC(),
+	)
+}
+
+// SetStatusCode records the status of the response to an audited request
+func (model *ImpersonationAuditModel) SetStatusCode(ctx context.Context, id int64, statusCode int) error {
+	_, err := model.Exec(ctx, "UPDATE impersonation_audit_log SET status_code = ? WHERE id = ?", statusCode, id)
+
+	return err
+}
+
+// List returns a page of the audit log, newest first, optionally only for one impersonated user
+func (model *ImpersonationAuditModel) List(ctx context.Context, userID int64, limit int, offset int) ([]ImpersonationAuditEntry, error) {
+	query := "SELECT id, impersonator_id, user_id, token_id, action, method, path, status_code, ip_address, user_agent, created_at FROM impersonation_audit_log"
+	args := []interface{}{}
+	if userID != 0 {
+		query += " WHERE user_id = ?"
+		args = append(args, userID)
+	}
+	query += " ORDER BY id DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
+
+	rows, err := model.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []ImpersonationAuditEntry{}
+	for rows.Next() {
+		var e ImpersonationAuditEntry
+		if err := rows.Scan(&e.ID, &e.ImpersonatorID, &e.UserID, &e.TokenID, &e.Action, &e.Method, &e.Path, &e.StatusCode, &e.IPAddress, &e.UserAgent, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
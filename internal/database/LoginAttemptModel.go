@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// LoginAttemptModel stores failed login attempts, so throttling and lockouts apply across every instance.
+// It implements throttle.Backend.
+type LoginAttemptModel struct {
+	*conn
+}
+
+func (model *LoginAttemptModel) Fail(ctx context.Context, key string, now time.Time, resetBefore time.Time) (int, error) {
+	_, err := model.Exec(ctx,
+		"INSERT INTO login_attempts (throttle_key, failures, last_failure_at) VALUES (?, 1, ?) "+model.dialect.OnConflictUpdate("throttle_key")+
+			"failures = CASE WHEN login_attempts.last_failure_at < ? THEN 1 ELSE login_attempts.failures + 1 END, last_failure_at = "+model.dialect.Inserted("last_failure_at"),
+		key, now.UTC(), resetBefore.UTC(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var failures int
+	err = model.QueryRow(ctx, "SELECT failures FROM login_attempts WHERE throttle_key = ?", key).Scan(&failures)
+
+	return failures, err
+}
+
+func (model *LoginAttemptModel) Lock(ctx context.Context, key string, until time.Time) error {
+	_, err := model.Exec(ctx, "UPDATE login_attempts SET locked_until = ? WHERE throttle_key = ?", until.UTC(), key)
+
+	return err
+}
+
+func (model *LoginAttemptModel) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	var lockedUntil sql.NullTime
+
+	err := model.QueryRow(ctx, "SELECT locked_until FROM login_attempts WHERE throttle_key = ?", key).Scan(&lockedUntil)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return lockedUntil.Time, nil
+}
+
+func (model *LoginAttemptModel) Reset(ctx context.Context, key string) error {
+	_, err := model.Exec(ctx, "DELETE FROM login_attempts WHERE throttle_key = ?", key)
+
+	return err
+}
+
+// Prune removes attempts that are no longer locked and last failed before the given time
+func (model *LoginAttemptModel) Prune(ctx context.Context, before time.Time) error {
+	_, err := model.Exec(ctx, "DELETE FROM login_attempts WHERE last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)", before.UTC(), time.Now().UTC())
+
+	return err
+}
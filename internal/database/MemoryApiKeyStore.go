@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+)
+
+// MemoryApiKeyStore is an ApiKeyStore that keeps keys in memory
+type MemoryApiKeyStore struct {
+	db *memoryDB
+}
+
+func (s *MemoryApiKeyStore) Create(_ context.Context, userID int64, name string, prefix string, keyHash string, expiresAt *time.Time) (int64, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	expires := sql.NullTime{}
+	if expiresAt != nil {
+		expires = sql.NullTime{Time: expiresAt.UTC(), Valid: true}
+	}
+
+	id := t.newID()
+	t.apiKeys[id] = ApiKey{
+		ID:        id,
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   keyHash,
+		ExpiresAt: expires,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	return id, nil
+}
+
+func (s *MemoryApiKeyStore) GetByUserId(_ context.Context, userID int64) ([]ApiKey, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	apiKeys := []ApiKey{}
+	for _, k := range t.apiKeys {
+		if k.UserID == userID {
+			apiKeys = append(apiKeys, k)
+		}
+	}
+	sort.Slice(apiKeys, func(i, j int) bool {
+		return apiKeys[i].ID < apiKeys[j].ID
+	})
+
+	return apiKeys, nil
+}
+
+// GetValid finds an unexpired key by its hash
+func (s *MemoryApiKeyStore) GetValid(_ context.Context, keyHash string) (ApiKey, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	now := time.Now()
+	for _, k := range t.apiKeys {
+		if k.KeyHash == keyHash && (!k.ExpiresAt.Valid || k.ExpiresAt.Time.After(now)) {
+			return k, nil
+		}
+	}
+
+	return ApiKey{}, sql.ErrNoRows
+}
+
+// RecordUse updates when the key was last used, at most once a minute
+func (s *MemoryApiKeyStore) RecordUse(_ context.Context, id int64) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	now := time.Now().UTC()
+	if k, ok := t.apiKeys[id]; ok && (!k.LastUsedAt.Valid || k.LastUsedAt.Time.Before(now.Add(-apiKeyUseInterval))) {
+		k.LastUsedAt = sql.NullTime{Time: now, Valid: true}
+		t.apiKeys[id] = k
+	}
+
+	return nil
+}
+
+// Delete revokes one of the user's keys, returning false if the user has no key with that id
+func (s *MemoryApiKeyStore) Delete(_ context.Context, userID int64, id int64) (bool, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	if k, ok := t.apiKeys[id]; !ok || k.UserID != userID {
+		return false, nil
+	}
+	delete(t.apiKeys, id)
+
+	return true, nil
+}
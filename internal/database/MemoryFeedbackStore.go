@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// MemoryFeedbackStore is a FeedbackStore that keeps feedback in memory
+type MemoryFeedbackStore struct {
+	db *memoryDB
+}
+
+func (s *MemoryFeedbackStore) Save(_ context.Context, name string, userID int64, feedbackType string, description string) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	t.feedback = append(t.feedback, Feedback{
+		ID:          t.newID(),
+		Name:        name,
+		UserID:      userID,
+		Type:        feedbackType,
+		Description: description,
+		CreatedAt:   time.Now().UTC().Format(time.RFC3339),
+	})
+
+	return nil
+}
+
+func (s *MemoryFeedbackStore) DeleteForUser(_ context.Context, userID int64) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	t.feedback = slices.DeleteFunc(t.feedback, func(f Feedback) bool { return f.UserID == userID })
+
+	return nil
+}
+
+// All returns the feedback saved so far, oldest first
+func (s *MemoryFeedbackStore) All() []Feedback {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	return append([]Feedback{}, t.feedback...)
+}
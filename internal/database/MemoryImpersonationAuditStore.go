@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// MemoryImpersonationAuditStore is an ImpersonationAuditStore that keeps the audit log in memory
+type MemoryImpersonationAuditStore struct {
+	db *memoryDB
+}
+
+// Create records an entry, returning its id so the response status can be filled in later
+func (s *MemoryImpersonationAuditStore) Create(_ context.Context, entry ImpersonationAuditEntry) (int64, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	if len(entry.Path) > maxAuditPathLength {
+		entry.Path = entry.Path[:maxAuditPathLength]
+	}
+	if len(entry.UserAgent) > maxUserAgentLength {
+		entry.UserAgent = entry.UserAgent[:maxUserAgentLength]
+	}
+
+	entry.ID = t.newID()
+	entry.CreatedAt = time.Now().UTC()
+	t.impersonationAudit = append(t.impersonationAudit, entry)
+
+	return entry.ID, nil
+}
+
+// SetStatusCode records the status of the response to an audited request
+func (s *MemoryImpersonationAuditStore) SetStatusCode(_ context.Context, id int64, statusCode int) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	for i := range t.impersonationAudit {
+		if t.impersonationAudit[i].ID == id {
+			// A new pointer, so a copy of the log taken for a transaction keeps the old status
+			t.impersonationAudit[i].StatusCode = &statusCode
+		}
+	}
+
+	return nil
+}
+
+// List returns a page of the audit log, newest first, optionally only for one impersonated user
+func (s *MemoryImpersonationAuditStore) List(_ context.Context, userID int64, limit int, offset int) ([]ImpersonationAuditEntry, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	entries := []ImpersonationAuditEntry{}
+	for i := len(t.impersonationAudit) - 1; i >= 0; i-- {
+		if e := t.impersonationAudit[i]; userID == 0 || e.UserID == userID {
+			entries = append(entries, e)
+		}
+	}
+
+	if offset >= len(entries) {
+		return []ImpersonationAuditEntry{}, nil
+	}
+
+	return entries[offset:min(offset+limit, len(entries))], nil
+}
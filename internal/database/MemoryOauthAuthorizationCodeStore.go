@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+)
+
+// MemoryOauthAuthorizationCodeStore is an OauthAuthorizationCodeStore that keeps codes in memory, by their hash
+type MemoryOauthAuthorizationCodeStore struct {
+	db *memoryDB
+}
+
+type oauthCode struct {
+	OauthAuthorizationCode
+	used bool
+}
+
+func (s *MemoryOauthAuthorizationCodeStore) Create(_ context.Context, code OauthAuthorizationCode) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	code.Scopes = slices.Clone(code.Scopes)
+	code.ExpiresAt = code.ExpiresAt.UTC()
+	t.oauthCodes[code.CodeHash] = oauthCode{OauthAuthorizationCode: code}
+
+	return nil
+}
+
+// Redeem uses up an unexpired code, returning sql.ErrNoRows if it doesn't exist, has expired or was already used
+func (s *MemoryOauthAuthorizationCodeStore) Redeem(_ context.Context, codeHash string) (OauthAuthorizationCode, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	code, ok := t.oauthCodes[codeHash]
+	if !ok || code.used || !code.ExpiresAt.After(time.Now()) {
+		return OauthAuthorizationCode{}, sql.ErrNoRows
+	}
+
+	code.used = true
+	t.oauthCodes[codeHash] = code
+
+	return code.OauthAuthorizationCode, nil
+}
+
+func (s *MemoryOauthAuthorizationCodeStore) DeleteExpired(_ context.Context) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	now := time.Now()
+	for codeHash, code := range t.oauthCodes {
+		if code.ExpiresAt.Before(now) {
+			delete(t.oauthCodes, codeHash)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"sort"
+	"time"
+)
+
+// MemoryOauthClientStore is an OauthClientStore that keeps clients in memory
+type MemoryOauthClientStore struct {
+	db *memoryDB
+}
+
+// Create registers a client. secretHash is empty for public clients.
+func (s *MemoryOauthClientStore) Create(_ context.Context, id string, name string, secretHash string, redirectURIs []string, scopes []string, grantTypes []string, createdBy int64) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	if _, ok := t.oauthClients[id]; ok {
+		return errors.New("client already exists")
+	}
+
+	t.oauthClients[id] = OauthClient{
+		ID:           id,
+		Name:         name,
+		SecretHash:   sql.NullString{String: secretHash, Valid: secretHash != ""},
+		RedirectURIs: slices.Clone(redirectURIs),
+		Scopes:       slices.Clone(scopes),
+		GrantTypes:   slices.Clone(grantTypes),
+		CreatedBy:    sql.NullInt64{Int64: createdBy, Valid: true},
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	return nil
+}
+
+func (s *MemoryOauthClientStore) List(_ context.Context) ([]OauthClient, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	clients := []OauthClient{}
+	for _, c := range t.oauthClients {
+		clients = append(clients, c)
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].CreatedAt.Before(clients[j].CreatedAt)
+	})
+
+	return clients, nil
+}
+
+// GetActive finds a client that has not been revoked
+func (s *MemoryOauthClientStore) GetActive(_ context.Context, id string) (OauthClient, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	c, ok := t.oauthClients[id]
+	if !ok || c.RevokedAt != nil {
+		return OauthClient{}, sql.ErrNoRows
+	}
+
+	return c, nil
+}
+
+// Revoke stops the client being used, returning false if there is no active client with that id
+func (s *MemoryOauthClientStore) Revoke(_ context.Context, id string) (bool, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	c, ok := t.oauthClients[id]
+	if !ok || c.RevokedAt != nil {
+		return false, nil
+	}
+
+	now := time.Now().UTC()
+	c.RevokedAt = &now
+	t.oauthClients[id] = c
+
+	return true, nil
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+)
+
+// MemoryOauthConsentStore is an OauthConsentStore that keeps consents in memory
+type MemoryOauthConsentStore struct {
+	db *memoryDB
+}
+
+type oauthConsentKey struct {
+	userID   int64
+	clientID string
+}
+
+// GetScopes returns the scopes the user has allowed the client, which is empty if they haven't
+// consented or the client has been revoked
+func (s *MemoryOauthConsentStore) GetScopes(_ context.Context, userID int64, clientID string) ([]string, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	consent, ok := t.oauthConsents[oauthConsentKey{userID, clientID}]
+	if !ok || !t.oauthClientActive(clientID) {
+		return []string{}, nil
+	}
+
+	return slices.Clone(consent.Scopes), nil
+}
+
+// Save records the user's consent to the scopes, replacing what they consented to before
+func (s *MemoryOauthConsentStore) Save(_ context.Context, userID int64, clientID string, scopes []string) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	now := time.Now().UTC()
+	key := oauthConsentKey{userID, clientID}
+	consent, ok := t.oauthConsents[key]
+	if !ok {
+		consent = OauthConsent{UserID: userID, ClientID: clientID, CreatedAt: now}
+	}
+	consent.Scopes = slices.Clone(scopes)
+	consent.UpdatedAt = now
+	t.oauthConsents[key] = consent
+
+	return nil
+}
+
+// GetByUserId returns the active clients the user has authorized
+func (s *MemoryOauthConsentStore) GetByUserId(_ context.Context, userID int64) ([]OauthConsent, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	consents := []OauthConsent{}
+	for key, consent := range t.oauthConsents {
+		if key.userID == userID && t.oauthClientActive(key.clientID) {
+			consent.ClientName = t.oauthClients[key.clientID].Name
+			consents = append(consents, consent)
+		}
+	}
+	sort.Slice(consents, func(i, j int) bool {
+		return consents[i].CreatedAt.Before(consents[j].CreatedAt)
+	})
+
+	return consents, nil
+}
+
+// Delete withdraws the user's consent, returning false if they hadn't given any
+func (s *MemoryOauthConsentStore) Delete(_ context.Context, userID int64, clientID string) (bool, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	key := oauthConsentKey{userID, clientID}
+	if _, ok := t.oauthConsents[key]; !ok {
+		return false, nil
+	}
+	delete(t.oauthConsents, key)
+
+	return true, nil
+}
+
+func (t *memoryTables) oauthClientActive(id string) bool {
+	c, ok := t.oauthClients[id]
+
+	return ok && c.RevokedAt == nil
+}
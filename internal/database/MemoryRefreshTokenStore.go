@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// MemoryRefreshTokenStore is a RefreshTokenStore that keeps refresh tokens in memory
+type MemoryRefreshTokenStore struct {
+	db *memoryDB
+}
+
+func (s *MemoryRefreshTokenStore) Create(_ context.Context, userID int64, familyID string, tokenHash string, rememberMe bool, expiresAt time.Time) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	for _, r := range t.refreshTokens {
+		if r.TokenHash == tokenHash {
+			return errors.New("refresh token already exists")
+		}
+	}
+
+	id := t.newID()
+	t.refreshTokens[id] = RefreshToken{
+		ID:         id,
+		UserID:     userID,
+		FamilyID:   familyID,
+		TokenHash:  tokenHash,
+		RememberMe: rememberMe,
+		ExpiresAt:  expiresAt.UTC(),
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	return nil
+}
+
+func (s *MemoryRefreshTokenStore) GetByHash(_ context.Context, tokenHash string) (RefreshToken, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	for _, r := range t.refreshTokens {
+		if r.TokenHash == tokenHash {
+			return r, nil
+		}
+	}
+
+	return RefreshToken{}, sql.ErrNoRows
+}
+
+// MarkUsed flags the refresh token as used, returning false if it had already been used or revoked
+func (s *MemoryRefreshTokenStore) MarkUsed(_ context.Context, id int64) (bool, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	r, ok := t.refreshTokens[id]
+	if !ok || r.UsedAt.Valid || r.RevokedAt.Valid {
+		return false, nil
+	}
+
+	r.UsedAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+	t.refreshTokens[id] = r
+
+	return true, nil
+}
+
+func (s *MemoryRefreshTokenStore) RevokeFamily(_ context.Context, familyID string) error {
+	s.revokeWhere(func(r RefreshToken) bool { return r.FamilyID == familyID })
+
+	return nil
+}
+
+func (s *MemoryRefreshTokenStore) RevokeAllForUser(_ context.Context, userID int64) error {
+	s.revokeWhere(func(r RefreshToken) bool { return r.UserID == userID })
+
+	return nil
+}
+
+func (s *MemoryRefreshTokenStore) RevokeAllForUserExcept(_ context.Context, userID int64, familyID string) error {
+	s.revokeWhere(func(r RefreshToken) bool { return r.UserID == userID && r.FamilyID != familyID })
+
+	return nil
+}
+
+func (s *MemoryRefreshTokenStore) revokeWhere(match func(r RefreshToken) bool) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	now := sql.NullTime{Time: time.Now().UTC(), Valid: true}
+	for id, r := range t.refreshTokens {
+		if match(r) && !r.RevokedAt.Valid {
+			r.RevokedAt = now
+			t.refreshTokens[id] = r
+		}
+	}
+}
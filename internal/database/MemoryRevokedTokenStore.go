@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// MemoryRevokedTokenStore is a RevokedTokenStore that keeps revocations in memory
+type MemoryRevokedTokenStore struct {
+	db *memoryDB
+}
+
+type revokedToken struct {
+	userID    int64
+	expiresAt time.Time
+}
+
+func (s *MemoryRevokedTokenStore) Revoke(_ context.Context, jti string, userID int64, expiresAt time.Time) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	if _, ok := t.revokedTokens[jti]; !ok {
+		t.revokedTokens[jti] = revokedToken{userID: userID, expiresAt: expiresAt.UTC()}
+	}
+
+	return nil
+}
+
+func (s *MemoryRevokedTokenStore) IsRevoked(_ context.Context, jti string) (bool, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	_, ok := t.revokedTokens[jti]
+
+	return ok, nil
+}
+
+func (s *MemoryRevokedTokenStore) RevokeAllForUser(_ context.Context, userID int64, before time.Time) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	t.revokedBefore[userID] = before.UTC()
+
+	return nil
+}
+
+func (s *MemoryRevokedTokenStore) GetRevokedBefore(_ context.Context, userID int64) (time.Time, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	return t.revokedBefore[userID], nil
+}
+
+func (s *MemoryRevokedTokenStore) DeleteExpired(_ context.Context) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	now := time.Now()
+	for jti, r := range t.revokedTokens {
+		if r.expiresAt.Before(now) {
+			delete(t.revokedTokens, jti)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"sort"
+)
+
+// MemoryRoleStore is a RoleStore that keeps roles in memory, starting with those the migrations seed
+type MemoryRoleStore struct {
+	db *memoryDB
+}
+
+func (s *MemoryRoleStore) List(_ context.Context) ([]Role, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	roles := []Role{}
+	for _, role := range t.roles {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+
+	return roles, nil
+}
+
+// GetRoleNamesForUser returns the names of the roles assigned to the user
+func (s *MemoryRoleStore) GetRoleNamesForUser(_ context.Context, userID int64) ([]string, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	names := []string{}
+	for roleID := range t.userRoles[userID] {
+		names = append(names, t.roles[roleID].Name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// GetPermissionNamesForUser returns the names of every permission granted to the user by any of their roles
+func (s *MemoryRoleStore) GetPermissionNamesForUser(_ context.Context, userID int64) ([]string, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	seen := map[string]bool{}
+	names := []string{}
+	for roleID := range t.userRoles[userID] {
+		for _, name := range t.rolePermissions[roleID] {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names, nil
+}
+
+// AssignToUser gives the user the named role, returning false if there is no such role
+func (s *MemoryRoleStore) AssignToUser(_ context.Context, userID int64, roleName string) (bool, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	roleID, ok := t.roleID(roleName)
+	if !ok {
+		return false, nil
+	}
+
+	if _, ok := t.users[userID]; ok {
+		if t.userRoles[userID] == nil {
+			t.userRoles[userID] = map[int64]bool{}
+		}
+		t.userRoles[userID][roleID] = true
+	}
+
+	return true, nil
+}
+
+// RemoveFromUser takes the named role away from the user, returning false if they did not have it
+func (s *MemoryRoleStore) RemoveFromUser(_ context.Context, userID int64, roleName string) (bool, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	roleID, ok := t.roleID(roleName)
+	if !ok || !t.userRoles[userID][roleID] {
+		return false, nil
+	}
+	delete(t.userRoles[userID], roleID)
+
+	return true, nil
+}
+
+func (t *memoryTables) roleID(name string) (int64, bool) {
+	for id, role := range t.roles {
+		if role.Name == name {
+			return id, true
+		}
+	}
+
+	return 0, false
+}
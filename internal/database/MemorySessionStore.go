@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+)
+
+// MemorySessionStore is a SessionStore that keeps sessions in memory
+type MemorySessionStore struct {
+	db *memoryDB
+}
+
+func (s *MemorySessionStore) Create(_ context.Context, id string, userID int64, userAgent string, ipAddress string, expiresAt time.Time) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	if _, ok := t.sessions[id]; ok {
+		return errors.New("session already exists")
+	}
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	now := time.Now().UTC()
+	t.sessions[id] = Session{
+		ID:         id,
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		ExpiresAt:  expiresAt.UTC(),
+		LastSeenAt: now,
+		CreatedAt:  now,
+	}
+
+	return nil
+}
+
+// GetActiveByUserId returns the user's sessions that have not been revoked or expired, most recently used first
+func (s *MemorySessionStore) GetActiveByUserId(_ context.Context, userID int64) ([]Session, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	now := time.Now()
+	sessions := []Session{}
+	for _, session := range t.sessions {
+		if session.UserID == userID && !session.RevokedAt.Valid && session.ExpiresAt.After(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+
+	return sessions, nil
+}
+
+// Extend records the session being refreshed, returning false if there is no such session
+func (s *MemorySessionStore) Extend(_ context.Context, id string, ipAddress string, expiresAt time.Time) (bool, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	session, ok := t.sessions[id]
+	if !ok {
+		return false, nil
+	}
+
+	session.IPAddress = ipAddress
+	session.ExpiresAt = expiresAt.UTC()
+	session.LastSeenAt = time.Now().UTC()
+	t.sessions[id] = session
+
+	return true, nil
+}
+
+// Touch records the session being used
+func (s *MemorySessionStore) Touch(_ context.Context, id string) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	if session, ok := t.sessions[id]; ok {
+		session.LastSeenAt = time.Now().UTC()
+		t.sessions[id] = session
+	}
+
+	return nil
+}
+
+// IsRevoked reports whether the session has been revoked. A session that no longer exists counts as revoked.
+func (s *MemorySessionStore) IsRevoked(_ context.Context, id string) (bool, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	session, ok := t.sessions[id]
+
+	return !ok || session.RevokedAt.Valid, nil
+}
+
+// Revoke revokes one of the user's sessions, returning false if the user has no active session with that id
+func (s *MemorySessionStore) Revoke(_ context.Context, userID int64, id string) (bool, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	session, ok := t.sessions[id]
+	if !ok || session.UserID != userID || session.RevokedAt.Valid {
+		return false, nil
+	}
+
+	session.RevokedAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+	t.sessions[id] = session
+
+	return true, nil
+}
+
+// RevokeAllForUser revokes every one of the user's sessions apart from exceptID, which may be empty
+func (s *MemorySessionStore) RevokeAllForUser(_ context.Context, userID int64, exceptID string) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	now := sql.NullTime{Time: time.Now().UTC(), Valid: true}
+	for id, session := range t.sessions {
+		if session.UserID == userID && id != exceptID && !session.RevokedAt.Valid {
+			session.RevokedAt = now
+			t.sessions[id] = session
+		}
+	}
+
+	return nil
+}
+
+// DeleteExpired removes sessions that can no longer be refreshed
+func (s *MemorySessionStore) DeleteExpired(_ context.Context) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	now := time.Now()
+	for id, session := range t.sessions {
+		if session.ExpiresAt.Before(now) {
+			delete(t.sessions, id)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// MemoryTwoFactorStore is a TwoFactorStore that keeps TOTP enrolments and recovery codes in memory
+type MemoryTwoFactorStore struct {
+	db *memoryDB
+}
+
+func (s *MemoryTwoFactorStore) Get(_ context.Context, userID int64) (TwoFactor, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	if _, ok := t.users[userID]; !ok {
+		return TwoFactor{}, sql.ErrNoRows
+	}
+
+	tf := t.twoFactor[userID]
+	tf.UserID = userID
+
+	return tf, nil
+}
+
+// SetPendingSecret stores a new secret that only takes effect once Enable is called
+func (s *MemoryTwoFactorStore) SetPendingSecret(_ context.Context, userID int64, secret string) error {
+	return s.update(userID, func(tf *TwoFactor) {
+		if !tf.EnabledAt.Valid {
+			*tf = TwoFactor{UserID: userID, Secret: sql.NullString{String: secret, Valid: true}}
+		}
+	})
+}
+
+func (s *MemoryTwoFactorStore) Enable(_ context.Context, userID int64, counter int64) error {
+	return s.update(userID, func(tf *TwoFactor) {
+		if tf.Secret.Valid {
+			tf.EnabledAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+			tf.LastCounter = sql.NullInt64{Int64: counter, Valid: true}
+		}
+	})
+}
+
+func (s *MemoryTwoFactorStore) Disable(_ context.Context, userID int64) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	delete(t.twoFactor, userID)
+	delete(t.recoveryCodes, userID)
+
+	return nil
+}
+
+// UseCounter records the time step of an accepted code, returning false if that code (or a later one) was already used
+func (s *MemoryTwoFactorStore) UseCounter(_ context.Context, userID int64, counter int64) (bool, error) {
+	used := false
+	err := s.update(userID, func(tf *TwoFactor) {
+		if !tf.LastCounter.Valid || tf.LastCounter.Int64 < counter {
+			tf.LastCounter = sql.NullInt64{Int64: counter, Valid: true}
+			used = true
+		}
+	})
+
+	return used, err
+}
+
+// ReplaceRecoveryCodes swaps the user's recovery codes for a new set
+func (s *MemoryTwoFactorStore) ReplaceRecoveryCodes(_ context.Context, userID int64, codeHashes []string) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	codes := map[string]bool{}
+	for _, codeHash := range codeHashes {
+		codes[codeHash] = false
+	}
+	t.recoveryCodes[userID] = codes
+
+	return nil
+}
+
+// UseRecoveryCode consumes one of the user's recovery codes, returning false if it does not exist or was already used
+func (s *MemoryTwoFactorStore) UseRecoveryCode(_ context.Context, userID int64, codeHash string) (bool, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	used, ok := t.recoveryCodes[userID][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	t.recoveryCodes[userID][codeHash] = true
+
+	return true, nil
+}
+
+// update changes the user's enrolment if the user exists
+func (s *MemoryTwoFactorStore) update(userID int64, change func(tf *TwoFactor)) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	if _, ok := t.users[userID]; !ok {
+		return nil
+	}
+
+	tf := t.twoFactor[userID]
+	tf.UserID = userID
+	change(&tf)
+	t.twoFactor[userID] = tf
+
+	return nil
+}
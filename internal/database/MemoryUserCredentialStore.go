@@ -0,0 +1,92 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+)
+
+// MemoryUserCredentialStore is a UserCredentialStore that keeps passkeys in memory
+type MemoryUserCredentialStore struct {
+	db *memoryDB
+}
+
+func (s *MemoryUserCredentialStore) Create(_ context.Context, credential UserCredential) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	for _, c := range t.credentials {
+		if bytes.Equal(c.CredentialID, credential.CredentialID) {
+			return errors.New("credential already exists")
+		}
+	}
+
+	credential.ID = t.newID()
+	credential.LastUsedAt = sql.NullTime{}
+	credential.CreatedAt = time.Now().UTC()
+	t.credentials[credential.ID] = credential
+
+	return nil
+}
+
+func (s *MemoryUserCredentialStore) GetByUserId(_ context.Context, userID int64) ([]UserCredential, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	credentials := []UserCredential{}
+	for _, c := range t.credentials {
+		if c.UserID == userID {
+			credentials = append(credentials, c)
+		}
+	}
+	// Ids increase with creation time, and are unique where times may not be
+	sort.Slice(credentials, func(i, j int) bool {
+		return credentials[i].ID < credentials[j].ID
+	})
+
+	return credentials, nil
+}
+
+func (s *MemoryUserCredentialStore) GetByCredentialId(_ context.Context, credentialID []byte) (UserCredential, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	for _, c := range t.credentials {
+		if bytes.Equal(c.CredentialID, credentialID) {
+			return c, nil
+		}
+	}
+
+	return UserCredential{}, sql.ErrNoRows
+}
+
+// RecordUse stores the authenticator's new signature counter and flags after a successful login
+func (s *MemoryUserCredentialStore) RecordUse(_ context.Context, id int64, signCount uint32, flags uint8) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	if c, ok := t.credentials[id]; ok {
+		c.SignCount = signCount
+		c.Flags = flags
+		c.LastUsedAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+		t.credentials[id] = c
+	}
+
+	return nil
+}
+
+// Delete removes one of the user's credentials, returning false if the user has no credential with that id
+func (s *MemoryUserCredentialStore) Delete(_ context.Context, userID int64, id int64) (bool, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	if c, ok := t.credentials[id]; !ok || c.UserID != userID {
+		return false, nil
+	}
+	delete(t.credentials, id)
+
+	return true, nil
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// MemoryUserIdentityStore is a UserIdentityStore that keeps identities in memory
+type MemoryUserIdentityStore struct {
+	db *memoryDB
+}
+
+func (s *MemoryUserIdentityStore) GetByProviderSubject(_ context.Context, provider string, subject string) (UserIdentity, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	for _, i := range t.identities {
+		if i.Provider == provider && i.Subject == subject {
+			return i, nil
+		}
+	}
+
+	return UserIdentity{}, sql.ErrNoRows
+}
+
+func (s *MemoryUserIdentityStore) Create(_ context.Context, userID int64, provider string, subject string, email string) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	for _, i := range t.identities {
+		if i.Provider == provider && i.Subject == subject {
+			return errors.New("identity already linked")
+		}
+	}
+
+	now := time.Now().UTC()
+	id := t.newID()
+	t.identities[id] = UserIdentity{
+		ID:          id,
+		UserID:      userID,
+		Provider:    provider,
+		Subject:     subject,
+		Email:       email,
+		LastLoginAt: sql.NullTime{Time: now, Valid: true},
+		CreatedAt:   now,
+	}
+
+	return nil
+}
+
+// RecordLogin keeps the identity's email up to date with the provider and records when it was last used
+func (s *MemoryUserIdentityStore) RecordLogin(_ context.Context, id int64, email string) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	if i, ok := t.identities[id]; ok {
+		i.Email = email
+		i.LastLoginAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+		t.identities[id] = i
+	}
+
+	return nil
+}
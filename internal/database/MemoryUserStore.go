@@ -0,0 +1,160 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+var errUsernameTaken = errors.New("username already exists")
+
+// MemoryUserStore is a UserStore that keeps users in memory. Like the database it treats usernames as
+// case-insensitive.
+type MemoryUserStore struct {
+	db *memoryDB
+}
+
+func (s *MemoryUserStore) FindUser(_ context.Context, id int64) (User, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	u, ok := t.users[id]
+	if !ok {
+		return User{}, sql.ErrNoRows
+	}
+
+	return t.withTwoFactor(u), nil
+}
+
+func (s *MemoryUserStore) GetByUsername(_ context.Context, username string) (User, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	for _, u := range t.users {
+		if strings.EqualFold(u.Username, username) {
+			return t.withTwoFactor(u), nil
+		}
+	}
+
+	return User{}, sql.ErrNoRows
+}
+
+// List returns a page of users, ordered by id
+func (s *MemoryUserStore) List(_ context.Context, limit int, offset int) ([]User, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	users := make([]User, 0, len(t.users))
+	for _, u := range t.users {
+		users = append(users, t.withTwoFactor(u))
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+
+	if offset >= len(users) {
+		return []User{}, nil
+	}
+
+	return users[offset:min(offset+limit, len(users))], nil
+}
+
+func (s *MemoryUserStore) Count(_ context.Context) (int, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	return len(t.users), nil
+}
+
+func (s *MemoryUserStore) Create(_ context.Context, username string, passwordHash string) (int64, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	if t.usernameTaken(username, 0) {
+		return 0, errUsernameTaken
+	}
+
+	id := t.newID()
+	t.users[id] = User{ID: id, Username: username, Password: passwordHash}
+
+	return id, nil
+}
+
+func (s *MemoryUserStore) UpdatePassword(_ context.Context, id int64, passwordHash string) error {
+	return s.update(id, func(u *User) {
+		u.Password = passwordHash
+	})
+}
+
+func (s *MemoryUserStore) UpdateEmail(_ context.Context, id int64, email string) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	if t.usernameTaken(email, id) {
+		return errUsernameTaken
+	}
+
+	if u, ok := t.users[id]; ok {
+		now := time.Now().UTC()
+		u.Username = email
+		u.EmailVerifiedAt = &now
+		t.users[id] = u
+	}
+
+	return nil
+}
+
+func (s *MemoryUserStore) MarkEmailVerified(_ context.Context, id int64) error {
+	return s.update(id, func(u *User) {
+		if u.EmailVerifiedAt == nil {
+			now := time.Now().UTC()
+			u.EmailVerifiedAt = &now
+		}
+	})
+}
+
+func (s *MemoryUserStore) Delete(_ context.Context, id int64) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	t.deleteUser(id)
+
+	return nil
+}
+
+// update changes a user if they exist. Like an UPDATE matching no rows, a missing user is not an error.
+func (s *MemoryUserStore) update(id int64, change func(u *User)) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	if u, ok := t.users[id]; ok {
+		change(&u)
+		t.users[id] = u
+	}
+
+	return nil
+}
+
+// usernameTaken reports whether a user other than exceptID has the username
+func (t *memoryTables) usernameTaken(username string, exceptID int64) bool {
+	for _, u := range t.users {
+		if u.ID != exceptID && strings.EqualFold(u.Username, username) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// withTwoFactor fills in when the user enabled two-factor authentication, which the TwoFactorStore keeps track of
+func (t *memoryTables) withTwoFactor(u User) User {
+	if tf, ok := t.twoFactor[u.ID]; ok && tf.EnabledAt.Valid {
+		enabledAt := tf.EnabledAt.Time
+		u.TOTPEnabledAt = &enabledAt
+	}
+
+	return u
+}
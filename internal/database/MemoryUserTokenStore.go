@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// MemoryUserTokenStore is a UserTokenStore that keeps tokens in memory
+type MemoryUserTokenStore struct {
+	db *memoryDB
+}
+
+func (s *MemoryUserTokenStore) Create(_ context.Context, userID int64, purpose string, tokenHash string, payload string, expiresAt time.Time) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	id := t.newID()
+	t.userTokens[id] = UserToken{
+		ID:        id,
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: tokenHash,
+		Payload:   sql.NullString{String: payload, Valid: payload != ""},
+		ExpiresAt: expiresAt.UTC(),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	return nil
+}
+
+// GetValid finds an unused, unexpired token issued for the given purpose
+func (s *MemoryUserTokenStore) GetValid(_ context.Context, purpose string, tokenHash string) (UserToken, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	now := time.Now()
+	for _, token := range t.userTokens {
+		if token.Purpose == purpose && token.TokenHash == tokenHash && !token.UsedAt.Valid && token.ExpiresAt.After(now) {
+			return token, nil
+		}
+	}
+
+	return UserToken{}, sql.ErrNoRows
+}
+
+// MarkUsed consumes the token, returning false if it had already been used
+func (s *MemoryUserTokenStore) MarkUsed(_ context.Context, id int64) (bool, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	token, ok := t.userTokens[id]
+	if !ok || token.UsedAt.Valid {
+		return false, nil
+	}
+
+	token.UsedAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+	t.userTokens[id] = token
+
+	return true, nil
+}
+
+func (s *MemoryUserTokenStore) InvalidateForUser(_ context.Context, userID int64, purpose string) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	now := sql.NullTime{Time: time.Now().UTC(), Valid: true}
+	for id, token := range t.userTokens {
+		if token.UserID == userID && token.Purpose == purpose && !token.UsedAt.Valid {
+			token.UsedAt = now
+			t.userTokens[id] = token
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryUserTokenStore) CountCreatedSince(_ context.Context, userID int64, purpose string, since time.Time) (int, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	count := 0
+	for _, token := range t.userTokens {
+		if token.UserID == userID && token.Purpose == purpose && !token.CreatedAt.Before(since) {
+			count++
+		}
+	}
+
+	return count, nil
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// MemoryWebAuthnSessionStore is a WebAuthnSessionStore that keeps challenges in memory, by the hash of their token
+type MemoryWebAuthnSessionStore struct {
+	db *memoryDB
+}
+
+func (s *MemoryWebAuthnSessionStore) Create(_ context.Context, tokenHash string, userID int64, ceremony string, data string, expiresAt time.Time) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	t.webAuthnSessions[tokenHash] = WebAuthnSession{
+		ID:        t.newID(),
+		UserID:    sql.NullInt64{Int64: userID, Valid: userID != 0},
+		Ceremony:  ceremony,
+		Data:      data,
+		ExpiresAt: expiresAt.UTC(),
+	}
+
+	return nil
+}
+
+// Consume fetches and deletes an unexpired session, so each challenge can only be answered once
+func (s *MemoryWebAuthnSessionStore) Consume(_ context.Context, tokenHash string, ceremony string) (WebAuthnSession, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	session, ok := t.webAuthnSessions[tokenHash]
+	if !ok || session.Ceremony != ceremony || !session.ExpiresAt.After(time.Now()) {
+		return WebAuthnSession{}, sql.ErrNoRows
+	}
+	delete(t.webAuthnSessions, tokenHash)
+
+	return session, nil
+}
+
+func (s *MemoryWebAuthnSessionStore) DeleteExpired(_ context.Context) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	now := time.Now()
+	for tokenHash, session := range t.webAuthnSessions {
+		if session.ExpiresAt.Before(now) {
+			delete(t.webAuthnSessions, tokenHash)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// MemoryWorkoutBackupStore is a WorkoutBackupStore that keeps backups in memory
+type MemoryWorkoutBackupStore struct {
+	db *memoryDB
+}
+
+func (s *MemoryWorkoutBackupStore) GetByUserId(_ context.Context, userID int64) (UserWorkoutBackup, error) {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	b, ok := t.workoutBackups[userID]
+	if !ok {
+		return UserWorkoutBackup{}, sql.ErrNoRows
+	}
+
+	return b, nil
+}
+
+// CreateWorkoutBackup records the user's backup. Each user has at most one, as with the table's primary key.
+func (s *MemoryWorkoutBackupStore) CreateWorkoutBackup(_ context.Context, userID int64, backupPath string) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	if _, ok := t.workoutBackups[userID]; ok {
+		return errors.New("user already has a workout backup")
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	t.workoutBackups[userID] = UserWorkoutBackup{UserID: userID, BackupPath: backupPath, CreatedAt: now, UpdatedAt: now}
+
+	return nil
+}
+
+func (s *MemoryWorkoutBackupStore) TouchWorkoutBackup(_ context.Context, uwb *UserWorkoutBackup) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	if b, ok := t.workoutBackups[uwb.UserID]; ok && b.BackupPath == uwb.BackupPath {
+		b.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		t.workoutBackups[uwb.UserID] = b
+	}
+
+	return nil
+}
+
+func (s *MemoryWorkoutBackupStore) DeleteForUser(_ context.Context, userID int64) error {
+	t, unlock := s.db.lock()
+	defer unlock()
+
+	delete(t.workoutBackups, userID)
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// OauthAuthorizationCode is a hashed, single-use code from the authorization code flow, bound to its client,
+// redirect URI and PKCE challenge
+type OauthAuthorizationCode struct {
+	CodeHash      string
+	ClientID      string
+	UserID        int64
+	RedirectURI   string
+	Scopes        []string
+	CodeChallenge string
+	ExpiresAt     time.Time
+}
+
+type OauthAuthorizationCodeModel struct {
+	*conn
+}
+
+func (model *OauthAuthorizationCodeModel) Create(ctx context.Context, code OauthAuthorizationCode) error {
+	_, err := model.Exec(ctx,
+		"INSERT INTO oauth_authorization_codes (code_hash, client_id, user_id, redirect_uri, scopes, code_challenge, expires_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		code.CodeHash, code.ClientID, code.UserID, code.RedirectURI, strings.Join(code.Scopes, " "), code.CodeChallenge, code.ExpiresAt.UTC(), time.Now().UTC(),
+	)
+
+	return err
+}
+
+// Redeem uses up an unexpired code, returning sql.ErrNoRows if it doesn't exist, has expired or was already used
+func (model *OauthAuthorizationCodeModel) Redeem(ctx context.Context, codeHash string) (OauthAuthorizationCode, error) {
+	now := time.Now().UTC()
+
+	// Marking the code used first makes redeeming it atomic, so two concurrent requests can't both get tokens
+	result, err := model.Exec(ctx, "UPDATE oauth_authorization_codes SET used_at = ? WHERE code_hash = ? AND used_at IS NULL AND expires_at > ?", now, codeHash, now)
+	if err != nil {
+		return OauthAuthorizationCode{}, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return OauthAuthorizationCode{}, err
+	}
+	if affected != 1 {
+		return OauthAuthorizationCode{}, sql.ErrNoRows
+	}
+
+	c := OauthAuthorizationCode{}
+	var scopes string
+	err = model.QueryRow(ctx, "SELECT code_hash, client_id, user_id, redirect_uri, scopes, code_challenge, expires_at FROM oauth_authorization_codes WHERE code_hash = ?", codeHash).
+		Scan(&c.CodeHash, &c.ClientID, &c.UserID, &c.RedirectURI, &scopes, &c.CodeChallenge, &c.ExpiresAt)
+	if err != nil {
+		return OauthAuthorizationCode{}, err
+	}
+	c.Scopes = strings.Fields(scopes)
+
+	return c, nil
+}
+
+func (model *OauthAuthorizationCodeModel) DeleteExpired(ctx context.Context) error {
+	_, err := model.Exec(ctx, "DELETE FROM oauth_authorization_codes WHERE expires_at < ?", time.Now().UTC())
+
+	return err
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// OauthClient is a third-party app registered to use the API through OAuth2. Public clients (e.g. mobile and single
+// page apps) have no secret, so must use PKCE and cannot use the client credentials grant.
+type OauthClient struct {
+	ID           string         `json:"id"`
+	Name         string         `json:"name"`
+	SecretHash   sql.NullString `json:"-"`
+	RedirectURIs []string       `json:"redirectUris"`
+	Scopes       []string       `json:"scopes"`
+	GrantTypes   []string       `json:"grantTypes"`
+	CreatedBy    sql.NullInt64  `json:"-"`
+	RevokedAt    *time.Time     `json:"revokedAt"`
+	CreatedAt    time.Time      `json:"createdAt"`
+}
+
+// IsPublic reports whether the client has no secret
+func (c OauthClient) IsPublic() bool {
+	return !c.SecretHash.Valid
+}
+
+type OauthClientModel struct {
+	*conn
+}
+
+const oauthClientColumns = "id, name, secret_hash, redirect_uris, scopes, grant_types, created_by, revoked_at, created_at"
+
+// Lists of redirect URIs, scopes and grant types are stored space separated, like the OAuth scope parameter
+func scanOauthClient(scanner interface{ Scan(...any) error }) (OauthClient, error) {
+	c := new(OauthClient)
+	var redirectURIs, scopes, grantTypes string
+
+	err := scanner.Scan(&c.ID, &c.Name, &c.SecretHash, &redirectURIs, &scopes, &grantTypes, &c.CreatedBy, &c.RevokedAt, &c.CreatedAt)
+	if err != nil {
+		return OauthClient{}, err
+	}
+
+	c.RedirectURIs = strings.Fields(redirectURIs)
+	c.Scopes = strings.Fields(scopes)
+	c.GrantTypes = strings.Fields(grantTypes)
+
+	return *c, nil
+}
+
+// Create registers a client. secretHash is empty for public clients.
+func (model *OauthClientModel) Create(ctx context.Context, id string, name string, secretHash string, redirectURIs []string, scopes []string, grantTypes []string, createdBy int64) error {
+	secret := sql.NullString{String: secretHash, Valid: secretHash != ""}
+
+	_, err := model.Exec(ctx,
+		"INSERT INTO oauth_clients (id, name, secret_hash, redirect_uris, scopes, grant_types, created_by, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		id, name, secret, strings.Join(redirectURIs, " "), strings.Join(scopes, " "), strings.Join(grantTypes, " "), createdBy, time.Now().UTC(),
+	)
+
+	return err
+}
+
+func (model *OauthClientModel) List(ctx context.Context) ([]OauthClient, error) {
+	rows, err := model.Query(ctx, "SELECT "+oauthClientColumns+" FROM oauth_clients ORDER BY created_at")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clients := []OauthClient{}
+	for rows.Next() {
+		client, err := scanOauthClient(rows)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+
+	return clients, rows.Err()
+}
+
+// GetActive finds a client that has not been revoked
+func (model *OauthClientModel) GetActive(ctx context.Context, id string) (OauthClient, error) {
+	return scanOauthClient(model.QueryRow(ctx, "SELECT "+oauthClientColumns+" FROM oauth_clients WHERE id = ? AND revoked_at IS NULL", id))
+}
+
+// Revoke stops the client being used, returning false if there is no active client with that id
+func (model *OauthClientModel) Revoke(ctx context.Context, id string) (bool, error) {
+	result, err := model.Exec(ctx, "UPDATE oauth_clients SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL", time.Now().UTC(), id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
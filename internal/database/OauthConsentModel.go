@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// OauthConsent records the scopes a user has allowed a client to use
+type OauthConsent struct {
+	UserID     int64     `json:"-"`
+	ClientID   string    `json:"clientId"`
+	ClientName string    `json:"clientName"`
+	Scopes     []string  `json:"scopes"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+type OauthConsentModel struct {
+	*conn
+}
+
+// GetScopes returns the scopes the user has allowed the client, which is empty if they haven't
+// consented or the client has been revoked
+func (model *OauthConsentModel) GetScopes(ctx context.Context, userID int64, clientID string) ([]string, error) {
+	rows, err := model.Query(ctx, "SELECT oauth_consents.scopes FROM oauth_consents JOIN oauth_clients ON oauth_clients.id = oauth_consents.client_id WHERE oauth_consents.user_id = ? AND oauth_consents.client_id = ? AND oauth_clients.revoked_at IS NULL", userID, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scopes := []string{}
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		scopes = strings.Fields(s)
+	}
+
+	return scopes, rows.Err()
+}
+
+// Save records the user's consent to the scopes, replacing what they consented to before
+func (model *OauthConsentModel) Save(ctx context.Context, userID int64, clientID string, scopes []string) error {
+	now := time.Now().UTC()
+	_, err := model.Exec(ctx, "INSERT INTO oauth_consents (user_id, client_id, scopes, created_at, updated_at) VALUES (?, ?, ?, ?, ?) "+
+		model.dialect.OnConflictUpdate("user_id", "client_id")+"scopes = "+model.dialect.Inserted("scopes")+", updated_at = "+model.dialect.Inserted("updated_at"),
+		userID, clientID, strings.Join(scopes, " "), now, now)
+
+	return err
+}
+
+// GetByUserId returns the active clients the user has authorized
+func (model *OauthConsentModel) GetByUserId(ctx context.Context, userID int64) ([]OauthConsent, error) {
+	rows, err := model.Query(ctx, "SELECT oauth_consents.user_id, oauth_consents.client_id, oauth_clients.name, oauth_consents.scopes, oauth_consents.created_at, oauth_consents.updated_at FROM oauth_consents JOIN oauth_clients ON oauth_clients.id = oauth_consents.client_id WHERE oauth_consents.user_id = ? AND oauth_clients.revoked_at IS NULL ORDER BY oauth_consents.created_at", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	consents := []OauthConsent{}
+	for rows.Next() {
+		var c OauthConsent
+		var scopes string
+		if err := rows.Scan(&c.UserID, &c.ClientID, &c.ClientName, &scopes, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		c.Scopes = strings.Fields(scopes)
+		consents = append(consents, c)
+	}
+
+	return consents, rows.Err()
+}
+
+// Delete withdraws the user's consent, returning false if they hadn't given any
+func (model *OauthConsentModel) Delete(ctx context.Context, userID int64, clientID string) (bool, error) {
+	result, err := model.Exec(ctx, "DELETE FROM oauth_consents WHERE user_id = ? AND client_id = ?", userID, clientID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
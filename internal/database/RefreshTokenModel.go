@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type RefreshToken struct {
+	ID         int64        `json:"id"`
+	UserID     int64        `json:"userId"`
+	FamilyID   string       `json:"familyId"`
+	TokenHash  string       `json:"-"`
+	RememberMe bool         `json:"rememberMe"`
+	ExpiresAt  time.Time    `json:"expiresAt"`
+	UsedAt     sql.NullTime `json:"-"`
+	RevokedAt  sql.NullTime `json:"-"`
+	CreatedAt  time.Time    `json:"createdAt"`
+}
+
+type RefreshTokenModel struct {
+	*conn
+}
+
+func (model *RefreshTokenModel) Create(ctx context.Context, userID int64, familyID string, tokenHash string, rememberMe bool, expiresAt time.Time) error {
+	_, err := model.Exec(ctx, "INSERT INTO refresh_tokens (user_id, family_id, token_hash, remember_me, expires_at) VALUES (?, ?, ?, ?, ?)", userID, familyID, tokenHash, rememberMe, expiresAt)
+
+	return err
+}
+
+func (model *RefreshTokenModel) GetByHash(ctx context.Context, tokenHash string) (RefreshToken, error) {
+	t := new(RefreshToken)
+
+	row := model.QueryRow(ctx, "SELECT id, user_id, family_id, token_hash, remember_me, expires_at, used_at, revoked_at, created_at FROM refresh_tokens WHERE token_hash = ?", tokenHash)
+
+	err := row.Scan(&t.ID, &t.UserID, &t.FamilyID, &t.TokenHash, &t.RememberMe, &t.ExpiresAt, &t.UsedAt, &t.RevokedAt, &t.CreatedAt)
+	if err != nil {
+		return RefreshToken{}, err
+	}
+
+	return *t, nil
+}
+
+// MarkUsed flags the refresh token as used, returning false if it had already been used or revoked.
+// The check and update happen in a single statement so two concurrent refreshes cannot both succeed.
+func (model *RefreshTokenModel) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	result, err := model.Exec(ctx, "UPDATE refresh_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL", time.Now().UTC(), id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// RevokeFamily revokes every refresh token descended from the same login
+func (model *RefreshTokenModel) RevokeFamily(ctx context.Context, familyID string) error {
+	_, err := model.Exec(ctx, "UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL", time.Now().UTC(), familyID)
+
+	return err
+}
+
+// RevokeAllForUser revokes every refresh token belonging to the user, signing them out everywhere
+func (model *RefreshTokenModel) RevokeAllForUser(ctx context.Context, userID int64) error {
+	_, err := model.Exec(ctx, "UPDATE refresh_tokens SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL", time.Now().UTC(), userID)
+
+	return err
+}
+
+// RevokeAllForUserExcept revokes every refresh token belonging to the user apart from those in one family
+func (model *RefreshTokenModel) RevokeAllForUserExcept(ctx context.Context, userID int64, familyID string) error {
+	_, err := model.Exec(ctx, "UPDATE refresh_tokens SET revoked_at = ? WHERE user_id = ? AND family_id != ? AND revoked_at IS NULL", time.Now().UTC(), userID, familyID)
+
+	return err
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type RevokedTokenModel struct {
+	*conn
+}
+
+// Revoke records a single token as revoked. The row is only needed until the token would have expired anyway.
+func (model *RevokedTokenModel) Revoke(ctx context.Context, jti string, userID int64, expiresAt time.Time) error {
+	_, err := model.Exec(ctx, "INSERT INTO revoked_tokens (jti, user_id, expires_at) VALUES (?, ?, ?) "+model.dialect.OnConflictIgnore("jti"), jti, userID, expiresAt)
+
+	return err
+}
+
+func (model *RevokedTokenModel) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	var count int
+
+	err := model.QueryRow(ctx, "SELECT COUNT(*) FROM revoked_tokens WHERE jti = ?", jti).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// RevokeAllForUser invalidates every token issued to the user before the given time
+func (model *RevokedTokenModel) RevokeAllForUser(ctx context.Context, userID int64, before time.Time) error {
+	_, err := model.Exec(ctx, "INSERT INTO user_token_revocations (user_id, revoked_before) VALUES (?, ?) "+model.dialect.OnConflictUpdate("user_id")+"revoked_before = "+model.dialect.Inserted("revoked_before"), userID, before)
+
+	return err
+}
+
+// GetRevokedBefore returns the time before which all of the user's tokens are revoked, or the zero time if none are
+func (model *RevokedTokenModel) GetRevokedBefore(ctx context.Context, userID int64) (time.Time, error) {
+	var revokedBefore time.Time
+
+	err := model.QueryRow(ctx, "SELECT revoked_before FROM user_token_revocations WHERE user_id = ?", userID).Scan(&revokedBefore)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return revokedBefore, nil
+}
+
+func (model *RevokedTokenModel) DeleteExpired(ctx context.Context) error {
+	_, err := model.Exec(ctx, "DELETE FROM revoked_tokens WHERE expires_at < ?", time.Now().UTC())
+
+	return err
+}
@@ -0,0 +1,97 @@
+package database
+
+import "context"
+
+type Role struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type RoleModel struct {
+	*conn
+}
+
+func (model *RoleModel) List(ctx context.Context) ([]Role, error) {
+	rows, err := model.Query(ctx, "SELECT id, name, description FROM roles ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []Role{}
+	for rows.Next() {
+		var role Role
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+// GetRoleNamesForUser returns the names of the roles assigned to the user
+func (model *RoleModel) GetRoleNamesForUser(ctx context.Context, userID int64) ([]string, error) {
+	return model.queryNames(ctx, "SELECT roles.name FROM roles INNER JOIN user_roles ON user_roles.role_id = roles.id WHERE user_roles.user_id = ? ORDER BY roles.name", userID)
+}
+
+// GetPermissionNamesForUser returns the names of every permission granted to the user by any of their roles
+func (model *RoleModel) GetPermissionNamesForUser(ctx context.Context, userID int64) ([]string, error) {
+	return model.queryNames(ctx, `SELECT DISTINCT permissions.name FROM permissions
+		INNER JOIN role_permissions ON role_permissions.permission_id = permissions.id
+		INNER JOIN user_roles ON user_roles.role_id = role_permissions.role_id
+		WHERE user_roles.user_id = ?`, userID)
+}
+
+// AssignToUser gives the user the named role, returning false if there is no such role
+func (model *RoleModel) AssignToUser(ctx context.Context, userID int64, roleName string) (bool, error) {
+	result, err := model.Exec(ctx, "INSERT INTO user_roles (user_id, role_id) SELECT users.id, roles.id FROM users, roles WHERE users.id = ? AND roles.name = ? "+model.dialect.OnConflictIgnore("user_id", "role_id"), userID, roleName)
+	if err != nil {
+		return false, err
+	}
+
+	// An already assigned role affects no rows, so check the role exists separately
+	if affected, err := result.RowsAffected(); err != nil || affected == 1 {
+		return affected == 1, err
+	}
+
+	var count int
+	err = model.QueryRow(ctx, "SELECT COUNT(*) FROM roles WHERE name = ?", roleName).Scan(&count)
+
+	return count == 1, err
+}
+
+// RemoveFromUser takes the named role away from the user, returning false if they did not have it
+func (model *RoleModel) RemoveFromUser(ctx context.Context, userID int64, roleName string) (bool, error) {
+	result, err := model.Exec(ctx, "DELETE FROM user_roles WHERE user_id = ? AND role_id IN (SELECT id FROM roles WHERE name = ?)", userID, roleName)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (model *RoleModel) queryNames(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := model.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
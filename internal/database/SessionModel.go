@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Session is a single login on a device. Its id is also the family id of the login's refresh tokens,
+// and the sid claim of its access tokens.
+type Session struct {
+	ID         string       `json:"id"`
+	UserID     int64        `json:"-"`
+	UserAgent  string       `json:"userAgent"`
+	IPAddress  string       `json:"ipAddress"`
+	ExpiresAt  time.Time    `json:"expiresAt"`
+	LastSeenAt time.Time    `json:"lastSeenAt"`
+	RevokedAt  sql.NullTime `json:"-"`
+	CreatedAt  time.Time    `json:"createdAt"`
+}
+
+type SessionModel struct {
+	*conn
+}
+
+const maxUserAgentLength = 512
+
+func (model *SessionModel) Create(ctx context.Context, id string, userID int64, userAgent string, ipAddress string, expiresAt time.Time) error {
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	now := time.Now().UTC()
+	_, err := model.Exec(ctx, "INSERT INTO sessions (id, user_id, user_agent, ip_address, expires_at, last_seen_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)", id, userID, userAgent, ipAddress, expiresAt.UTC(), now, now)
+
+	return err
+}
+
+// GetActiveByUserId returns the user's sessions that have not been revoked or expired, most recently used first
+func (model *SessionModel) GetActiveByUserId(ctx context.Context, userID int64) ([]Session, error) {
+	rows, err := model.Query(ctx, "SELECT id, user_id, user_agent, ip_address, expires_at, last_seen_at, revoked_at, created_at FROM sessions WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ? ORDER BY last_seen_at DESC", userID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		var s Session
+		if err := rows.Scan(&s.ID, &s.UserID, &s.UserAgent, &s.IPAddress, &s.ExpiresAt, &s.LastSeenAt, &s.RevokedAt, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
+
+// Extend records the session being refreshed, returning false if there is no such session
+func (model *SessionModel) Extend(ctx context.Context, id string, ipAddress string, expiresAt time.Time) (bool, error) {
+	result, err := model.Exec(ctx, "UPDATE sessions SET ip_address = ?, expires_at = ?, last_seen_at = ? WHERE id = ?", ipAddress, expiresAt.UTC(), time.Now().UTC(), id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// Touch records the session being used
+func (model *SessionModel) Touch(ctx context.Context, id string) error {
+	_, err := model.Exec(ctx, "UPDATE sessions SET last_seen_at = ? WHERE id = ?", time.Now().UTC(), id)
+
+	return err
+}
+
+// IsRevoked reports whether the session has been revoked. A session that no longer exists counts as revoked.
+func (model *SessionModel) IsRevoked(ctx context.Context, id string) (bool, error) {
+	var revokedAt sql.NullTime
+
+	err := model.QueryRow(ctx, "SELECT revoked_at FROM sessions WHERE id = ?", id).Scan(&revokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return revokedAt.Valid, nil
+}
+
+// Revoke revokes one of the user's sessions, returning false if the user has no active session with that id
+func (model *SessionModel) Revoke(ctx context.Context, userID int64, id string) (bool, error) {
+	result, err := model.Exec(ctx, "UPDATE sessions SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL", time.Now().UTC(), id, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// RevokeAllForUser revokes every one of the user's sessions apart from exceptID, which may be empty
+func (model *SessionModel) RevokeAllForUser(ctx context.Context, userID int64, exceptID string) error {
+	_, err := model.Exec(ctx, "UPDATE sessions SET revoked_at = ? WHERE user_id = ? AND id != ? AND revoked_at IS NULL", time.Now().UTC(), userID, exceptID)
+
+	return err
+}
+
+// DeleteExpired removes sessions that can no longer be refreshed
+func (model *SessionModel) DeleteExpired(ctx context.Context) error {
+	_, err := model.Exec(ctx, "DELETE FROM sessions WHERE expires_at < ?", time.Now().UTC())
+
+	return err
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// TwoFactor is a user's TOTP enrolment. A secret without EnabledAt is still waiting to be confirmed.
+type TwoFactor struct {
+	UserID      int64
+	Secret      sql.NullString
+	EnabledAt   sql.NullTime
+	LastCounter sql.NullInt64
+}
+
+type TwoFactorModel struct {
+	*conn
+}
+
+func (model *TwoFactorModel) Get(ctx context.Context, userID int64) (TwoFactor, error) {
+	t := new(TwoFactor)
+
+	row := model.QueryRow(ctx, "SELECT id, totp_secret, totp_enabled_at, totp_last_counter FROM users WHERE id = ?", userID)
+
+	err := row.Scan(&t.UserID, &t.Secret, &t.EnabledAt, &t.LastCounter)
+	if err != nil {
+		return TwoFactor{}, err
+	}
+
+	return *t, nil
+}
+
+// SetPendingSecret stores a new secret that only takes effect once Enable is called
+func (model *TwoFactorModel) SetPendingSecret(ctx context.Context, userID int64, secret string) error {
+	_, err := model.Exec(ctx, "UPDATE users SET totp_secret = ?, totp_enabled_at = NULL, totp_last_counter = NULL WHERE id = ? AND totp_enabled_at IS NULL", secret, userID)
+
+	return err
+}
+
+func (model *TwoFactorModel) Enable(ctx context.Context, userID int64, counter int64) error {
+	_, err := model.Exec(ctx, "UPDATE users SET totp_enabled_at = ?, totp_last_counter = ? WHERE id = ? AND totp_secret IS NOT NULL", time.Now().UTC(), counter, userID)
+
+	return err
+}
+
+func (model *TwoFactorModel) Disable(ctx context.Context, userID int64) error {
+	_, err := model.Exec(ctx, "UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_counter = NULL WHERE id = ?", userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = model.Exec(ctx, "DELETE FROM recovery_codes WHERE user_id = ?", userID)
+
+	return err
+}
+
+// UseCounter records the time step of an accepted code, returning false if that code (or a later one) was already used
+func (model *TwoFactorModel) UseCounter(ctx context.Context, userID int64, counter int64) (bool, error) {
+	result, err := model.Exec(ctx, "UPDATE users SET totp_last_counter = ? WHERE id = ? AND (totp_last_counter IS NULL OR totp_last_counter < ?)", counter, userID, counter)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// ReplaceRecoveryCodes swaps the user's recovery codes for a new set
+func (model *TwoFactorModel) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	if _, err := model.Exec(ctx, "DELETE FROM recovery_codes WHERE user_id = ?", userID); err != nil {
+		return err
+	}
+
+	for _, codeHash := range codeHashes {
+		if _, err := model.Exec(ctx, "INSERT INTO recovery_codes (user_id, code_hash) VALUES (?, ?)", userID, codeHash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UseRecoveryCode consumes one of the user's recovery codes, returning false if it does not exist or was already used
+func (model *TwoFactorModel) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	result, err := model.Exec(ctx, "UPDATE recovery_codes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL", time.Now().UTC(), userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
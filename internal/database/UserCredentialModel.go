@@ -0,0 +1,94 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// UserCredential is a WebAuthn credential (passkey) registered to a user
+type UserCredential struct {
+	ID              int64        `json:"id"`
+	UserID          int64        `json:"-"`
+	Name            string       `json:"name"`
+	CredentialID    []byte       `json:"-"`
+	PublicKey       []byte       `json:"-"`
+	AttestationType string       `json:"-"`
+	AAGUID          []byte       `json:"-"`
+	SignCount       uint32       `json:"-"`
+	Flags           uint8        `json:"-"`
+	Transports      string       `json:"-"`
+	LastUsedAt      sql.NullTime `json:"-"`
+	CreatedAt       time.Time    `json:"createdAt"`
+}
+
+type UserCredentialModel struct {
+	*conn
+}
+
+const userCredentialColumns = "id, user_id, name, credential_id, public_key, attestation_type, aaguid, sign_count, flags, transports, last_used_at, created_at"
+
+func scanUserCredential(scanner interface{ Scan(...any) error }) (UserCredential, error) {
+	c := new(UserCredential)
+
+	err := scanner.Scan(&c.ID, &c.UserID, &c.Name, &c.CredentialID, &c.PublicKey, &c.AttestationType, &c.AAGUID, &c.SignCount, &c.Flags, &c.Transports, &c.LastUsedAt, &c.CreatedAt)
+	if err != nil {
+		return UserCredential{}, err
+	}
+
+	return *c, nil
+}
+
+func (model *UserCredentialModel) Create(ctx context.Context, credential UserCredential) error {
+	_, err := model.Exec(ctx,
+		"INSERT INTO user_credentials (user_id, name, credential_id, public_key, attestation_type, aaguid, sign_count, flags, transports) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		credential.UserID, credential.Name, credential.CredentialID, credential.PublicKey, credential.AttestationType, credential.AAGUID, credential.SignCount, credential.Flags, credential.Transports,
+	)
+
+	return err
+}
+
+func (model *UserCredentialModel) GetByUserId(ctx context.Context, userID int64) ([]UserCredential, error) {
+	rows, err := model.Query(ctx, "SELECT "+userCredentialColumns+" FROM user_credentials WHERE user_id = ? ORDER BY created_at", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	credentials := []UserCredential{}
+	for rows.Next() {
+		credential, err := scanUserCredential(rows)
+		if err != nil {
+			return nil, err
+		}
+		credentials = append(credentials, credential)
+	}
+
+	return credentials, rows.Err()
+}
+
+func (model *UserCredentialModel) GetByCredentialId(ctx context.Context, credentialID []byte) (UserCredential, error) {
+	return scanUserCredential(model.QueryRow(ctx, "SELECT "+userCredentialColumns+" FROM user_credentials WHERE credential_id = ?", credentialID))
+}
+
+// RecordUse stores the authenticator's new signature counter and flags after a successful login
+func (model *UserCredentialModel) RecordUse(ctx context.Context, id int64, signCount uint32, flags uint8) error {
+	_, err := model.Exec(ctx, "UPDATE user_credentials SET sign_count = ?, flags = ?, last_used_at = ? WHERE id = ?", signCount, flags, time.Now().UTC(), id)
+
+	return err
+}
+
+// Delete removes one of the user's credentials, returning false if the user has no credential with that id
+func (model *UserCredentialModel) Delete(ctx context.Context, userID int64, id int64) (bool, error) {
+	result, err := model.Exec(ctx, "DELETE FROM user_credentials WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
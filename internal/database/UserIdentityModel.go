@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// UserIdentity links a user to their account with an external OpenID Connect provider
+type UserIdentity struct {
+	ID          int64        `json:"id"`
+	UserID      int64        `json:"-"`
+	Provider    string       `json:"provider"`
+	Subject     string       `json:"-"`
+	Email       string       `json:"email"`
+	LastLoginAt sql.NullTime `json:"-"`
+	CreatedAt   time.Time    `json:"createdAt"`
+}
+
+type UserIdentityModel struct {
+	*conn
+}
+
+// GetByProviderSubject finds the identity for the provider's stable user identifier (the id_token sub claim)
+func (model *UserIdentityModel) GetByProviderSubject(ctx context.Context, provider string, subject string) (UserIdentity, error) {
+	i := new(UserIdentity)
+
+	row := model.QueryRow(ctx, "SELECT id, user_id, provider, subject, email, last_login_at, created_at FROM user_identities WHERE provider = ? AND subject = ?", provider, subject)
+
+	err := row.Scan(&i.ID, &i.UserID, &i.Provider, &i.Subject, &i.Email, &i.LastLoginAt, &i.CreatedAt)
+	if err != nil {
+		return UserIdentity{}, err
+	}
+
+	return *i, nil
+}
+
+func (model *UserIdentityModel) Create(ctx context.Context, userID int64, provider string, subject string, email string) error {
+	_, err := model.Exec(ctx, "INSERT INTO user_identities (user_id, provider, subject, email, last_login_at) VALUES (?, ?, ?, ?, ?)", userID, provider, subject, email, time.Now().UTC())
+
+	return err
+}
+
+// RecordLogin keeps the identity's email up to date with the provider and records when it was last used
+func (model *UserIdentityModel) RecordLogin(ctx context.Context, id int64, email string) error {
+	_, err := model.Exec(ctx, "UPDATE user_identities SET email = ?, last_login_at = ? WHERE id = ?", email, time.Now().UTC(), id)
+
+	return err
+}
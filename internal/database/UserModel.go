@@ -1,26 +1,28 @@
 package database
 
 import (
-	"github.com/jmoiron/sqlx"
-	"golang.org/x/crypto/bcrypt"
+	"context"
+	"time"
 )
 
 type UserModel struct {
-	*sqlx.DB
+	*conn
 }
 
 type User struct {
-	ID       int64  `json:"id"`
-	Username string `json:"username"`
-	Password string `json:"-"`
+	ID              int64      `json:"id"`
+	Username        string     `json:"username"`
+	Password        string     `json:"-"`
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt"`
+	TOTPEnabledAt   *time.Time `json:"twoFactorEnabledAt"`
 }
 
-func (userModel *UserModel) FindUser(id int64) (User, error) {
+func (userModel *UserModel) FindUser(ctx context.Context, id int64) (User, error) {
 	u := new(User)
 
-	row := userModel.DB.QueryRow("SELECT id, username, password FROM users WHERE id = ?", id)
+	row := userModel.QueryRow(ctx, "SELECT id, username, password, email_verified_at, totp_enabled_at FROM users WHERE id = ?", id)
 
-	err := row.Scan(&u.ID, &u.Username, &u.Password)
+	err := row.Scan(&u.ID, &u.Username, &u.Password, &u.EmailVerifiedAt, &u.TOTPEnabledAt)
 	if err != nil {
 		return User{}, err
 	}
@@ -28,13 +30,13 @@ func (userModel *UserModel) FindUser(id int64) (User, error) {
 	return *u, nil
 }
 
-func (userModel *UserModel) GetByUsername(username string) (User, error) {
+func (userModel *UserModel) GetByUsername(ctx context.Context, username string) (User, error) {
 	u := new(User)
 
 	row :=
-		userModel.DB.QueryRow("SELECT id, username, password FROM users WHERE username = ?", username)
+		userModel.QueryRow(ctx, "SELECT id, username, password, email_verified_at, totp_enabled_at FROM users WHERE username = ?", username)
 
-	err := row.Scan(&u.ID, &u.Username, &u.Password)
+	err := row.Scan(&u.ID, &u.Username, &u.Password, &u.EmailVerifiedAt, &u.TOTPEnabledAt)
 	if err != nil {
 		return User{}, err
 	}
@@ -42,28 +44,61 @@ func (userModel *UserModel) GetByUsername(username string) (User, error) {
 	return *u, nil
 }
 
-func (u *UserModel) Create(username string, password string) (int64, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+// List returns a page of users, ordered by id
+func (userModel *UserModel) List(ctx context.Context, limit int, offset int) ([]User, error) {
+	rows, err := userModel.Query(ctx, "SELECT id, username, password, email_verified_at, totp_enabled_at FROM users ORDER BY id LIMIT ? OFFSET ?", limit, offset)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		var u User
+		if err := rows.Scan(&u.ID, &u.Username, &u.Password, &u.EmailVerifiedAt, &u.TOTPEnabledAt); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
 	}
 
-	result, err := u.DB.Exec("INSERT INTO users (username, password) VALUES (?, ?)", username, hashedPassword)
+	return users, rows.Err()
+}
 
-	if err != nil {
-		return 0, err
-	}
+func (userModel *UserModel) Count(ctx context.Context) (int, error) {
+	var count int
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return 0, err
-	}
+	err := userModel.QueryRow(ctx, "SELECT COUNT(*) FROM users").Scan(&count)
+
+	return count, err
+}
+
+// Create inserts a new user. The password must already be hashed, with application.Passwords.
+func (u *UserModel) Create(ctx context.Context, username string, passwordHash string) (int64, error) {
+	return u.InsertReturningID(ctx, "INSERT INTO users (username, password) VALUES (?, ?)", username, passwordHash)
+}
+
+// UpdatePassword replaces the user's password hash
+func (u *UserModel) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	_, err := u.Exec(ctx, "UPDATE users SET password = ? WHERE id = ?", passwordHash, id)
+
+	return err
+}
+
+// UpdateEmail changes the user's email address (their username), marking it verified as they have confirmed it
+func (u *UserModel) UpdateEmail(ctx context.Context, id int64, email string) error {
+	_, err := u.Exec(ctx, "UPDATE users SET username = ?, email_verified_at = ? WHERE id = ?", email, time.Now().UTC(), id)
+
+	return err
+}
+
+func (u *UserModel) MarkEmailVerified(ctx context.Context, id int64) error {
+	_, err := u.Exec(ctx, "UPDATE users SET email_verified_at = ? WHERE id = ? AND email_verified_at IS NULL", time.Now().UTC(), id)
 
-	return id, nil
+	return err
 }
 
-func (u *UserModel) Delete(id int64) error {
-	_, err := u.DB.Exec("DELETE FROM users WHERE id = ?", id)
+func (u *UserModel) Delete(ctx context.Context, id int64) error {
+	_, err := u.Exec(ctx, "DELETE FROM users WHERE id = ?", id)
 	if err != nil {
 		return err
 	}
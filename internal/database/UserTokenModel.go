@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Purposes a UserToken can be issued for. A token is only ever valid for the purpose it was issued for.
+const (
+	UserTokenPasswordReset     = "password_reset"
+	UserTokenEmailVerification = "email_verification"
+	// UserTokenEmailChange tokens carry the new email address as their payload
+	UserTokenEmailChange = "email_change"
+	// UserTokenMagicLink tokens carry their login options and browser binding as a JSON payload
+	UserTokenMagicLink = "magic_link"
+)
+
+// UserToken is a hashed, expiring, single-use token emailed to a user, e.g. for resetting their password
+type UserToken struct {
+	ID        int64          `json:"id"`
+	UserID    int64          `json:"userId"`
+	Purpose   string         `json:"purpose"`
+	TokenHash string         `json:"-"`
+	Payload   sql.NullString `json:"-"`
+	ExpiresAt time.Time      `json:"expiresAt"`
+	UsedAt    sql.NullTime   `json:"-"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+type UserTokenModel struct {
+	*conn
+}
+
+func (model *UserTokenModel) Create(ctx context.Context, userID int64, purpose string, tokenHash string, payload string, expiresAt time.Time) error {
+	_, err := model.Exec(ctx, "INSERT INTO user_tokens (user_id, purpose, token_hash, payload, expires_at) VALUES (?, ?, ?, ?, ?)", userID, purpose, tokenHash, sql.NullString{String: payload, Valid: payload != ""}, expiresAt)
+
+	return err
+}
+
+// GetValid finds an unused, unexpired token issued for the given purpose
+func (model *UserTokenModel) GetValid(ctx context.Context, purpose string, tokenHash string) (UserToken, error) {
+	t := new(UserToken)
+
+	row := model.QueryRow(ctx, "SELECT id, user_id, purpose, token_hash, payload, expires_at, used_at, created_at FROM user_tokens WHERE purpose = ? AND token_hash = ? AND used_at IS NULL AND expires_at > ?", purpose, tokenHash, time.Now().UTC())
+
+	err := row.Scan(&t.ID, &t.UserID, &t.Purpose, &t.TokenHash, &t.Payload, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt)
+	if err != nil {
+		return UserToken{}, err
+	}
+
+	return *t, nil
+}
+
+// MarkUsed consumes the token, returning false if it had already been used
+func (model *UserTokenModel) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	result, err := model.Exec(ctx, "UPDATE user_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL", time.Now().UTC(), id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// InvalidateForUser consumes every outstanding token of the given purpose, so only a newly issued one will work
+func (model *UserTokenModel) InvalidateForUser(ctx context.Context, userID int64, purpose string) error {
+	_, err := model.Exec(ctx, "UPDATE user_tokens SET used_at = ? WHERE user_id = ? AND purpose = ? AND used_at IS NULL", time.Now().UTC(), userID, purpose)
+
+	return err
+}
+
+// CountCreatedSince counts the tokens of a purpose issued to the user since the given time, for throttling
+func (model *UserTokenModel) CountCreatedSince(ctx context.Context, userID int64, purpose string, since time.Time) (int, error) {
+	var count int
+
+	err := model.QueryRow(ctx, "SELECT COUNT(*) FROM user_tokens WHERE user_id = ? AND purpose = ? AND created_at >= ?", userID, purpose, since.UTC()).Scan(&count)
+
+	return count, err
+}
@@ -1,6 +1,9 @@
 package database
 
-import "github.com/jmoiron/sqlx"
+import (
+	"context"
+	"time"
+)
 
 type UserWorkoutBackup struct {
 	UserID     int64  `json:"userId"`
@@ -10,13 +13,13 @@ type UserWorkoutBackup struct {
 }
 
 type UserWorkoutBackupModel struct {
-	*sqlx.DB
+	*conn
 }
 
-func (model *UserWorkoutBackupModel) GetByUserId(userID int64) (UserWorkoutBackup, error) {
+func (model *UserWorkoutBackupModel) GetByUserId(ctx context.Context, userID int64) (UserWorkoutBackup, error) {
 	u := new(UserWorkoutBackup)
 
-	row := model.DB.QueryRow("SELECT user_id, backup_path, created_at, updated_at FROM user_workout_backups WHERE user_id = ?", userID)
+	row := model.QueryRow(ctx, "SELECT user_id, backup_path, created_at, updated_at FROM user_workout_backups WHERE user_id = ?", userID)
 
 	err := row.Scan(&u.UserID, &u.BackupPath, &u.CreatedAt, &u.UpdatedAt)
 	if err != nil {
@@ -26,16 +29,22 @@ func (model *UserWorkoutBackupModel) GetByUserId(userID int64) (UserWorkoutBacku
 	return *u, nil
 }
 
-func (model *UserWorkoutBackupModel) CreateWorkoutBackup(userID int64, backupPath string) error {
-	_, err := model.DB.Exec("INSERT INTO user_workout_backups (user_id, backup_path) VALUES (?, ?)", userID, backupPath)
+func (model *UserWorkoutBackupModel) CreateWorkoutBackup(ctx context.Context, userID int64, backupPath string) error {
+	_, err := model.Exec(ctx, "INSERT INTO user_workout_backups (user_id, backup_path) VALUES (?, ?)", userID, backupPath)
 
 	return err
 }
 
-func (model *UserWorkoutBackupModel) TouchWorkoutBackup(uwb *UserWorkoutBackup) error {
+func (model *UserWorkoutBackupModel) TouchWorkoutBackup(ctx context.Context, uwb *UserWorkoutBackup) error {
 	// Just update the updated_at
 
-	_, err := model.DB.Exec("UPDATE user_workout_backups SET updated_at = NOW() WHERE user_id = ? AND backup_path = ?", uwb.UserID, uwb.BackupPath)
+	_, err := model.Exec(ctx, "UPDATE user_workout_backups SET updated_at = ? WHERE user_id = ? AND backup_path = ?", time.Now().UTC(), uwb.UserID, uwb.BackupPath)
+
+	return err
+}
+
+func (model *UserWorkoutBackupModel) DeleteForUser(ctx context.Context, userID int64) error {
+	_, err := model.Exec(ctx, "DELETE FROM user_workout_backups WHERE user_id = ?", userID)
 
 	return err
 }
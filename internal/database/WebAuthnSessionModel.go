@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Ceremonies a WebAuthnSession can be created for
+const (
+	WebAuthnRegistration = "registration"
+	WebAuthnLogin        = "login"
+)
+
+// WebAuthnSession holds the challenge for a WebAuthn ceremony between its begin and finish requests
+type WebAuthnSession struct {
+	ID        int64
+	UserID    sql.NullInt64
+	Ceremony  string
+	Data      string
+	ExpiresAt time.Time
+}
+
+type WebAuthnSessionModel struct {
+	*conn
+}
+
+// Create stores the session data. userID is 0 for ceremonies where the user is not yet known, such as passkey login.
+func (model *WebAuthnSessionModel) Create(ctx context.Context, tokenHash string, userID int64, ceremony string, data string, expiresAt time.Time) error {
+	_, err := model.Exec(ctx, "INSERT INTO webauthn_sessions (token_hash, user_id, ceremony, data, expires_at) VALUES (?, ?, ?, ?, ?)", tokenHash, sql.NullInt64{Int64: userID, Valid: userID != 0}, ceremony, data, expiresAt)
+
+	return err
+}
+
+// Consume fetches and deletes an unexpired session, so each challenge can only be answered once
+func (model *WebAuthnSessionModel) Consume(ctx context.Context, tokenHash string, ceremony string) (WebAuthnSession, error) {
+	s := new(WebAuthnSession)
+
+	row := model.QueryRow(ctx, "SELECT id, user_id, ceremony, data, expires_at FROM webauthn_sessions WHERE token_hash = ? AND ceremony = ? AND expires_at > ?", tokenHash, ceremony, time.Now().UTC())
+
+	if err := row.Scan(&s.ID, &s.UserID, &s.Ceremony, &s.Data, &s.ExpiresAt); err != nil {
+		return WebAuthnSession{}, err
+	}
+
+	result, err := model.Exec(ctx, "DELETE FROM webauthn_sessions WHERE id = ?", s.ID)
+	if err != nil {
+		return WebAuthnSession{}, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return WebAuthnSession{}, err
+	}
+	if affected != 1 {
+		// Another request consumed it first
+		return WebAuthnSession{}, sql.ErrNoRows
+	}
+
+	return *s, nil
+}
+
+func (model *WebAuthnSessionModel) DeleteExpired(ctx context.Context) error {
+	_, err := model.Exec(ctx, "DELETE FROM webauthn_sessions WHERE expires_at < ?", time.Now().UTC())
+
+	return err
+}
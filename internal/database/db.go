@@ -2,38 +2,131 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
+	_ "modernc.org/sqlite"
 )
 
-const defaultTimeout = 3 * time.Second
+// connectTimeout limits how long connecting may take. Queries have their own timeout, given to New.
+const connectTimeout = 3 * time.Second
 
+// DB is the connection pool, with every store bound to it. Use WithTx for stores bound to a transaction.
 type DB struct {
 	*sqlx.DB
-	UserModel
-	UserWorkoutBackupModel
-	FeedbackModel
+	Dialect Dialect
+	Stores
+	// LoginAttempts is a throttle backend rather than a store, so is not part of Stores
+	LoginAttempts *LoginAttemptModel
 }
 
-func New(dsn string) (*DB, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+// New connects to the database. dsn is in the driver's own format, which for SQLite is the path to the file.
+// Each query is canceled after queryTimeout, or when its context ends if sooner; zero means no timeout.
+func New(dialect Dialect, dsn string, queryTimeout time.Duration) (*DB, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
 	defer cancel()
 
-	db, err := sqlx.ConnectContext(ctx, "mysql", dsn)
+	sqlDB, err := open(dialect, dsn, false)
 	if err != nil {
 		return nil, err
 	}
 
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(2 * time.Hour)
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+
+	db := sqlx.NewDb(sqlDB, driverNames[dialect])
+
+	if dialect == DialectSQLite && isSQLiteMemory(dsn) {
+		// A :memory: database only exists on the connection that made it
+		db.SetMaxOpenConns(1)
+	} else {
+		// SQLite allows one writer at a time, but in WAL mode readers don't wait for it, and writers wait up to the
+		// busy timeout, so it can have a pool like the others
+		db.SetMaxOpenConns(10)
+		db.SetMaxIdleConns(5)
+		db.SetConnMaxLifetime(2 * time.Hour)
+	}
+
+	c := &conn{db: db, q: db, dialect: dialect, timeout: queryTimeout}
+
+	return &DB{DB: db, Dialect: dialect, Stores: newStores(c), LoginAttempts: &LoginAttemptModel{c}}, nil
+}
+
+// isSQLiteMemory reports whether the SQLite dsn is for an in-memory database rather than a file
+func isSQLiteMemory(dsn string) bool {
+	return dsn == "" || strings.HasPrefix(dsn, ":memory:") || strings.Contains(dsn, "mode=memory")
+}
+
+// OpenForMigrations opens a separate connection pool for the migrator, which needs to run whole migration
+// files at once and so enables MySQL's multiStatements. That is kept off for the application's own pool, where it
+// would make SQL injection more damaging.
+func OpenForMigrations(dialect Dialect, dsn string) (*sql.DB, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+
+	db, err := open(dialect, dsn, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+var driverNames = map[Dialect]string{
+	DialectMySQL:    "mysql",
+	DialectPostgres: "pgx",
+	DialectSQLite:   "sqlite",
+}
+
+// open applies the connection settings every connection relies on. Timestamp columns are scanned into time.Time,
+// and the session is kept in UTC so CURRENT_TIMESTAMP defaults compare correctly against the UTC times we pass in.
+func open(dialect Dialect, dsn string, multiStatements bool) (*sql.DB, error) {
+	switch dialect {
+	case DialectMySQL:
+		cfg, err := mysql.ParseDSN(dsn)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ParseTime = true
+		cfg.MultiStatements = multiStatements
+		if cfg.Params == nil {
+			cfg.Params = map[string]string{}
+		}
+		cfg.Params["time_zone"] = "'+00:00'"
+
+		return sql.Open(driverNames[dialect], cfg.FormatDSN())
+	case DialectPostgres:
+		cfg, err := pgx.ParseConfig(dsn)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RuntimeParams["timezone"] = "UTC"
+
+		return stdlib.OpenDB(*cfg), nil
+	case DialectSQLite:
+		// SQLite leaves foreign keys off unless asked. Times are stored as text, so they need a format that sorts
+		// correctly for comparisons to work.
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		dsn += separator + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_time_format=sqlite"
+
+		return sql.Open(driverNames[dialect], dsn)
+	}
 
-	return &DB{
-		DB:                     db,
-		UserModel:              UserModel{db},
-		UserWorkoutBackupModel: UserWorkoutBackupModel{db},
-		FeedbackModel:          FeedbackModel{db},
-	}, nil
+	return nil, fmt.Errorf("unknown database dialect %q", dialect)
 }
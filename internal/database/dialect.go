@@ -0,0 +1,204 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Dialect is the SQL database the models run against, chosen with DB_DRIVER. Queries are written for MySQL,
+// with ? placeholders, and the few statements that can't be written portably ask the dialect for their syntax.
+type Dialect string
+
+const (
+	DialectMySQL    Dialect = "mysql"
+	DialectPostgres Dialect = "postgres"
+	// DialectSQLite needs no database server, which suits local development. It is not meant for running
+	// more than one instance against the same file.
+	DialectSQLite Dialect = "sqlite"
+)
+
+// ParseDialect validates a DB_DRIVER value
+func ParseDialect(name string) (Dialect, error) {
+	switch d := Dialect(strings.ToLower(strings.TrimSpace(name))); d {
+	case DialectMySQL, DialectPostgres, DialectSQLite:
+		return d, nil
+	case "postgresql", "pgx":
+		return DialectPostgres, nil
+	case "sqlite3":
+		return DialectSQLite, nil
+	}
+
+	return "", fmt.Errorf("unknown database driver %q, expected mysql, postgres or sqlite", name)
+}
+
+// Rebind rewrites the ? placeholders in query for the dialect
+func (d Dialect) Rebind(query string) string {
+	if d == DialectPostgres {
+		return sqlx.Rebind(sqlx.DOLLAR, query)
+	}
+
+	return query
+}
+
+// OnConflictUpdate starts the clause that turns an INSERT into an upsert, followed by the assignments to make to
+// the existing row when one of the conflict columns is already taken
+func (d Dialect) OnConflictUpdate(conflict ...string) string {
+	if d == DialectMySQL {
+		return "ON DUPLICATE KEY UPDATE "
+	}
+
+	return "ON CONFLICT (" + strings.Join(conflict, ", ") + ") DO UPDATE SET "
+}
+
+// OnConflictIgnore is the clause that makes an INSERT do nothing when one of the conflict columns is already taken.
+// Unlike INSERT IGNORE, other errors still fail the statement.
+func (d Dialect) OnConflictIgnore(conflict ...string) string {
+	if d == DialectMySQL {
+		return "ON DUPLICATE KEY UPDATE " + conflict[0] + " = " + conflict[0]
+	}
+
+	return "ON CONFLICT (" + strings.Join(conflict, ", ") + ") DO NOTHING"
+}
+
+// Inserted refers to a column of the row an upsert tried to insert
+func (d Dialect) Inserted(column string) string {
+	if d == DialectMySQL {
+		return "VALUES(" + column + ")"
+	}
+
+	return "excluded." + column
+}
+
+// conn runs the models' queries against the database, or a transaction in it, rebinding them for its dialect. Each
+// query runs under the caller's context, limited to timeout if it is set, and is stopped if the context ends first.
+type conn struct {
+	db *sqlx.DB
+	// q is db, or tx inside WithTx
+	q  querier
+	tx *sql.Tx
+	// depth counts the savepoints nested inside tx
+	depth   int
+	dialect Dialect
+	timeout time.Duration
+}
+
+// querier is what *sqlx.DB and *sql.Tx have in common
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (c *conn) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	result, err := c.q.ExecContext(ctx, c.dialect.Rebind(query), utcArgs(args)...)
+
+	return result, canceled(ctx, err)
+}
+
+// Query runs a query returning rows. The timeout covers reading the rows too, so they must be closed.
+func (c *conn) Query(ctx context.Context, query string, args ...any) (*rows, error) {
+	ctx, cancel := c.withTimeout(ctx)
+
+	sqlRows, err := c.q.QueryContext(ctx, c.dialect.Rebind(query), utcArgs(args)...)
+	if err != nil {
+		cancel()
+		return nil, canceled(ctx, err)
+	}
+
+	return &rows{Rows: sqlRows, ctx: ctx, cancel: cancel}, nil
+}
+
+// QueryRow runs a query returning at most one row. The timeout covers the row's Scan.
+func (c *conn) QueryRow(ctx context.Context, query string, args ...any) *row {
+	ctx, cancel := c.withTimeout(ctx)
+
+	return &row{row: c.q.QueryRowContext(ctx, c.dialect.Rebind(query), utcArgs(args)...), ctx: ctx, cancel: cancel}
+}
+
+// InsertReturningID runs an INSERT into a table with an auto-incrementing id column and returns the new row's id.
+// PostgreSQL has no LastInsertId, so the id is returned by the statement instead.
+func (c *conn) InsertReturningID(ctx context.Context, query string, args ...any) (int64, error) {
+	if c.dialect == DialectPostgres {
+		var id int64
+		err := c.QueryRow(ctx, query+" RETURNING id", args...).Scan(&id)
+
+		return id, err
+	}
+
+	result, err := c.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+func (c *conn) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+// rows are the results of Query, which release the query's timeout when closed
+type rows struct {
+	*sql.Rows
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (r *rows) Scan(dest ...any) error {
+	return canceled(r.ctx, r.Rows.Scan(dest...))
+}
+
+func (r *rows) Err() error {
+	return canceled(r.ctx, r.Rows.Err())
+}
+
+func (r *rows) Close() error {
+	defer r.cancel()
+
+	return r.Rows.Close()
+}
+
+// row is the result of QueryRow, which releases the query's timeout once scanned
+type row struct {
+	row    *sql.Row
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (r *row) Scan(dest ...any) error {
+	defer r.cancel()
+
+	return canceled(r.ctx, r.row.Scan(dest...))
+}
+
+// utcArgs converts times to UTC. The MySQL driver does this itself, but PostgreSQL timestamp columns and SQLite's
+// text timestamps keep the wall clock time of whatever location they are given.
+func utcArgs(args []any) []any {
+	for i, arg := range args {
+		switch t := arg.(type) {
+		case time.Time:
+			args[i] = t.UTC()
+		case *time.Time:
+			if t != nil {
+				args[i] = t.UTC()
+			}
+		case sql.NullTime:
+			t.Time = t.Time.UTC()
+			args[i] = t
+		}
+	}
+
+	return args
+}
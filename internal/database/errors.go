@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// CanceledError is returned when a query is stopped before it finishes, either because its context was canceled,
+// usually by the client disconnecting, or because it ran longer than the query timeout
+type CanceledError struct {
+	// Err is context.Canceled or context.DeadlineExceeded
+	Err error
+}
+
+func (e *CanceledError) Error() string {
+	if e.Timeout() {
+		return "database query timed out"
+	}
+
+	return "database query canceled"
+}
+
+func (e *CanceledError) Unwrap() error {
+	return e.Err
+}
+
+// Timeout reports whether the query ran out of time, rather than being canceled
+func (e *CanceledError) Timeout() bool {
+	return errors.Is(e.Err, context.DeadlineExceeded)
+}
+
+// IsCanceled reports whether err is, or wraps, a CanceledError
+func IsCanceled(err error) bool {
+	var canceledErr *CanceledError
+	return errors.As(err, &canceledErr)
+}
+
+// canceled replaces err with a CanceledError if the query failed because ctx ended. Each driver reports this
+// differently, so the context is checked rather than the error.
+func canceled(ctx context.Context, err error) error {
+	if err == nil || errors.Is(err, sql.ErrNoRows) || ctx.Err() == nil {
+		return err
+	}
+
+	return &CanceledError{Err: ctx.Err()}
+}
+
+// isRetryable reports whether err means the database gave up on a transaction because it conflicted with another,
+// so running it again may succeed
+func isRetryable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		// ER_LOCK_DEADLOCK
+		return mysqlErr.Number == 1213
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// serialization_failure and deadlock_detected
+		return pgErr.Code == "40001" || pgErr.Code == "40P01"
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		// Extended codes such as SQLITE_BUSY_SNAPSHOT keep the primary code in the low byte
+		return sqliteErr.Code()&0xff == sqlite3.SQLITE_BUSY
+	}
+
+	return false
+}
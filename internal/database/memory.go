@@ -0,0 +1,230 @@
+package database
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// memoryState is the state shared by the memory stores, which stand in for the database's tables
+type memoryState struct {
+	// mu is held by each store call
+	mu sync.Mutex
+	// txMu is held for the whole of a transaction, and by each store call outside one, so nothing else changes the
+	// tables while a transaction runs
+	txMu   sync.Mutex
+	tables memoryTables
+}
+
+// memoryDB is the memory stores' view of the state, either inside a transaction or outside one
+type memoryDB struct {
+	state *memoryState
+	inTx  bool
+}
+
+// memoryTables holds a map or slice for each table. Rows are stored by value, so copying the maps copies the tables.
+type memoryTables struct {
+	nextID int64
+
+	users          map[int64]User
+	twoFactor      map[int64]TwoFactor
+	recoveryCodes  map[int64]map[string]bool // user id to code hash to whether it is used
+	workoutBackups map[int64]UserWorkoutBackup
+	feedback       []Feedback
+
+	refreshTokens    map[int64]RefreshToken
+	revokedTokens    map[string]revokedToken
+	revokedBefore    map[int64]time.Time
+	userTokens       map[int64]UserToken
+	credentials      map[int64]UserCredential
+	webAuthnSessions map[string]WebAuthnSession
+	identities       map[int64]UserIdentity
+	apiKeys          map[int64]ApiKey
+	sessions         map[string]Session
+
+	roles           map[int64]Role
+	rolePermissions map[int64][]string
+	userRoles       map[int64]map[int64]bool // user id to role ids
+
+	impersonationAudit []ImpersonationAuditEntry
+	oauthClients       map[string]OauthClient
+	oauthCodes         map[string]oauthCode
+	oauthConsents      map[oauthConsentKey]OauthConsent
+}
+
+// NewMemoryStores returns stores that keep everything in memory, for tests and trying the API out. Like the database
+// they return sql.ErrNoRows for missing rows, delete a user's rows along with the user, and start with the roles and
+// permissions the migrations seed.
+func NewMemoryStores() Stores {
+	state := &memoryState{tables: memoryTables{
+		users:            map[int64]User{},
+		twoFactor:        map[int64]TwoFactor{},
+		recoveryCodes:    map[int64]map[string]bool{},
+		workoutBackups:   map[int64]UserWorkoutBackup{},
+		refreshTokens:    map[int64]RefreshToken{},
+		revokedTokens:    map[string]revokedToken{},
+		revokedBefore:    map[int64]time.Time{},
+		userTokens:       map[int64]UserToken{},
+		credentials:      map[int64]UserCredential{},
+		webAuthnSessions: map[string]WebAuthnSession{},
+		identities:       map[int64]UserIdentity{},
+		apiKeys:          map[int64]ApiKey{},
+		sessions:         map[string]Session{},
+		roles:            map[int64]Role{},
+		rolePermissions:  map[int64][]string{},
+		userRoles:        map[int64]map[int64]bool{},
+		oauthClients:     map[string]OauthClient{},
+		oauthCodes:       map[string]oauthCode{},
+		oauthConsents:    map[oauthConsentKey]OauthConsent{},
+	}}
+
+	admin := state.tables.newID()
+	state.tables.roles[admin] = Role{ID: admin, Name: "admin", Description: "Full access to the admin API"}
+	state.tables.rolePermissions[admin] = []string{"users.view", "users.manage_roles", "users.impersonate", "oauth.manage_clients"}
+
+	return (&memoryDB{state: state}).stores()
+}
+
+func (db *memoryDB) stores() Stores {
+	return Stores{
+		Users:              &MemoryUserStore{db},
+		WorkoutBackups:     &MemoryWorkoutBackupStore{db},
+		Feedback:           &MemoryFeedbackStore{db},
+		RefreshTokens:      &MemoryRefreshTokenStore{db},
+		RevokedTokens:      &MemoryRevokedTokenStore{db},
+		UserTokens:         &MemoryUserTokenStore{db},
+		TwoFactor:          &MemoryTwoFactorStore{db},
+		Credentials:        &MemoryUserCredentialStore{db},
+		WebAuthnSessions:   &MemoryWebAuthnSessionStore{db},
+		Identities:         &MemoryUserIdentityStore{db},
+		ApiKeys:            &MemoryApiKeyStore{db},
+		Roles:              &MemoryRoleStore{db},
+		Sessions:           &MemorySessionStore{db},
+		ImpersonationAudit: &MemoryImpersonationAuditStore{db},
+		OauthClients:       &MemoryOauthClientStore{db},
+		OauthCodes:         &MemoryOauthAuthorizationCodeStore{db},
+		OauthConsents:      &MemoryOauthConsentStore{db},
+		tx:                 memoryTx{db: db},
+	}
+}
+
+// lock locks the tables for a store call, returning them along with the function to unlock them. Calls outside a
+// transaction wait for any running transaction to finish, so calling the stores from outside a transaction's fn, on
+// the goroutine running it, deadlocks.
+func (db *memoryDB) lock() (*memoryTables, func()) {
+	if !db.inTx {
+		db.state.txMu.Lock()
+	}
+	db.state.mu.Lock()
+
+	return &db.state.tables, func() {
+		db.state.mu.Unlock()
+		if !db.inTx {
+			db.state.txMu.Unlock()
+		}
+	}
+}
+
+// memoryTx runs transactions by copying the tables when they begin, and putting the copy back if they fail. Store
+// calls outside the transaction wait for it, so putting the copy back only undoes the transaction's own changes.
+type memoryTx struct {
+	db *memoryDB
+}
+
+func (t memoryTx) withTx(ctx context.Context, fn func(tx Stores) error) error {
+	// Transactions nest as savepoints, which already hold txMu
+	if !t.db.inTx {
+		t.db.state.txMu.Lock()
+		defer t.db.state.txMu.Unlock()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return &CanceledError{Err: err}
+	}
+
+	txDB := &memoryDB{state: t.db.state, inTx: true}
+
+	tables, unlock := txDB.lock()
+	saved := tables.clone()
+	unlock()
+
+	committed := false
+	defer func() {
+		if !committed {
+			tables, unlock := txDB.lock()
+			*tables = saved
+			unlock()
+		}
+	}()
+
+	if err := fn(txDB.stores()); err != nil {
+		return err
+	}
+	committed = true
+
+	return nil
+}
+
+func (t *memoryTables) newID() int64 {
+	t.nextID++
+
+	return t.nextID
+}
+
+func (t *memoryTables) clone() memoryTables {
+	c := *t
+
+	c.users = maps.Clone(t.users)
+	c.twoFactor = maps.Clone(t.twoFactor)
+	c.recoveryCodes = cloneNested(t.recoveryCodes)
+	c.workoutBackups = maps.Clone(t.workoutBackups)
+	c.feedback = slices.Clone(t.feedback)
+	c.refreshTokens = maps.Clone(t.refreshTokens)
+	c.revokedTokens = maps.Clone(t.revokedTokens)
+	c.revokedBefore = maps.Clone(t.revokedBefore)
+	c.userTokens = maps.Clone(t.userTokens)
+	c.credentials = maps.Clone(t.credentials)
+	c.webAuthnSessions = maps.Clone(t.webAuthnSessions)
+	c.identities = maps.Clone(t.identities)
+	c.apiKeys = maps.Clone(t.apiKeys)
+	c.sessions = maps.Clone(t.sessions)
+	c.roles = maps.Clone(t.roles)
+	c.rolePermissions = maps.Clone(t.rolePermissions)
+	c.userRoles = cloneNested(t.userRoles)
+	c.impersonationAudit = slices.Clone(t.impersonationAudit)
+	c.oauthClients = maps.Clone(t.oauthClients)
+	c.oauthCodes = maps.Clone(t.oauthCodes)
+	c.oauthConsents = maps.Clone(t.oauthConsents)
+
+	return c
+}
+
+func cloneNested[K comparable, K2 comparable, V any](m map[K]map[K2]V) map[K]map[K2]V {
+	c := make(map[K]map[K2]V, len(m))
+	for k, inner := range m {
+		c[k] = maps.Clone(inner)
+	}
+
+	return c
+}
+
+// deleteUser deletes the user's rows from the tables whose foreign keys cascade
+func (t *memoryTables) deleteUser(id int64) {
+	delete(t.users, id)
+	delete(t.twoFactor, id)
+	delete(t.recoveryCodes, id)
+	delete(t.workoutBackups, id)
+	delete(t.userRoles, id)
+
+	maps.DeleteFunc(t.refreshTokens, func(_ int64, r RefreshToken) bool { return r.UserID == id })
+	maps.DeleteFunc(t.userTokens, func(_ int64, r UserToken) bool { return r.UserID == id })
+	maps.DeleteFunc(t.credentials, func(_ int64, r UserCredential) bool { return r.UserID == id })
+	maps.DeleteFunc(t.webAuthnSessions, func(_ string, r WebAuthnSession) bool { return r.UserID.Valid && r.UserID.Int64 == id })
+	maps.DeleteFunc(t.identities, func(_ int64, r UserIdentity) bool { return r.UserID == id })
+	maps.DeleteFunc(t.apiKeys, func(_ int64, r ApiKey) bool { return r.UserID == id })
+	maps.DeleteFunc(t.sessions, func(_ string, r Session) bool { return r.UserID == id })
+	maps.DeleteFunc(t.oauthCodes, func(_ string, r oauthCode) bool { return r.UserID == id })
+	maps.DeleteFunc(t.oauthConsents, func(k oauthConsentKey, _ OauthConsent) bool { return k.userID == id })
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryWithTxRollsBack(t *testing.T) {
+	ctx := context.Background()
+	stores := NewMemoryStores()
+	errFailed := errors.New("failed")
+
+	err := stores.WithTx(ctx, func(tx Stores) error {
+		if _, err := tx.Users.Create(ctx, "user@example.com", "hash"); err != nil {
+			return err
+		}
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("WithTx = %v, want %v", err, errFailed)
+	}
+
+	if _, err := stores.Users.GetByUsername(ctx, "user@example.com"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetByUsername after rollback = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestMemoryWithTxRollbackKeepsOutsideWrites(t *testing.T) {
+	ctx := context.Background()
+	stores := NewMemoryStores()
+
+	written := make(chan error)
+	err := stores.WithTx(ctx, func(tx Stores) error {
+		// A write from another request while the transaction runs waits for it, rather than being undone by it
+		go func() {
+			_, err := stores.Users.Create(ctx, "outside@example.com", "hash")
+			written <- err
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		if _, err := tx.Users.Create(ctx, "inside@example.com", "hash"); err != nil {
+			return err
+		}
+		return errors.New("failed")
+	})
+	if err == nil {
+		t.Fatal("WithTx succeeded, want its error")
+	}
+	if err := <-written; err != nil {
+		t.Fatalf("writing outside the transaction: %v", err)
+	}
+
+	if _, err := stores.Users.GetByUsername(ctx, "outside@example.com"); err != nil {
+		t.Errorf("outside user after rollback: %v", err)
+	}
+	if _, err := stores.Users.GetByUsername(ctx, "inside@example.com"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("inside user = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestMemoryWithTxNestedRollbackKeepsOuter(t *testing.T) {
+	ctx := context.Background()
+	stores := NewMemoryStores()
+
+	err := stores.WithTx(ctx, func(tx Stores) error {
+		if _, err := tx.Users.Create(ctx, "outer@example.com", "hash"); err != nil {
+			return err
+		}
+
+		innerErr := tx.WithTx(ctx, func(tx Stores) error {
+			if _, err := tx.Users.Create(ctx, "inner@example.com", "hash"); err != nil {
+				return err
+			}
+			return errors.New("inner failed")
+		})
+		if innerErr == nil {
+			t.Error("inner WithTx succeeded, want its error")
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx = %v", err)
+	}
+
+	if _, err := stores.Users.GetByUsername(ctx, "outer@example.com"); err != nil {
+		t.Errorf("outer user: %v", err)
+	}
+	if _, err := stores.Users.GetByUsername(ctx, "inner@example.com"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("inner user = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestMemoryDeleteUserCascades(t *testing.T) {
+	ctx := context.Background()
+	stores := NewMemoryStores()
+
+	id, err := stores.Users.Create(ctx, "user@example.com", "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stores.Sessions.Create(ctx, "session", id, "test", "192.0.2.1", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stores.Roles.AssignToUser(ctx, id, "admin"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := stores.Users.Delete(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+
+	if revoked, _ := stores.Sessions.IsRevoked(ctx, "session"); !revoked {
+		t.Error("session still active after deleting its user")
+	}
+	if permissions, _ := stores.Roles.GetPermissionNamesForUser(ctx, id); len(permissions) != 0 {
+		t.Errorf("permissions = %v after deleting the user, want none", permissions)
+	}
+}
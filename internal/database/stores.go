@@ -0,0 +1,208 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// Stores is every store the application uses. New backs them with the database, where inside WithTx they are bound
+// to a transaction, and NewMemoryStores keeps them in memory so handlers can be tested without a database.
+type Stores struct {
+	Users              UserStore
+	WorkoutBackups     WorkoutBackupStore
+	Feedback           FeedbackStore
+	RefreshTokens      RefreshTokenStore
+	RevokedTokens      RevokedTokenStore
+	UserTokens         UserTokenStore
+	TwoFactor          TwoFactorStore
+	Credentials        UserCredentialStore
+	WebAuthnSessions   WebAuthnSessionStore
+	Identities         UserIdentityStore
+	ApiKeys            ApiKeyStore
+	Roles              RoleStore
+	Sessions           SessionStore
+	ImpersonationAudit ImpersonationAuditStore
+	OauthClients       OauthClientStore
+	OauthCodes         OauthAuthorizationCodeStore
+	OauthConsents      OauthConsentStore
+
+	tx transactor
+}
+
+// transactor runs a function with the stores bound to a transaction
+type transactor interface {
+	withTx(ctx context.Context, fn func(tx Stores) error) error
+}
+
+// UserStore reads and writes user accounts
+type UserStore interface {
+	FindUser(ctx context.Context, id int64) (User, error)
+	GetByUsername(ctx context.Context, username string) (User, error)
+	List(ctx context.Context, limit int, offset int) ([]User, error)
+	Count(ctx context.Context) (int, error)
+	Create(ctx context.Context, username string, passwordHash string) (int64, error)
+	UpdatePassword(ctx context.Context, id int64, passwordHash string) error
+	UpdateEmail(ctx context.Context, id int64, email string) error
+	MarkEmailVerified(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// WorkoutBackupStore records where each user's workout backup is kept
+type WorkoutBackupStore interface {
+	GetByUserId(ctx context.Context, userID int64) (UserWorkoutBackup, error)
+	CreateWorkoutBackup(ctx context.Context, userID int64, backupPath string) error
+	TouchWorkoutBackup(ctx context.Context, uwb *UserWorkoutBackup) error
+	DeleteForUser(ctx context.Context, userID int64) error
+}
+
+// FeedbackStore saves feedback sent in by users
+type FeedbackStore interface {
+	Save(ctx context.Context, name string, userID int64, feedbackType string, description string) error
+	DeleteForUser(ctx context.Context, userID int64) error
+}
+
+// RefreshTokenStore keeps the hashes of issued refresh tokens, for rotation and reuse detection
+type RefreshTokenStore interface {
+	Create(ctx context.Context, userID int64, familyID string, tokenHash string, rememberMe bool, expiresAt time.Time) error
+	GetByHash(ctx context.Context, tokenHash string) (RefreshToken, error)
+	MarkUsed(ctx context.Context, id int64) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeAllForUser(ctx context.Context, userID int64) error
+	RevokeAllForUserExcept(ctx context.Context, userID int64, familyID string) error
+}
+
+// RevokedTokenStore records access tokens revoked before they expire
+type RevokedTokenStore interface {
+	Revoke(ctx context.Context, jti string, userID int64, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	RevokeAllForUser(ctx context.Context, userID int64, before time.Time) error
+	GetRevokedBefore(ctx context.Context, userID int64) (time.Time, error)
+	DeleteExpired(ctx context.Context) error
+}
+
+// UserTokenStore keeps the single-use tokens emailed to users
+type UserTokenStore interface {
+	Create(ctx context.Context, userID int64, purpose string, tokenHash string, payload string, expiresAt time.Time) error
+	GetValid(ctx context.Context, purpose string, tokenHash string) (UserToken, error)
+	MarkUsed(ctx context.Context, id int64) (bool, error)
+	InvalidateForUser(ctx context.Context, userID int64, purpose string) error
+	CountCreatedSince(ctx context.Context, userID int64, purpose string, since time.Time) (int, error)
+}
+
+// TwoFactorStore keeps users' TOTP secrets and recovery codes
+type TwoFactorStore interface {
+	Get(ctx context.Context, userID int64) (TwoFactor, error)
+	SetPendingSecret(ctx context.Context, userID int64, secret string) error
+	Enable(ctx context.Context, userID int64, counter int64) error
+	Disable(ctx context.Context, userID int64) error
+	UseCounter(ctx context.Context, userID int64, counter int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error)
+}
+
+// UserCredentialStore keeps users' passkeys
+type UserCredentialStore interface {
+	Create(ctx context.Context, credential UserCredential) error
+	GetByUserId(ctx context.Context, userID int64) ([]UserCredential, error)
+	GetByCredentialId(ctx context.Context, credentialID []byte) (UserCredential, error)
+	RecordUse(ctx context.Context, id int64, signCount uint32, flags uint8) error
+	Delete(ctx context.Context, userID int64, id int64) (bool, error)
+}
+
+// WebAuthnSessionStore holds WebAuthn challenges between the begin and finish requests
+type WebAuthnSessionStore interface {
+	Create(ctx context.Context, tokenHash string, userID int64, ceremony string, data string, expiresAt time.Time) error
+	Consume(ctx context.Context, tokenHash string, ceremony string) (WebAuthnSession, error)
+	DeleteExpired(ctx context.Context) error
+}
+
+// UserIdentityStore links users to their OpenID Connect accounts
+type UserIdentityStore interface {
+	GetByProviderSubject(ctx context.Context, provider string, subject string) (UserIdentity, error)
+	Create(ctx context.Context, userID int64, provider string, subject string, email string) error
+	RecordLogin(ctx context.Context, id int64, email string) error
+}
+
+// ApiKeyStore keeps the hashes of users' API keys
+type ApiKeyStore interface {
+	Create(ctx context.Context, userID int64, name string, prefix string, keyHash string, expiresAt *time.Time) (int64, error)
+	GetByUserId(ctx context.Context, userID int64) ([]ApiKey, error)
+	GetValid(ctx context.Context, keyHash string) (ApiKey, error)
+	RecordUse(ctx context.Context, id int64) error
+	Delete(ctx context.Context, userID int64, id int64) (bool, error)
+}
+
+// RoleStore reads roles and their permissions, and assigns them to users
+type RoleStore interface {
+	List(ctx context.Context) ([]Role, error)
+	GetRoleNamesForUser(ctx context.Context, userID int64) ([]string, error)
+	GetPermissionNamesForUser(ctx context.Context, userID int64) ([]string, error)
+	AssignToUser(ctx context.Context, userID int64, roleName string) (bool, error)
+	RemoveFromUser(ctx context.Context, userID int64, roleName string) (bool, error)
+}
+
+// SessionStore keeps users' logins on each device
+type SessionStore interface {
+	Create(ctx context.Context, id string, userID int64, userAgent string, ipAddress string, expiresAt time.Time) error
+	GetActiveByUserId(ctx context.Context, userID int64) ([]Session, error)
+	Extend(ctx context.Context, id string, ipAddress string, expiresAt time.Time) (bool, error)
+	Touch(ctx context.Context, id string) error
+	IsRevoked(ctx context.Context, id string) (bool, error)
+	Revoke(ctx context.Context, userID int64, id string) (bool, error)
+	RevokeAllForUser(ctx context.Context, userID int64, exceptID string) error
+	DeleteExpired(ctx context.Context) error
+}
+
+// ImpersonationAuditStore keeps the impersonation audit log
+type ImpersonationAuditStore interface {
+	Create(ctx context.Context, entry ImpersonationAuditEntry) (int64, error)
+	SetStatusCode(ctx context.Context, id int64, statusCode int) error
+	List(ctx context.Context, userID int64, limit int, offset int) ([]ImpersonationAuditEntry, error)
+}
+
+// OauthClientStore keeps the third-party apps registered to use OAuth
+type OauthClientStore interface {
+	Create(ctx context.Context, id string, name string, secretHash string, redirectURIs []string, scopes []string, grantTypes []string, createdBy int64) error
+	List(ctx context.Context) ([]OauthClient, error)
+	GetActive(ctx context.Context, id string) (OauthClient, error)
+	Revoke(ctx context.Context, id string) (bool, error)
+}
+
+// OauthAuthorizationCodeStore keeps the codes issued by the authorization code flow
+type OauthAuthorizationCodeStore interface {
+	Create(ctx context.Context, code OauthAuthorizationCode) error
+	Redeem(ctx context.Context, codeHash string) (OauthAuthorizationCode, error)
+	DeleteExpired(ctx context.Context) error
+}
+
+// OauthConsentStore records the scopes users have allowed OAuth clients
+type OauthConsentStore interface {
+	GetScopes(ctx context.Context, userID int64, clientID string) ([]string, error)
+	Save(ctx context.Context, userID int64, clientID string, scopes []string) error
+	GetByUserId(ctx context.Context, userID int64) ([]OauthConsent, error)
+	Delete(ctx context.Context, userID int64, clientID string) (bool, error)
+}
+
+// newStores binds the database models to c, which is the connection pool or a transaction
+func newStores(c *conn) Stores {
+	return Stores{
+		Users:              &UserModel{c},
+		WorkoutBackups:     &UserWorkoutBackupModel{c},
+		Feedback:           &FeedbackModel{c},
+		RefreshTokens:      &RefreshTokenModel{c},
+		RevokedTokens:      &RevokedTokenModel{c},
+		UserTokens:         &UserTokenModel{c},
+		TwoFactor:          &TwoFactorModel{c},
+		Credentials:        &UserCredentialModel{c},
+		WebAuthnSessions:   &WebAuthnSessionModel{c},
+		Identities:         &UserIdentityModel{c},
+		ApiKeys:            &ApiKeyModel{c},
+		Roles:              &RoleModel{c},
+		Sessions:           &SessionModel{c},
+		ImpersonationAudit: &ImpersonationAuditModel{c},
+		OauthClients:       &OauthClientModel{c},
+		OauthCodes:         &OauthAuthorizationCodeModel{c},
+		OauthConsents:      &OauthConsentModel{c},
+		tx:                 c,
+	}
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// txAttempts is how many times WithTx runs a transaction that keeps failing with a deadlock or serialization error
+	txAttempts     = 3
+	txRetryBackoff = 25 * time.Millisecond
+)
+
+// WithTx runs fn in a transaction, committing it if fn returns nil and rolling it back if fn returns an error or
+// panics. Only the queries made through tx are part of the transaction: one made outside it doesn't see fn's changes
+// until the commit, and one that writes rows fn has written waits for the transaction to end, or on SQLite fails with
+// a busy error after the busy timeout. An in-memory SQLite database has only the connection fn is using, so there any
+// query made outside tx waits for a connection until it times out.
+//
+// If the database aborts the transaction to resolve a deadlock or serialization failure with another, fn is run again
+// in a new transaction, up to txAttempts times in all. It must not have effects outside the database that would be
+// wrong to repeat.
+//
+// Called on the Stores given to fn, WithTx nests a savepoint instead: an error from the inner fn rolls back only its
+// queries, and is returned for the outer fn to handle. Retrying happens only at the outermost level, as a deadlock
+// aborts the whole transaction.
+//
+// The memory stores have no deadlocks to retry, and run one transaction at a time.
+func (s Stores) WithTx(ctx context.Context, fn func(tx Stores) error) error {
+	return s.tx.withTx(ctx, fn)
+}
+
+func (c *conn) withTx(ctx context.Context, fn func(tx Stores) error) error {
+	if c.tx != nil {
+		return c.savepoint(ctx, fn)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := c.transaction(ctx, fn)
+		if err == nil || attempt == txAttempts || !isRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return &CanceledError{Err: ctx.Err()}
+		case <-time.After(time.Duration(attempt) * txRetryBackoff):
+		}
+	}
+}
+
+func (c *conn) transaction(ctx context.Context, fn func(tx Stores) error) error {
+	// The transaction is rolled back if ctx ends, so each query's timeout must not be applied to it
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return canceled(ctx, err)
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+
+	if err := fn(newStores(&conn{db: c.db, q: tx, tx: tx, dialect: c.dialect, timeout: c.timeout})); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return canceled(ctx, err)
+	}
+	committed = true
+
+	return nil
+}
+
+// savepoint runs fn inside a savepoint of the conn's transaction, named after how deeply it is nested
+func (c *conn) savepoint(ctx context.Context, fn func(tx Stores) error) error {
+	inner := *c
+	inner.depth++
+	name := fmt.Sprintf("sp_%d", inner.depth)
+
+	if _, err := c.Exec(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	if err := fn(newStores(&inner)); err != nil {
+		if _, rollbackErr := c.Exec(ctx, "ROLLBACK TO SAVEPOINT "+name); rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+		return err
+	}
+
+	_, err := c.Exec(ctx, "RELEASE SAVEPOINT "+name)
+
+	return err
+}
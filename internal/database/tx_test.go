@@ -0,0 +1,193 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+)
+
+// newSQLiteDB migrates a new SQLite database file for the test
+func newSQLiteDB(t *testing.T) *database.DB {
+	t.Helper()
+
+	dsn := filepath.Join(t.TempDir(), "test.db")
+
+	m, closeMigrator, err := application.NewMigrator(database.DialectSQLite, dsn, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeMigrator()
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+
+	db, err := database.New(database.DialectSQLite, dsn, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func userExists(t *testing.T, db *database.DB, username string) bool {
+	t.Helper()
+
+	_, err := db.Users.GetByUsername(context.Background(), username)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		t.Fatal(err)
+	}
+
+	return err == nil
+}
+
+func TestWithTxCommits(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+
+	err := db.WithTx(ctx, func(tx database.Stores) error {
+		_, err := tx.Users.Create(ctx, "user@example.com", "hash")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTx = %v", err)
+	}
+
+	if !userExists(t, db, "user@example.com") {
+		t.Error("user not committed")
+	}
+}
+
+func TestWithTxRollsBackOnError(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+	errFailed := errors.New("failed")
+
+	err := db.WithTx(ctx, func(tx database.Stores) error {
+		if _, err := tx.Users.Create(ctx, "user@example.com", "hash"); err != nil {
+			return err
+		}
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("WithTx = %v, want %v", err, errFailed)
+	}
+
+	if userExists(t, db, "user@example.com") {
+		t.Error("user committed despite the error")
+	}
+}
+
+func TestWithTxRetriesConflicts(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+
+	attempts := 0
+	err := db.WithTx(ctx, func(tx database.Stores) error {
+		attempts++
+
+		// Reading starts the transaction's snapshot. Another connection then writes, so the first attempt's own
+		// write conflicts with it and the transaction has to start again.
+		if _, err := tx.Users.GetByUsername(ctx, "other@example.com"); !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if attempts == 1 {
+			if _, err := db.Users.Create(ctx, "outside@example.com", "hash"); err != nil {
+				t.Fatalf("writing outside the transaction: %v", err)
+			}
+		}
+
+		_, err := tx.Users.Create(ctx, "inside@example.com", "hash")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTx = %v", err)
+	}
+
+	if attempts != 2 {
+		t.Errorf("ran %d times, want 2", attempts)
+	}
+	if !userExists(t, db, "inside@example.com") || !userExists(t, db, "outside@example.com") {
+		t.Error("users not committed")
+	}
+}
+
+func TestWithTxDoesNotRetryOtherErrors(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+
+	attempts := 0
+	err := db.WithTx(ctx, func(tx database.Stores) error {
+		attempts++
+		return errors.New("failed")
+	})
+	if err == nil || attempts != 1 {
+		t.Errorf("WithTx = %v after %d attempts, want an error after 1", err, attempts)
+	}
+}
+
+func TestWithTxSavepointRollbackKeepsOuter(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+
+	err := db.WithTx(ctx, func(tx database.Stores) error {
+		if _, err := tx.Users.Create(ctx, "outer@example.com", "hash"); err != nil {
+			return err
+		}
+
+		innerErr := tx.WithTx(ctx, func(tx database.Stores) error {
+			if _, err := tx.Users.Create(ctx, "inner@example.com", "hash"); err != nil {
+				return err
+			}
+			return errors.New("inner failed")
+		})
+		if innerErr == nil {
+			t.Error("inner WithTx succeeded, want its error")
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx = %v", err)
+	}
+
+	if !userExists(t, db, "outer@example.com") {
+		t.Error("outer user not committed")
+	}
+	if userExists(t, db, "inner@example.com") {
+		t.Error("inner user committed despite its savepoint rolling back")
+	}
+}
+
+func TestQueryOutsideWithTxSeesCommittedRows(t *testing.T) {
+	ctx := context.Background()
+	db := newSQLiteDB(t)
+
+	err := db.WithTx(ctx, func(tx database.Stores) error {
+		if _, err := tx.Users.Create(ctx, "user@example.com", "hash"); err != nil {
+			return err
+		}
+
+		// Before the commit, other connections don't see the row, and aren't blocked by the transaction
+		if userExists(t, db, "user@example.com") {
+			t.Error("uncommitted user visible outside the transaction")
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx = %v", err)
+	}
+
+	if !userExists(t, db, "user@example.com") {
+		t.Error("user not committed")
+	}
+}
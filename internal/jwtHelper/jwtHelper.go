@@ -2,38 +2,43 @@ package jwtHelper
 
 import (
 	"crypto/rsa"
-	_ "embed"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-//go:embed public-key.pem
-var publicKey []byte
-
 // jwtHelper now receives a pointer to the Application struct
 type JWTService struct { // No interface, just a struct
-	privateKey *rsa.PrivateKey
-	publicKey  *rsa.PublicKey
+	keyring         *Keyring
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+	rememberMeTTL   time.Duration
 }
 
-func NewJWTService(secretKey string) (*JWTService, error) {
-	privateKey, err := parsePrivateKey(secretKey)
-	if err != nil {
-		return nil, err
-	}
+const (
+	AccessCookieName  = "jwt"
+	RefreshCookieName = "refresh_token"
+)
 
-	pubKey, err := jwt.ParseRSAPublicKeyFromPEM(publicKey)
-	if err != nil {
-		return nil, fmt.Errorf("error parsing public key: %v", err)
+func NewJWTService(keyring *Keyring, accessTokenTTL, refreshTokenTTL, rememberMeTTL time.Duration) (*JWTService, error) {
+	if keyring == nil || keyring.SigningKey() == nil {
+		return nil, errors.New("JWT keyring has no signing key")
 	}
 
-	return &JWTService{privateKey: privateKey, publicKey: pubKey}, nil
+	return &JWTService{
+		keyring:         keyring,
+		accessTokenTTL:  accessTokenTTL,
+		refreshTokenTTL: refreshTokenTTL,
+		rememberMeTTL:   rememberMeTTL,
+	}, nil
 }
 
 // Load RSA private key from an environment variable
@@ -59,70 +64,179 @@ func parsePrivateKey(pemKey string) (*rsa.PrivateKey, error) {
 	return privKey, nil
 }
 
-func (h *JWTService) CreateJwtCookie(userId int64, username string, rememberMe bool) (*http.Cookie, error) {
-	daysToExpire := 1
-	if rememberMe {
-		daysToExpire = 28
-	}
-
-	expiry := time.Now().Add(time.Hour * 24 * time.Duration(daysToExpire))
-
-	jwtToken, err := h.createSignedJWT(userId, username, expiry)
+// CreateJwtCookie creates a short-lived access token cookie. Longer sessions are kept alive by the refresh token.
+func (h *JWTService) CreateJwtCookie(userId int64, username string, sessionId string, rememberMe bool) (*http.Cookie, error) {
+	jwtToken, expiry, err := h.CreateAccessToken(userId, username, sessionId)
 
 	if err != nil {
 		return nil, err
 	}
 
-	cookie, err := h.createCookieFromJWT(jwtToken, rememberMe, expiry)
+	return h.CreateCookieFromToken(jwtToken, rememberMe, expiry)
+}
 
+// CreateAccessToken creates a signed, short-lived access token for the given session, for clients that send it as a Bearer token
+func (h *JWTService) CreateAccessToken(userId int64, username string, sessionId string) (string, time.Time, error) {
+	expiry := time.Now().Add(h.accessTokenTTL)
+
+	jwtToken, err := h.createSignedJWT(userId, username, sessionId, expiry)
 	if err != nil {
-		return nil, err
+		return "", time.Time{}, err
 	}
 
-	return cookie, nil
+	return jwtToken, expiry, nil
+}
+
+// CreateCookieFromToken wraps an access token created by CreateAccessToken in the jwt cookie
+func (h *JWTService) CreateCookieFromToken(jwtToken string, rememberMe bool, expiry time.Time) (*http.Cookie, error) {
+	return h.createCookieFromJWT(AccessCookieName, jwtToken, rememberMe, expiry)
 }
 
 func (h *JWTService) ClearCookie() (*http.Cookie, error) {
-	return h.createCookieFromJWT("", false, time.Now())
+	return h.createCookieFromJWT(AccessCookieName, "", false, time.Now())
 }
 
-func (h *JWTService) VerifyJWT(signedToken string) error {
-	// Parse the public key
-	pubKey, err := jwt.ParseRSAPublicKeyFromPEM(publicKey)
-	if err != nil {
-		return fmt.Errorf("error parsing public key: %v", err)
+// RefreshTokenExpiry returns when a refresh token issued now should expire
+func (h *JWTService) RefreshTokenExpiry(rememberMe bool) time.Time {
+	if rememberMe {
+		return time.Now().Add(h.rememberMeTTL)
 	}
+	return time.Now().Add(h.refreshTokenTTL)
+}
 
-	// Verify the token
-	token, err := jwt.Parse(signedToken, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return pubKey, nil
-	})
-	if err != nil {
-		return fmt.Errorf("error verifying token: %v", err)
-	}
-	if !token.Valid {
-		return errors.New("token is invalid")
-	}
-	return nil
+// CreateRefreshCookie wraps an opaque refresh token in a cookie. Without rememberMe it is a session cookie.
+func (h *JWTService) CreateRefreshCookie(refreshToken string, rememberMe bool, expiry time.Time) (*http.Cookie, error) {
+	return h.createCookieFromJWT(RefreshCookieName, refreshToken, rememberMe, expiry)
+}
+
+func (h *JWTService) ClearRefreshCookie() (*http.Cookie, error) {
+	return h.createCookieFromJWT(RefreshCookieName, "", false, time.Now())
+}
+
+func (h *JWTService) VerifyJWT(signedToken string) error {
+	_, err := h.ParseAndVerifyJWT(signedToken)
+	return err
+}
+
+// JWKS returns the public keys tokens can be verified with
+func (h *JWTService) JWKS() JWKS {
+	return h.keyring.JWKS()
 }
 
 // Function to create and sign a JWT
-func (h *JWTService) createSignedJWT(userId int64, username string, expiry time.Time) (string, error) {
+func (h *JWTService) createSignedJWT(userId int64, username string, sessionId string, expiry time.Time) (string, error) {
 	// Create a new token object with claims
 	claims := jwt.MapClaims{
+		"jti":      uuid.NewString(),
+		"sid":      sessionId,
 		"userId":   userId,
 		"username": username,
+		"iat":      time.Now().Unix(),
 		"exp":      expiry.Unix(),
 	}
 
+	return h.signClaims(claims)
+}
+
+// CreateImpersonationToken creates an access token for the user that records who is really acting in an RFC 8693
+// "act" claim. It belongs to no session, so it cannot be refreshed and stops working at expiry.
+func (h *JWTService) CreateImpersonationToken(userId int64, username string, impersonatorId int64, ttl time.Duration) (string, *TokenClaims, error) {
+	now := time.Now()
+	tokenClaims := &TokenClaims{
+		ID:             uuid.NewString(),
+		UserID:         userId,
+		ImpersonatorID: impersonatorId,
+		IssuedAt:       now.Truncate(time.Second),
+		ExpiresAt:      now.Add(ttl).Truncate(time.Second),
+	}
+
+	signedToken, err := h.signClaims(jwt.MapClaims{
+		"jti":      tokenClaims.ID,
+		"userId":   userId,
+		"username": username,
+		"act":      map[string]interface{}{"sub": strconv.FormatInt(impersonatorId, 10)},
+		"iat":      tokenClaims.IssuedAt.Unix(),
+		"exp":      tokenClaims.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return signedToken, tokenClaims, nil
+}
+
+// CreateOauthAccessToken creates an access token for a third-party OAuth client, limited to the granted scopes.
+// userId is 0 for the client credentials grant, where the client acts as itself rather than for a user.
+func (h *JWTService) CreateOauthAccessToken(userId int64, username string, clientId string, scopes []string, ttl time.Duration) (string, *TokenClaims, error) {
+	now := time.Now()
+	tokenClaims := &TokenClaims{
+		ID:        uuid.NewString(),
+		UserID:    userId,
+		ClientID:  clientId,
+		Scopes:    scopes,
+		IssuedAt:  now.Truncate(time.Second),
+		ExpiresAt: now.Add(ttl).Truncate(time.Second),
+	}
+
+	claims := jwt.MapClaims{
+		"jti":       tokenClaims.ID,
+		"client_id": clientId,
+		"scope":     strings.Join(scopes, " "),
+		"iat":       tokenClaims.IssuedAt.Unix(),
+		"exp":       tokenClaims.ExpiresAt.Unix(),
+	}
+	if userId != 0 {
+		claims["userId"] = userId
+		claims["username"] = username
+	}
+
+	signedToken, err := h.signClaims(claims)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return signedToken, tokenClaims, nil
+}
+
+// CreatePurposeToken creates a short-lived token that is only accepted by ParsePurposeToken for the same purpose,
+// e.g. the second step of a login. Purpose tokens are never accepted as access tokens.
+func (h *JWTService) CreatePurposeToken(purpose string, claims map[string]interface{}, ttl time.Duration) (string, error) {
+	mapClaims := jwt.MapClaims{}
+	for key, value := range claims {
+		mapClaims[key] = value
+	}
+
+	mapClaims["jti"] = uuid.NewString()
+	mapClaims["purpose"] = purpose
+	mapClaims["iat"] = time.Now().Unix()
+	mapClaims["exp"] = time.Now().Add(ttl).Unix()
+
+	return h.signClaims(mapClaims)
+}
+
+// ParsePurposeToken verifies a token created by CreatePurposeToken, checking it was issued for the given purpose
+func (h *JWTService) ParsePurposeToken(token string, purpose string) (jwt.MapClaims, error) {
+	parsedToken, err := h.ParseAndVerifyJWT(token)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := parsedToken.Claims.(jwt.MapClaims)
+	if tokenPurpose, _ := claims["purpose"].(string); tokenPurpose != purpose {
+		return nil, errors.New("invalid token")
+	}
+
+	return claims, nil
+}
+
+func (h *JWTService) signClaims(claims jwt.MapClaims) (string, error) {
 	// Create token with expiry:
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
 
-	// Sign the token with the private key
-	signedToken, err := token.SignedString(h.privateKey)
+	// Sign the token with the current signing key, recording which key that was
+	signingKey := h.keyring.SigningKey()
+	token.Header["kid"] = signingKey.ID
+	signedToken, err := token.SignedString(signingKey.PrivateKey)
 	if err != nil {
 		return "", fmt.Errorf("error signing the token: %v", err)
 	}
@@ -130,13 +244,13 @@ func (h *JWTService) createSignedJWT(userId int64, username string, expiry time.
 	return signedToken, nil
 }
 
-func (h *JWTService) createCookieFromJWT(signedToken string, rememberMe bool, expiry time.Time) (*http.Cookie, error) {
+func (h *JWTService) createCookieFromJWT(name string, signedToken string, rememberMe bool, expiry time.Time) (*http.Cookie, error) {
 
 	isDev := os.Getenv("ENV") == "local"
 
 	// Create the JWT cookie
 	jwtCookie := &http.Cookie{
-		Name:     "jwt",
+		Name:     name,
 		Value:    signedToken,
 		Path:     "/",
 		HttpOnly: true,
@@ -157,7 +271,7 @@ func (h *JWTService) createCookieFromJWT(signedToken string, rememberMe bool, ex
 	return jwtCookie, nil
 }
 
-// ParseAndVerifyJWT parses a JWT token and verifies it with the private key stored in the environment
+// ParseAndVerifyJWT parses a JWT token and verifies it against the key named by its kid header
 func (h *JWTService) ParseAndVerifyJWT(token string) (*jwt.Token, error) {
 
 	// Parse the JWT token
@@ -165,7 +279,18 @@ func (h *JWTService) ParseAndVerifyJWT(token string) (*jwt.Token, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return h.privateKey.Public(), nil
+
+		// Tokens issued before kid headers were added can only have been signed by the signing key
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return h.keyring.SigningKey().PublicKey, nil
+		}
+
+		key, ok := h.keyring.Lookup(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key: %s", kid)
+		}
+		return key.PublicKey, nil
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error parsing JWT: %v", err)
@@ -176,19 +301,110 @@ func (h *JWTService) ParseAndVerifyJWT(token string) (*jwt.Token, error) {
 	return parsedToken, nil
 }
 
+// BearerToken returns the token from the request's Authorization header, if it uses the Bearer scheme
+func BearerToken(r *http.Request) (string, bool) {
+	scheme, value, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	return strings.TrimSpace(value), true
+}
+
+// TokenFromRequest returns the access token sent with the request, preferring an Authorization: Bearer
+// header over the jwt cookie. fromHeader reports whether the token came from the header.
+func TokenFromRequest(r *http.Request) (token string, fromHeader bool) {
+	if token, ok := BearerToken(r); ok {
+		return token, true
+	}
+
+	cookie, err := r.Cookie(AccessCookieName)
+	if err != nil {
+		return "", false
+	}
+
+	return cookie.Value, false
+}
+
+// TokenClaims holds the claims of a verified access token that the rest of the app cares about
+type TokenClaims struct {
+	ID string
+	// SessionID is empty for tokens that belong to no session, such as impersonation and OAuth tokens
+	SessionID string
+	UserID    int64
+	// ImpersonatorID is the admin acting as UserID, or 0 for a normal token
+	ImpersonatorID int64
+	// ClientID is the third-party OAuth client the token was issued to, limited to Scopes. It is empty for our own
+	// tokens, which have every scope. UserID is 0 for tokens a client was issued for itself.
+	ClientID  string
+	Scopes    []string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
 // GetUserIdFromJWT retrieves the userId from a JWT token, using the ParseAndVerifyJWT function
 func (h *JWTService) GetUserIdFromJWT(token string) (int64, error) {
+	claims, err := h.GetClaimsFromJWT(token)
+	if err != nil {
+		return 0, err
+	}
+	if claims.UserID == 0 {
+		return 0, errors.New("token was not issued to a user")
+	}
+	return claims.UserID, nil
+}
+
+// GetClaimsFromJWT verifies a JWT token and extracts its claims
+func (h *JWTService) GetClaimsFromJWT(token string) (*TokenClaims, error) {
 	// Parse the JWT token
 	parsedToken, err := h.ParseAndVerifyJWT(token)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	claims := parsedToken.Claims.(jwt.MapClaims)
-	userId := claims["userId"].(float64)
+	userId, _ := claims["userId"].(float64)
+	jti, _ := claims["jti"].(string)
+	sid, _ := claims["sid"].(string)
+	clientId, _ := claims["client_id"].(string)
+	scope, _ := claims["scope"].(string)
 
-	if userId == 0 {
-		return 0, errors.New("invalid token")
+	if (userId == 0 && clientId == "") || jti == "" {
+		return nil, errors.New("invalid token")
 	}
-	return int64(userId), nil
+
+	// Purpose tokens are signed by the same keys but must never work as access tokens
+	if _, hasPurpose := claims["purpose"]; hasPurpose {
+		return nil, errors.New("invalid token")
+	}
+
+	issuedAt, err := claims.GetIssuedAt()
+	if err != nil || issuedAt == nil {
+		return nil, errors.New("invalid token")
+	}
+
+	expiresAt, err := claims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return nil, errors.New("invalid token")
+	}
+
+	var impersonatorId int64
+	if act, ok := claims["act"].(map[string]interface{}); ok {
+		actorSubject, _ := act["sub"].(string)
+		impersonatorId, err = strconv.ParseInt(actorSubject, 10, 64)
+		if err != nil || impersonatorId == 0 {
+			return nil, errors.New("invalid token")
+		}
+	}
+
+	return &TokenClaims{
+		ID:             jti,
+		SessionID:      sid,
+		UserID:         int64(userId),
+		ImpersonatorID: impersonatorId,
+		ClientID:       clientId,
+		Scopes:         strings.Fields(scope),
+		IssuedAt:       issuedAt.Time,
+		ExpiresAt:      expiresAt.Time,
+	}, nil
 }
@@ -0,0 +1,170 @@
+package jwtHelper
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key is a single RSA key in the keyring. Retired keys only have a public key, so they can
+// still verify tokens issued before a rotation but can no longer sign new ones.
+type Key struct {
+	ID         string
+	PrivateKey *rsa.PrivateKey
+	PublicKey  *rsa.PublicKey
+}
+
+// Keyring holds every key tokens may be verified against, plus the one used to sign new tokens
+type Keyring struct {
+	signingKey *Key
+	keys       map[string]*Key
+}
+
+// JWK is the JSON Web Key representation of a public RSA key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// LoadKeyring builds the keyring from a directory of PEM files, falling back to the single
+// RSA_PRIVATE_KEY value when no directory is configured.
+//
+// In the directory, "<kid>.pem" files are private keys and "<kid>.pub.pem" files are public keys of
+// retired keys. signingKeyId picks the key used for signing, and may be empty if there is only one private key.
+func LoadKeyring(keysDir string, signingKeyId string, legacyPrivateKey string) (*Keyring, error) {
+	if keysDir == "" {
+		privateKey, err := parsePrivateKey(legacyPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+
+		key := &Key{ID: thumbprint(&privateKey.PublicKey), PrivateKey: privateKey, PublicKey: &privateKey.PublicKey}
+		return &Keyring{signingKey: key, keys: map[string]*Key{key.ID: key}}, nil
+	}
+
+	entries, err := os.ReadDir(keysDir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading JWT keys directory: %v", err)
+	}
+
+	keyring := &Keyring{keys: map[string]*Key{}}
+	privateKeyIds := []string{}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".pem") {
+			continue
+		}
+
+		contents, err := os.ReadFile(filepath.Join(keysDir, name))
+		if err != nil {
+			return nil, fmt.Errorf("error reading JWT key %s: %v", name, err)
+		}
+
+		if kid, ok := strings.CutSuffix(name, ".pub.pem"); ok {
+			publicKey, err := jwt.ParseRSAPublicKeyFromPEM(contents)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing public key %s: %v", name, err)
+			}
+			// A private key with the same kid takes precedence
+			if _, exists := keyring.keys[kid]; !exists {
+				keyring.keys[kid] = &Key{ID: kid, PublicKey: publicKey}
+			}
+			continue
+		}
+
+		kid := strings.TrimSuffix(name, ".pem")
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(contents)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing private key %s: %v", name, err)
+		}
+		keyring.keys[kid] = &Key{ID: kid, PrivateKey: privateKey, PublicKey: &privateKey.PublicKey}
+		privateKeyIds = append(privateKeyIds, kid)
+	}
+
+	if signingKeyId == "" {
+		if len(privateKeyIds) != 1 {
+			return nil, errors.New("JWT_SIGNING_KEY_ID must be set when the keys directory does not contain exactly one private key")
+		}
+		signingKeyId = privateKeyIds[0]
+	}
+
+	signingKey, ok := keyring.keys[signingKeyId]
+	if !ok || signingKey.PrivateKey == nil {
+		return nil, fmt.Errorf("no private key found for signing key id %q", signingKeyId)
+	}
+	keyring.signingKey = signingKey
+
+	return keyring, nil
+}
+
+// Lookup returns the key with the given kid, if it is in the keyring
+func (k *Keyring) Lookup(kid string) (*Key, bool) {
+	key, ok := k.keys[kid]
+	return key, ok
+}
+
+// SigningKey returns the key new tokens are signed with
+func (k *Keyring) SigningKey() *Key {
+	return k.signingKey
+}
+
+// JWKS returns the public half of every key, ready to be served from /.well-known/jwks.json
+func (k *Keyring) JWKS() JWKS {
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	jwks := JWKS{Keys: []JWK{}}
+	for _, id := range ids {
+		jwks.Keys = append(jwks.Keys, toJWK(id, k.keys[id].PublicKey))
+	}
+
+	return jwks
+}
+
+func toJWK(kid string, publicKey *rsa.PublicKey) JWK {
+	return JWK{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: jwt.SigningMethodRS256.Alg(),
+		Kid: kid,
+		N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+	}
+}
+
+// thumbprint derives a stable key id from the public key, as described in RFC 7638
+func thumbprint(publicKey *rsa.PublicKey) string {
+	jwk := toJWK("", publicKey)
+
+	// The members must be in lexicographic order with no whitespace
+	canonical, _ := json.Marshal(struct {
+		E   string `json:"e"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+	}{E: jwk.E, Kty: jwk.Kty, N: jwk.N})
+
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,63 @@
+package mailer
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends plain text emails. SMTPMailer is for real environments; LogMailer and FileMailer
+// are for local development and tests, where nothing should actually be sent.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+type Config struct {
+	Driver   string
+	From     string
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Dir      string
+}
+
+// New creates the mailer selected by cfg.Driver, which is one of smtp, file or log
+func New(cfg Config, logger *slog.Logger) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.From), nil
+	case "file":
+		return NewFileMailer(cfg.Dir, cfg.From)
+	case "log", "":
+		return NewLogMailer(logger), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %s", cfg.Driver)
+	}
+}
+
+// stripNewlines stops header values from injecting extra headers
+var stripNewlines = strings.NewReplacer("\r", "", "\n", "")
+
+// format renders the message as an RFC 5322 email
+func format(from string, msg Message) []byte {
+	var b strings.Builder
+
+	b.WriteString("From: " + stripNewlines.Replace(from) + "\r\n")
+	b.WriteString("To: " + stripNewlines.Replace(msg.To) + "\r\n")
+	b.WriteString("Subject: " + stripNewlines.Replace(msg.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return []byte(b.String())
+}
@@ -0,0 +1,91 @@
+package mailer
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// smtpTimeout bounds connecting to the server and the whole conversation after that, so a server that stops
+// responding can't hold up the sender forever
+const smtpTimeout = 30 * time.Second
+
+type SMTPMailer struct {
+	host    string
+	addr    string
+	auth    smtp.Auth
+	from    string
+	timeout time.Duration
+}
+
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPMailer{
+		host:    host,
+		addr:    fmt.Sprintf("%s:%d", host, port),
+		auth:    auth,
+		from:    from,
+		timeout: smtpTimeout,
+	}
+}
+
+// Send delivers the message, upgrading the connection with STARTTLS when the server supports it. It does what
+// smtp.SendMail does, on a connection with a deadline.
+func (m *SMTPMailer) Send(msg Message) error {
+	conn, err := (&net.Dialer{Timeout: m.timeout}).Dial("tcp", m.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(m.timeout)); err != nil {
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return err
+		}
+	}
+
+	if m.auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return fmt.Errorf("smtp: server doesn't support AUTH")
+		}
+		if err := c.Auth(m.auth); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(stripNewlines.Replace(m.from)); err != nil {
+		return err
+	}
+	if err := c.Rcpt(stripNewlines.Replace(msg.To)); err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(format(m.from, msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestSMTPMailerTimesOut(t *testing.T) {
+	// A server that accepts the connection and then never says anything
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	m := NewSMTPMailer("127.0.0.1", listener.Addr().(*net.TCPAddr).Port, "", "", "no-reply@example.com")
+	m.timeout = 100 * time.Millisecond
+
+	sent := make(chan error, 1)
+	go func() {
+		sent <- m.Send(Message{To: "user@example.com", Subject: "Subject", Body: "Body"})
+	}()
+
+	select {
+	case err := <-sent:
+		if err == nil {
+			t.Error("Send succeeded without a server")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Send didn't time out")
+	}
+}
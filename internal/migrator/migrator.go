@@ -0,0 +1,371 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nathanjms/go-api-template/internal/database"
+)
+
+const (
+	lockName = "schema_migrations"
+	// lockKey identifies the PostgreSQL advisory lock, which is a number rather than a name
+	lockKey     = 7_355_608
+	lockTimeout = time.Minute
+)
+
+// Migrator applies migrations to a database, recording each one in the schema_migrations table. On PostgreSQL and
+// SQLite each migration runs in a transaction. MySQL commits DDL implicitly, so there a migration that fails part way
+// through is not rolled back; it stays unrecorded and the database needs fixing by hand before it can be run again.
+type Migrator struct {
+	db         *sql.DB
+	dialect    database.Dialect
+	logger     *slog.Logger
+	migrations []Migration
+}
+
+// Status is a migration and whether it has been applied. Modified migrations have been edited since they
+// were applied, and missing ones were applied but no longer have a file.
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"appliedAt"`
+	Modified  bool       `json:"modified"`
+	Missing   bool       `json:"missing"`
+}
+
+type appliedMigration struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// New loads the migrations in fsys, which must be written for the dialect. db must allow multiple statements
+// per query, see database.OpenForMigrations.
+func New(db *sql.DB, dialect database.Dialect, fsys fs.FS, logger *slog.Logger) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, dialect: dialect, logger: logger, migrations: migrations}, nil
+}
+
+// Up applies every pending migration in order and returns the ones it applied. It refuses to run if an applied
+// migration has been edited or deleted, or if a pending migration is older than the latest applied one.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	conn, unlock, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.verify(applied); err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		m.logger.Info("Applying migration", "version", migration.Version, "name", migration.Name)
+		err := m.run(ctx, conn, migration.Up, "INSERT INTO schema_migrations (version, name, checksum) VALUES (?, ?, ?)", migration.Version, migration.Name, migration.Checksum)
+		if err != nil {
+			return ran, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		ran = append(ran, migration)
+	}
+
+	return ran, nil
+}
+
+// Down rolls back the latest steps applied migrations, newest first, and returns the ones it rolled back
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	conn, unlock, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.verify(applied); err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(ran) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		if strings.TrimSpace(migration.Down) == "" {
+			return ran, fmt.Errorf("migration %d_%s has no down migration", migration.Version, migration.Name)
+		}
+
+		m.logger.Info("Rolling back migration", "version", migration.Version, "name", migration.Name)
+		if err := m.run(ctx, conn, migration.Down, "DELETE FROM schema_migrations WHERE version = ?", migration.Version); err != nil {
+			return ran, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		ran = append(ran, migration)
+	}
+
+	return ran, nil
+}
+
+// Baseline records every migration up to and including version as applied without running it, for databases
+// that were set up by applying the SQL files by hand
+func (m *Migrator) Baseline(ctx context.Context, version int64) ([]Migration, error) {
+	conn, unlock, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var recorded []Migration
+	for _, migration := range m.migrations {
+		if migration.Version > version {
+			break
+		}
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		if _, err := conn.ExecContext(ctx, m.dialect.Rebind("INSERT INTO schema_migrations (version, name, checksum) VALUES (?, ?, ?)"), migration.Version, migration.Name, migration.Checksum); err != nil {
+			return recorded, err
+		}
+
+		recorded = append(recorded, migration)
+	}
+
+	return recorded, nil
+}
+
+// Status lists every migration, along with any that were applied but no longer have a file, ordered by version
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &row.AppliedAt
+			status.Modified = row.Checksum != migration.Checksum
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, row := range applied {
+		statuses = append(statuses, Status{Version: row.Version, Name: row.Name, AppliedAt: &row.AppliedAt, Missing: true})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// verify checks the applied migrations still match the files, and that no pending migration would run out of order
+func (m *Migrator) verify(applied map[int64]appliedMigration) error {
+	var latest int64 = -1
+	for version := range applied {
+		latest = max(latest, version)
+	}
+
+	known := map[int64]bool{}
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+
+		row, ok := applied[migration.Version]
+		if !ok {
+			if migration.Version < latest {
+				return fmt.Errorf("migration %d_%s is pending but the newer migration %d is already applied", migration.Version, migration.Name, latest)
+			}
+			continue
+		}
+
+		if row.Checksum != migration.Checksum {
+			return fmt.Errorf("migration %d_%s has been modified since it was applied", migration.Version, migration.Name)
+		}
+	}
+
+	for version, row := range applied {
+		if !known[version] {
+			return fmt.Errorf("migration %d_%s was applied but its file is missing", version, row.Name)
+		}
+	}
+
+	return nil
+}
+
+// applied creates the schema_migrations table if needed and returns the migrations recorded in it
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int64]appliedMigration, error) {
+	// Written to work unchanged on every dialect
+	_, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations ("+
+		"version bigint NOT NULL, "+
+		"name varchar(255) NOT NULL, "+
+		"checksum char(64) NOT NULL, "+
+		"applied_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP, "+
+		"PRIMARY KEY (version))")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]appliedMigration{}
+	for rows.Next() {
+		var row appliedMigration
+		if err := rows.Scan(&row.Version, &row.Name, &row.Checksum, &row.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied[row.Version] = row
+	}
+
+	return applied, rows.Err()
+}
+
+// lock takes an advisory lock so only one instance migrates at a time, except on SQLite. The lock belongs to the connection,
+// so everything done while holding it uses the returned connection.
+func (m *Migrator) lock(ctx context.Context) (*sql.Conn, func(), error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var acquire, release func() (bool, error)
+	switch m.dialect {
+	case database.DialectMySQL:
+		acquire = func() (bool, error) {
+			var acquired sql.NullInt64
+			err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, int(lockTimeout.Seconds())).Scan(&acquired)
+			return acquired.Int64 == 1, err
+		}
+		release = func() (bool, error) {
+			_, err := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+			return true, err
+		}
+	case database.DialectPostgres:
+		// pg_advisory_lock waits forever, so poll with the non-blocking version instead
+		acquire = func() (bool, error) {
+			deadline := time.Now().Add(lockTimeout)
+			for {
+				var acquired bool
+				if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", lockKey).Scan(&acquired); err != nil || acquired {
+					return acquired, err
+				}
+				if time.Now().After(deadline) {
+					return false, nil
+				}
+				time.Sleep(time.Second)
+			}
+		}
+		release = func() (bool, error) {
+			_, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+			return true, err
+		}
+	default:
+		// SQLite has no advisory locks, and is only meant to be used by a single instance. Two processes migrating
+		// the same file at once still can't both apply a migration: each runs in a transaction and SQLite only lets
+		// one write at a time, so the loser fails, because the migration's changes or its schema_migrations row are
+		// already there or its snapshot is stale, and rolls back. It then has to be run again.
+		acquire = func() (bool, error) { return true, nil }
+		release = func() (bool, error) { return true, nil }
+	}
+
+	acquired, err := acquire()
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	if !acquired {
+		conn.Close()
+		return nil, nil, errors.New("timed out waiting for another instance to finish migrating")
+	}
+
+	unlock := func() {
+		// Closing the connection would also release the lock, this just makes it prompt
+		if _, err := release(); err != nil {
+			m.logger.Warn("Failed to release migration lock", "error", err)
+		}
+		conn.Close()
+	}
+
+	return conn, unlock, nil
+}
+
+// run runs a migration's script and then record, the statement that updates schema_migrations to match.
+// Where DDL can be rolled back, both run in one transaction so a failed migration leaves nothing behind.
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, script string, record string, args ...any) error {
+	record = m.dialect.Rebind(record)
+
+	if m.dialect == database.DialectMySQL {
+		if strings.TrimSpace(script) != "" {
+			if _, err := conn.ExecContext(ctx, script); err != nil {
+				return err
+			}
+		}
+
+		_, err := conn.ExecContext(ctx, record, args...)
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if strings.TrimSpace(script) != "" {
+		if _, err := tx.ExecContext(ctx, script); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
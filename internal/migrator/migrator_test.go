@@ -0,0 +1,414 @@
+package migrator_test
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/migrator"
+)
+
+// migrations are three SQLite migrations, each creating a table
+func migrations() fstest.MapFS {
+	return fstest.MapFS{
+		"1_create_a.up.sql":   {Data: []byte("CREATE TABLE a (id integer PRIMARY KEY);")},
+		"1_create_a.down.sql": {Data: []byte("DROP TABLE a;")},
+		"2_create_b.up.sql":   {Data: []byte("CREATE TABLE b (id integer PRIMARY KEY);")},
+		"2_create_b.down.sql": {Data: []byte("DROP TABLE b;")},
+		"3_create_c.up.sql":   {Data: []byte("CREATE TABLE c (id integer PRIMARY KEY);")},
+		"3_create_c.down.sql": {Data: []byte("DROP TABLE c;")},
+	}
+}
+
+// newDB opens a new SQLite database file for the test
+func newDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	return newDBAt(t, filepath.Join(t.TempDir(), "test.db"))
+}
+
+func newDBAt(t *testing.T, path string) *sql.DB {
+	t.Helper()
+
+	db, err := database.OpenForMigrations(database.DialectSQLite, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func newMigrator(t *testing.T, db *sql.DB, fsys fstest.MapFS) *migrator.Migrator {
+	t.Helper()
+
+	m, err := migrator.New(db, database.DialectSQLite, fsys, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return m
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+
+	return count == 1
+}
+
+func versions(ran []migrator.Migration) []int64 {
+	var v []int64
+	for _, migration := range ran {
+		v = append(v, migration.Version)
+	}
+
+	return v
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		want    []int64
+		wantErr string
+	}{
+		{
+			name: "orders by version",
+			fsys: fstest.MapFS{
+				"10_ten.up.sql": {Data: []byte("")},
+				"2_two.up.sql":  {Data: []byte("")},
+				"1_one.up.sql":  {Data: []byte("")},
+			},
+			want: []int64{1, 2, 10},
+		},
+		{
+			name: "ignores other files",
+			fsys: fstest.MapFS{
+				"1_one.up.sql": {Data: []byte("")},
+				"README.md":    {Data: []byte("")},
+				"2_Two.up.sql": {Data: []byte("")},
+			},
+			want: []int64{1},
+		},
+		{
+			name: "shared version",
+			fsys: fstest.MapFS{
+				"1_one.up.sql":     {Data: []byte("")},
+				"1_another.up.sql": {Data: []byte("")},
+			},
+			wantErr: "share a version",
+		},
+		{
+			name:    "down without up",
+			fsys:    fstest.MapFS{"1_one.down.sql": {Data: []byte("")}},
+			wantErr: "no up file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loaded, err := migrator.Load(tt.fsys)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load = %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := versions(loaded); !slices.Equal(got, tt.want) {
+				t.Errorf("versions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	mysqlDir, sqliteDir := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(sqliteDir, "4_existing.up.sql"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := migrator.Create([]string{mysqlDir, sqliteDir}, "Add User's Name!")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Numbered after the highest version in any directory, with the name made safe for a file name
+	want := []string{
+		filepath.Join(mysqlDir, "5_add_user_s_name.up.sql"),
+		filepath.Join(mysqlDir, "5_add_user_s_name.down.sql"),
+		filepath.Join(sqliteDir, "5_add_user_s_name.up.sql"),
+		filepath.Join(sqliteDir, "5_add_user_s_name.down.sql"),
+	}
+	if !slices.Equal(paths, want) {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+	for _, path := range want {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s not created: %v", path, err)
+		}
+	}
+
+	if _, err := migrator.Create([]string{mysqlDir}, "!!!"); err == nil {
+		t.Error("Create accepted a name with no letters or numbers")
+	}
+}
+
+func TestUp(t *testing.T) {
+	ctx := context.Background()
+	db := newDB(t)
+	m := newMigrator(t, db, migrations())
+
+	ran, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if got := versions(ran); !slices.Equal(got, []int64{1, 2, 3}) {
+		t.Errorf("ran %v, want [1 2 3]", got)
+	}
+	for _, table := range []string{"a", "b", "c"} {
+		if !tableExists(t, db, table) {
+			t.Errorf("table %s not created", table)
+		}
+	}
+
+	ran, err = m.Up(ctx)
+	if err != nil || len(ran) != 0 {
+		t.Errorf("second Up = %v, %v, want nothing to run", versions(ran), err)
+	}
+}
+
+func TestUpRollsBackFailedMigration(t *testing.T) {
+	ctx := context.Background()
+	db := newDB(t)
+	fsys := migrations()
+	fsys["2_create_b.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE b (id integer PRIMARY KEY); NOT SQL;")}
+
+	ran, err := newMigrator(t, db, fsys).Up(ctx)
+	if err == nil {
+		t.Fatal("Up succeeded with a broken migration")
+	}
+	if got := versions(ran); !slices.Equal(got, []int64{1}) {
+		t.Errorf("ran %v, want [1]", got)
+	}
+	if tableExists(t, db, "b") {
+		t.Error("failed migration's table kept")
+	}
+
+	statuses, err := newMigrator(t, db, fsys).Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statuses[1].AppliedAt != nil {
+		t.Error("failed migration recorded as applied")
+	}
+}
+
+func TestUpRefusesToRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(fsys fstest.MapFS)
+		wantErr string
+	}{
+		{
+			name: "modified",
+			change: func(fsys fstest.MapFS) {
+				fsys["2_create_b.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE b (id integer PRIMARY KEY, name text);")}
+			},
+			wantErr: "has been modified",
+		},
+		{
+			name: "missing",
+			change: func(fsys fstest.MapFS) {
+				delete(fsys, "2_create_b.up.sql")
+				delete(fsys, "2_create_b.down.sql")
+			},
+			wantErr: "its file is missing",
+		},
+		{
+			name: "out of order",
+			change: func(fsys fstest.MapFS) {
+				fsys["0_create_d.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE d (id integer PRIMARY KEY);")}
+			},
+			wantErr: "is pending but the newer migration 3 is already applied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := newDB(t)
+			if _, err := newMigrator(t, db, migrations()).Up(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			fsys := migrations()
+			tt.change(fsys)
+
+			ran, err := newMigrator(t, db, fsys).Up(ctx)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Up = %v, want an error containing %q", err, tt.wantErr)
+			}
+			if len(ran) != 0 {
+				t.Errorf("ran %v, want nothing", versions(ran))
+			}
+		})
+	}
+}
+
+func TestUpConcurrentlyOnSQLite(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	// Two processes, each with its own connections to the file
+	errs := make(chan error, 2)
+	for range 2 {
+		m := newMigrator(t, newDBAt(t, path), migrations())
+		go func() {
+			_, err := m.Up(ctx)
+			errs <- err
+		}()
+	}
+	first, second := <-errs, <-errs
+
+	// Either may lose, but the migrations end up applied exactly once
+	if first != nil && second != nil {
+		t.Fatalf("both failed: %v, %v", first, second)
+	}
+	db := newDBAt(t, path)
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("recorded %d migrations, want 3", count)
+	}
+	if ran, err := newMigrator(t, db, migrations()).Up(ctx); err != nil || len(ran) != 0 {
+		t.Errorf("Up afterwards = %v, %v, want nothing to run", versions(ran), err)
+	}
+}
+
+func TestDown(t *testing.T) {
+	ctx := context.Background()
+	db := newDB(t)
+	m := newMigrator(t, db, migrations())
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	ran, err := m.Down(ctx, 2)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if got := versions(ran); !slices.Equal(got, []int64{3, 2}) {
+		t.Errorf("rolled back %v, want [3 2]", got)
+	}
+	if !tableExists(t, db, "a") || tableExists(t, db, "b") || tableExists(t, db, "c") {
+		t.Error("tables don't match the migrations left applied")
+	}
+
+	// They can be applied again
+	ran, err = m.Up(ctx)
+	if err != nil || !slices.Equal(versions(ran), []int64{2, 3}) {
+		t.Errorf("Up after Down = %v, %v, want [2 3]", versions(ran), err)
+	}
+}
+
+func TestDownWithoutDownFile(t *testing.T) {
+	ctx := context.Background()
+	db := newDB(t)
+	fsys := migrations()
+	delete(fsys, "3_create_c.down.sql")
+
+	m := newMigrator(t, db, fsys)
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Down(ctx, 1); err == nil || !strings.Contains(err.Error(), "has no down migration") {
+		t.Errorf("Down = %v, want the no down migration error", err)
+	}
+	if !tableExists(t, db, "c") {
+		t.Error("table dropped without a down migration")
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	ctx := context.Background()
+	db := newDB(t)
+	m := newMigrator(t, db, migrations())
+
+	recorded, err := m.Baseline(ctx, 2)
+	if err != nil {
+		t.Fatalf("Baseline: %v", err)
+	}
+	if got := versions(recorded); !slices.Equal(got, []int64{1, 2}) {
+		t.Errorf("recorded %v, want [1 2]", got)
+	}
+	if tableExists(t, db, "a") {
+		t.Error("Baseline ran a migration")
+	}
+
+	// Only the migrations after the baseline run
+	ran, err := m.Up(ctx)
+	if err != nil || !slices.Equal(versions(ran), []int64{3}) {
+		t.Errorf("Up after Baseline = %v, %v, want [3]", versions(ran), err)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	ctx := context.Background()
+	db := newDB(t)
+	if _, err := newMigrator(t, db, migrations()).Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys := migrations()
+	fsys["1_create_a.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE a (id integer PRIMARY KEY, name text);")}
+	delete(fsys, "2_create_b.up.sql")
+	delete(fsys, "2_create_b.down.sql")
+	fsys["4_create_d.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE d (id integer PRIMARY KEY);")}
+
+	statuses, err := newMigrator(t, db, fsys).Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type summary struct {
+		version  int64
+		applied  bool
+		modified bool
+		missing  bool
+	}
+	want := []summary{
+		{version: 1, applied: true, modified: true},
+		{version: 2, applied: true, missing: true},
+		{version: 3, applied: true},
+		{version: 4},
+	}
+	if len(statuses) != len(want) {
+		t.Fatalf("statuses = %+v, want %d", statuses, len(want))
+	}
+	for i, status := range statuses {
+		got := summary{status.Version, status.AppliedAt != nil, status.Modified, status.Missing}
+		if got != want[i] {
+			t.Errorf("status %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
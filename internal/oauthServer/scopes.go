@@ -0,0 +1,34 @@
+package oauthServer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/jwtHelper"
+)
+
+// EffectiveScopes returns the scopes an OAuth access token can still use: those it was issued with that the user
+// still consents to, or for a client's own token, that the client is still registered for. It is empty once the
+// user withdraws consent or the client is revoked, so that takes effect straight away.
+func EffectiveScopes(ctx context.Context, stores database.Stores, claims *jwtHelper.TokenClaims) ([]string, error) {
+	if claims.UserID == 0 {
+		client, err := stores.OauthClients.GetActive(ctx, claims.ClientID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return []string{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return Intersect(claims.Scopes, client.Scopes), nil
+	}
+
+	consented, err := stores.OauthConsents.GetScopes(ctx, claims.UserID, claims.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	return Intersect(claims.Scopes, consented), nil
+}
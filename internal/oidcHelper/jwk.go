@@ -0,0 +1,79 @@
+package oidcHelper
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWK turns an RSA or EC JSON Web Key into a public key usable for verifying signatures
+func parseJWK(raw json.RawMessage) (string, interface{}, error) {
+	var key jwk
+	if err := json.Unmarshal(raw, &key); err != nil {
+		return "", nil, err
+	}
+
+	if key.Use != "" && key.Use != "sig" {
+		return "", nil, errors.New("key is not for signing")
+	}
+
+	switch key.Kty {
+	case "RSA":
+		n, err := decodeBigInt(key.N)
+		if err != nil {
+			return "", nil, err
+		}
+		e, err := decodeBigInt(key.E)
+		if err != nil {
+			return "", nil, err
+		}
+		return key.Kid, &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch key.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return "", nil, fmt.Errorf("unsupported curve: %s", key.Crv)
+		}
+		x, err := decodeBigInt(key.X)
+		if err != nil {
+			return "", nil, err
+		}
+		y, err := decodeBigInt(key.Y)
+		if err != nil {
+			return "", nil, err
+		}
+		return key.Kid, &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported key type: %s", key.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,147 @@
+package oidcHelper_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nathanjms/go-api-template/internal/oidcHelper"
+	"github.com/nathanjms/go-api-template/internal/testHelper"
+)
+
+const redirectURL = "http://localhost:8080/login/oidc/mock/callback"
+
+var identity = testHelper.OIDCIdentity{Subject: "subject", Email: "user@example.com", EmailVerified: true}
+
+// authorize starts a login and has the user sign in at the provider, returning the code and the PKCE verifier
+func authorize(t *testing.T, mock *testHelper.OIDCProvider, provider *oidcHelper.Provider, nonce string) (string, string) {
+	t.Helper()
+
+	verifier, challenge, err := oidcHelper.GeneratePKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", nonce, challenge)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	code, state := mock.Authorize(t, authURL, identity)
+	if state != "state" {
+		t.Fatalf("state = %q, want it passed through", state)
+	}
+
+	return code, verifier
+}
+
+func TestProviderCodeFlow(t *testing.T) {
+	ctx := context.Background()
+	mock := testHelper.NewOIDCProvider(t)
+	provider := oidcHelper.NewProvider(mock.Config("mock", redirectURL))
+
+	code, verifier := authorize(t, mock, provider, "nonce")
+
+	tokens, err := provider.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, tokens.IDToken, "nonce")
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if claims.Subject != identity.Subject || claims.Email != identity.Email || !claims.EmailVerified {
+		t.Errorf("claims = %+v, want %+v", claims, identity)
+	}
+
+	if _, err := provider.Exchange(ctx, code, verifier); err == nil {
+		t.Error("Exchange accepted a code twice")
+	}
+}
+
+func TestAuthCodeURLUsesS256Challenge(t *testing.T) {
+	mock := testHelper.NewOIDCProvider(t)
+	provider := oidcHelper.NewProvider(mock.Config("mock", redirectURL))
+
+	verifier, challenge, err := oidcHelper.GeneratePKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	if challenge != base64.RawURLEncoding.EncodeToString(sum[:]) {
+		t.Errorf("challenge %q is not the S256 hash of the verifier", challenge)
+	}
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := parsed.Query()
+	if query.Get("code_challenge") != challenge || query.Get("code_challenge_method") != "S256" || query.Get("nonce") != "nonce" {
+		t.Errorf("authorization URL query = %v", query)
+	}
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	mock := testHelper.NewOIDCProvider(t)
+	provider := oidcHelper.NewProvider(mock.Config("mock", redirectURL))
+
+	code, _ := authorize(t, mock, provider, "nonce")
+	otherVerifier, _, err := oidcHelper.GeneratePKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.Exchange(context.Background(), code, otherVerifier); err == nil {
+		t.Error("Exchange succeeded with another login's PKCE verifier")
+	}
+}
+
+func TestVerifyIDTokenRejectsWrongNonce(t *testing.T) {
+	ctx := context.Background()
+	mock := testHelper.NewOIDCProvider(t)
+	provider := oidcHelper.NewProvider(mock.Config("mock", redirectURL))
+
+	code, verifier := authorize(t, mock, provider, "nonce")
+	tokens, err := provider.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.VerifyIDToken(ctx, tokens.IDToken, "another nonce"); err == nil {
+		t.Error("VerifyIDToken accepted a token issued for another login")
+	}
+}
+
+func TestVerifyIDTokenRejectsBadClaims(t *testing.T) {
+	mock := testHelper.NewOIDCProvider(t)
+	provider := oidcHelper.NewProvider(mock.Config("mock", redirectURL))
+
+	tests := map[string]func(claims map[string]any){
+		"another audience": func(claims map[string]any) { claims["aud"] = "another client" },
+		"another issuer":   func(claims map[string]any) { claims["iss"] = "https://issuer.example.com" },
+		"expired":          func(claims map[string]any) { claims["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"no nonce":         func(claims map[string]any) { delete(claims, "nonce") },
+		"no subject":       func(claims map[string]any) { delete(claims, "sub") },
+	}
+
+	for name, tamper := range tests {
+		t.Run(name, func(t *testing.T) {
+			claims := mock.Claims(identity, "nonce")
+			tamper(claims)
+
+			if _, err := provider.VerifyIDToken(context.Background(), mock.IDToken(t, claims), "nonce"); err == nil {
+				t.Error("VerifyIDToken accepted the token")
+			}
+		})
+	}
+}
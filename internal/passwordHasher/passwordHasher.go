@@ -0,0 +1,164 @@
+package passwordHasher
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algorithms new hashes can be created with
+const (
+	Argon2id = "argon2id"
+	Bcrypt   = "bcrypt"
+)
+
+// Limits on argon2id parameters, so a tampered hash can't make verifying it use unbounded memory or time
+const (
+	maxArgon2Memory      = 1 << 20 // 1 GiB
+	maxArgon2Iterations  = 64
+	maxArgon2Parallelism = 64
+	maxArgon2KeyLength   = 1024
+)
+
+var ErrUnknownHashFormat = errors.New("unknown password hash format")
+
+// Argon2Params are the argon2id cost parameters. Memory is in KiB.
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// Hasher hashes passwords with the configured algorithm and verifies hashes made with any supported algorithm.
+// Hashes are PHC strings, e.g. $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>, or bcrypt's own $2a$ format.
+type Hasher struct {
+	algorithm  string
+	argon2     Argon2Params
+	bcryptCost int
+}
+
+func New(algorithm string, argon2Params Argon2Params, bcryptCost int) (*Hasher, error) {
+	if algorithm != Argon2id && algorithm != Bcrypt {
+		return nil, fmt.Errorf("unknown password hash algorithm: %s", algorithm)
+	}
+
+	if bcryptCost < bcrypt.MinCost || bcryptCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	if !argon2Params.valid() {
+		return nil, fmt.Errorf(
+			"argon2 memory, iterations and parallelism must be greater than zero and at most %d KiB, %d and %d",
+			maxArgon2Memory, maxArgon2Iterations, maxArgon2Parallelism,
+		)
+	}
+
+	return &Hasher{algorithm: algorithm, argon2: argon2Params, bcryptCost: bcryptCost}, nil
+}
+
+// Hash hashes the password with the configured algorithm
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.algorithm == Bcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+		return string(hash), err
+	}
+
+	salt := make([]byte, h.argon2.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.argon2.Iterations, h.argon2.Memory, h.argon2.Parallelism, h.argon2.KeyLength)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.argon2.Memory, h.argon2.Iterations, h.argon2.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify checks the password against the hash. needsRehash is true when the password matched but the hash
+// was made with a different algorithm or weaker parameters than configured, so it should be replaced.
+func (h *Hasher) Verify(password string, hash string) (ok bool, needsRehash bool, err error) {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return h.verifyArgon2id(password, hash)
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		return h.verifyBcrypt(password, hash)
+	default:
+		return false, false, ErrUnknownHashFormat
+	}
+}
+
+func (h *Hasher) verifyBcrypt(password string, hash string) (bool, bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return false, false, err
+	}
+
+	return true, h.algorithm != Bcrypt || cost != h.bcryptCost, nil
+}
+
+func (h *Hasher) verifyArgon2id(password string, hash string) (bool, bool, error) {
+	// "", "argon2id", "v=19", "m=65536,t=3,p=2", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false, false, ErrUnknownHashFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false, ErrUnknownHashFormat
+	}
+
+	var params Argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return false, false, ErrUnknownHashFormat
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, ErrUnknownHashFormat
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, false, ErrUnknownHashFormat
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	if !params.valid() || params.KeyLength == 0 || params.KeyLength > maxArgon2KeyLength {
+		return false, false, ErrUnknownHashFormat
+	}
+
+	otherKey := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	if subtle.ConstantTimeCompare(key, otherKey) != 1 {
+		return false, false, nil
+	}
+
+	return true, h.algorithm != Argon2id || params != h.argon2, nil
+}
+
+// valid reports whether the cost parameters are ones this package is willing to compute a hash with
+func (p Argon2Params) valid() bool {
+	return p.Memory > 0 && p.Memory <= maxArgon2Memory &&
+		p.Iterations > 0 && p.Iterations <= maxArgon2Iterations &&
+		p.Parallelism > 0 && p.Parallelism <= maxArgon2Parallelism
+}
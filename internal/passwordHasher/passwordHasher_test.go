@@ -0,0 +1,152 @@
+package passwordHasher
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Cheap parameters so the tests run quickly
+var testArgon2 = Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func newHasher(t *testing.T, algorithm string, argon2Params Argon2Params, bcryptCost int) *Hasher {
+	t.Helper()
+
+	h, err := New(algorithm, argon2Params, bcryptCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return h
+}
+
+func hash(t *testing.T, h *Hasher, password string) string {
+	t.Helper()
+
+	hashed, err := h.Hash(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return hashed
+}
+
+func TestHashAndVerify(t *testing.T) {
+	for _, algorithm := range []string{Argon2id, Bcrypt} {
+		t.Run(algorithm, func(t *testing.T) {
+			h := newHasher(t, algorithm, testArgon2, 4)
+			hashed := hash(t, h, "correct horse battery staple")
+
+			if ok, needsRehash, err := h.Verify("correct horse battery staple", hashed); !ok || needsRehash || err != nil {
+				t.Errorf("Verify = %v, %v, %v, want a match that needs no rehash", ok, needsRehash, err)
+			}
+			if ok, _, err := h.Verify("wrong password", hashed); ok || err != nil {
+				t.Errorf("Verify with the wrong password = %v, %v, want no match", ok, err)
+			}
+		})
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	argon2Hasher := newHasher(t, Argon2id, testArgon2, 4)
+	bcryptHasher := newHasher(t, Bcrypt, testArgon2, 4)
+	stronger := testArgon2
+	stronger.Iterations = 2
+
+	tests := []struct {
+		name   string
+		hashed string
+		h      *Hasher
+		want   bool
+	}{
+		{name: "bcrypt to argon2id", hashed: hash(t, bcryptHasher, "password"), h: argon2Hasher, want: true},
+		{name: "argon2id to bcrypt", hashed: hash(t, argon2Hasher, "password"), h: bcryptHasher, want: true},
+		{name: "stronger argon2id", hashed: hash(t, argon2Hasher, "password"), h: newHasher(t, Argon2id, stronger, 4), want: true},
+		{name: "higher bcrypt cost", hashed: hash(t, bcryptHasher, "password"), h: newHasher(t, Bcrypt, testArgon2, 5), want: true},
+		{name: "same argon2id", hashed: hash(t, argon2Hasher, "password"), h: argon2Hasher, want: false},
+		{name: "same bcrypt", hashed: hash(t, bcryptHasher, "password"), h: bcryptHasher, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, needsRehash, err := tt.h.Verify("password", tt.hashed)
+			if !ok || err != nil {
+				t.Fatalf("Verify = %v, %v, want a match", ok, err)
+			}
+			if needsRehash != tt.want {
+				t.Errorf("needsRehash = %v, want %v", needsRehash, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsMalformedHashes(t *testing.T) {
+	h := newHasher(t, Argon2id, testArgon2, 4)
+	valid := hash(t, h, "password")
+	parts := strings.Split(valid, "$")
+	salt, key := parts[4], parts[5]
+
+	tests := map[string]string{
+		"unknown algorithm":       "$1$salt$hash",
+		"empty":                   "",
+		"too few parts":           "$argon2id$v=19$m=1024,t=1,p=1$" + salt,
+		"too many parts":          valid + "$extra",
+		"other version":           "$argon2id$v=16$m=1024,t=1,p=1$" + salt + "$" + key,
+		"bad parameters":          "$argon2id$v=19$m=lots,t=1,p=1$" + salt + "$" + key,
+		"missing parameter":       "$argon2id$v=19$m=1024,t=1$" + salt + "$" + key,
+		"bad salt":                "$argon2id$v=19$m=1024,t=1,p=1$not*base64$" + key,
+		"bad key":                 "$argon2id$v=19$m=1024,t=1,p=1$" + salt + "$not*base64",
+		"empty key":               "$argon2id$v=19$m=1024,t=1,p=1$" + salt + "$",
+		"huge memory":             "$argon2id$v=19$m=4294967295,t=1,p=1$" + salt + "$" + key,
+		"huge iterations":         "$argon2id$v=19$m=1024,t=4294967295,p=1$" + salt + "$" + key,
+		"huge parallelism":        "$argon2id$v=19$m=1024,t=1,p=255$" + salt + "$" + key,
+		"parallelism over 8 bits": "$argon2id$v=19$m=1024,t=1,p=256$" + salt + "$" + key,
+		"zero memory":             "$argon2id$v=19$m=0,t=1,p=1$" + salt + "$" + key,
+		"zero iterations":         "$argon2id$v=19$m=1024,t=0,p=1$" + salt + "$" + key,
+		"zero parallelism":        "$argon2id$v=19$m=1024,t=1,p=0$" + salt + "$" + key,
+		"negative memory":         "$argon2id$v=19$m=-1,t=1,p=1$" + salt + "$" + key,
+	}
+
+	for name, hashed := range tests {
+		t.Run(name, func(t *testing.T) {
+			ok, _, err := h.Verify("password", hashed)
+			if ok || !errors.Is(err, ErrUnknownHashFormat) {
+				t.Errorf("Verify = %v, %v, want %v", ok, err, ErrUnknownHashFormat)
+			}
+		})
+	}
+}
+
+func TestVerifyComparesWholeKey(t *testing.T) {
+	h := newHasher(t, Argon2id, testArgon2, 4)
+	valid := hash(t, h, "password")
+	parts := strings.Split(valid, "$")
+
+	// A key that matches the start of the real one, as a comparison stopping early would accept
+	parts[5] = parts[5][:len(parts[5])-4]
+	if ok, _, err := h.Verify("password", strings.Join(parts, "$")); ok || err != nil {
+		t.Errorf("Verify with a truncated key = %v, %v, want no match", ok, err)
+	}
+}
+
+func TestNewRejectsParameters(t *testing.T) {
+	tests := map[string]struct {
+		algorithm  string
+		argon2     Argon2Params
+		bcryptCost int
+	}{
+		"unknown algorithm":  {algorithm: "md5", argon2: testArgon2, bcryptCost: 4},
+		"low bcrypt cost":    {algorithm: Bcrypt, argon2: testArgon2, bcryptCost: 3},
+		"high bcrypt cost":   {algorithm: Bcrypt, argon2: testArgon2, bcryptCost: 32},
+		"zero argon2 memory": {algorithm: Argon2id, argon2: Argon2Params{Iterations: 1, Parallelism: 1}, bcryptCost: 4},
+		"huge argon2 memory": {algorithm: Argon2id, argon2: Argon2Params{Memory: maxArgon2Memory + 1, Iterations: 1, Parallelism: 1}, bcryptCost: 4},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := New(tt.algorithm, tt.argon2, tt.bcryptCost); err == nil {
+				t.Error("New succeeded")
+			}
+		})
+	}
+}
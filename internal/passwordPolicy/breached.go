@@ -0,0 +1,166 @@
+package passwordPolicy
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// hashPrefixLength is the length of the hash prefix used to split the list into ranges
+	hashPrefixLength = 5
+	// linearScanSize is when a binary search of the sorted file switches to reading it line by line
+	linearScanSize = 4096
+	// maxLineLength comfortably fits a 40 character hash and its breach count
+	maxLineLength = 256
+)
+
+// BreachedList looks up passwords in an offline copy of the Have I Been Pwned Pwned Passwords list (SHA-1 version),
+// without loading it into memory. The path may be either:
+//   - a directory of range files named by the first 5 hex characters of the hash (e.g. 5BAA6.txt), each holding
+//     the remaining 35 characters and a count per line, as served by the k-anonymity range API and saved by the
+//     official downloader without --single
+//   - a single file with a full hash and count per line, sorted by hash, which is searched with a binary search
+type BreachedList struct {
+	path  string
+	isDir bool
+}
+
+func OpenBreachedList(path string) (*BreachedList, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening breached password list: %v", err)
+	}
+
+	return &BreachedList{path: path, isDir: info.IsDir()}, nil
+}
+
+// Contains reports whether the password is in the list
+func (b *BreachedList) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	if b.isDir {
+		return b.containsInRange(hash)
+	}
+
+	return b.containsInSorted(hash)
+}
+
+func (b *BreachedList) containsInRange(hash string) (bool, error) {
+	file, err := os.Open(filepath.Join(b.path, hash[:hashPrefixLength]+".txt"))
+	if os.IsNotExist(err) {
+		// The downloader doesn't write empty ranges
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	suffix := hash[hashPrefixLength:]
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if strings.EqualFold(lineHash(scanner.Bytes()), suffix) {
+			return true, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
+
+func (b *BreachedList) containsInSorted(hash string) (bool, error) {
+	file, err := os.Open(b.path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	// Narrow [lo, hi) down to a small window of whole lines that would contain the hash, with lo always at the start of a line
+	lo, hi := int64(0), info.Size()
+	for hi-lo > linearScanSize {
+		mid := lo + (hi-lo)/2
+
+		start, line, err := lineAfter(file, mid)
+		if err != nil {
+			return false, err
+		}
+
+		if start >= hi {
+			hi = mid
+			continue
+		}
+
+		switch compared := strings.Compare(strings.ToUpper(lineHash(line)), hash); {
+		case compared == 0:
+			return true, nil
+		case compared < 0:
+			lo = start + int64(len(line))
+		default:
+			hi = start
+		}
+	}
+
+	scanner := bufio.NewScanner(io.NewSectionReader(file, lo, hi-lo))
+	for scanner.Scan() {
+		if strings.EqualFold(lineHash(scanner.Bytes()), hash) {
+			return true, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
+
+// lineAfter returns the first whole line starting at or after offset, including its newline, and where it starts.
+// If there is no such line, start is the size of the file.
+func lineAfter(file *os.File, offset int64) (int64, []byte, error) {
+	readFrom := offset
+	if readFrom > 0 {
+		// Back up one byte, so a line starting exactly at offset is found after the previous line's newline
+		readFrom--
+	}
+
+	buf := make([]byte, 2*maxLineLength)
+	n, err := file.ReadAt(buf, readFrom)
+	if err != nil && err != io.EOF {
+		return 0, nil, err
+	}
+	buf = buf[:n]
+
+	start := readFrom
+	if offset > 0 {
+		newline := bytes.IndexByte(buf, '\n')
+		if newline < 0 {
+			return readFrom + int64(n), nil, nil
+		}
+		buf = buf[newline+1:]
+		start = readFrom + int64(newline) + 1
+	}
+
+	if len(buf) == 0 {
+		return start, nil, nil
+	}
+
+	if end := bytes.IndexByte(buf, '\n'); end >= 0 {
+		buf = buf[:end+1]
+	}
+
+	return start, buf, nil
+}
+
+// lineHash returns the hash (or hash suffix) from a "HASH:COUNT" line
+func lineHash(line []byte) string {
+	hash, _, _ := strings.Cut(strings.TrimSpace(string(line)), ":")
+	return hash
+}
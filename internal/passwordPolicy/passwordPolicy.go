@@ -0,0 +1,145 @@
+package passwordPolicy
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed common-passwords.txt
+var commonPasswordsFile string
+
+type Config struct {
+	MinLength        int
+	MaxLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireNumber    bool
+	RequireSymbol    bool
+	// RejectEmail rejects passwords containing the user's email address, or the part before the @
+	RejectEmail bool
+	// RejectCommon rejects passwords on the bundled list of commonly used passwords
+	RejectCommon bool
+	// BreachedListPath is an optional Have I Been Pwned style list of SHA-1 hashes of breached passwords,
+	// see BreachedList for the supported layouts
+	BreachedListPath string
+}
+
+// Policy checks new passwords against the configured rules
+type Policy struct {
+	config   Config
+	common   map[string]struct{}
+	breached *BreachedList
+}
+
+func New(cfg Config) (*Policy, error) {
+	if cfg.MinLength < 1 {
+		return nil, fmt.Errorf("password minimum length must be at least 1")
+	}
+
+	if cfg.MaxLength < cfg.MinLength {
+		return nil, fmt.Errorf("password maximum length (%d) must not be less than the minimum length (%d)", cfg.MaxLength, cfg.MinLength)
+	}
+
+	policy := &Policy{config: cfg, common: map[string]struct{}{}}
+
+	if cfg.RejectCommon {
+		for _, line := range strings.Split(commonPasswordsFile, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			policy.common[strings.ToLower(line)] = struct{}{}
+		}
+	}
+
+	if cfg.BreachedListPath != "" {
+		breached, err := OpenBreachedList(cfg.BreachedListPath)
+		if err != nil {
+			return nil, err
+		}
+		policy.breached = breached
+	}
+
+	return policy, nil
+}
+
+// Validate checks the password against every rule, returning a message for each one it breaks.
+// The email may be empty if it isn't known yet.
+func (p *Policy) Validate(password string, email string) ([]string, error) {
+	problems := []string{}
+
+	length := utf8.RuneCountInString(password)
+	if length < p.config.MinLength {
+		problems = append(problems, fmt.Sprintf("Password must be at least %d characters", p.config.MinLength))
+	}
+	if length > p.config.MaxLength {
+		problems = append(problems, fmt.Sprintf("Password must be at most %d characters", p.config.MaxLength))
+	}
+
+	var hasUpper, hasLower, hasNumber, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasNumber = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.config.RequireUppercase && !hasUpper {
+		problems = append(problems, "Password must contain an uppercase letter")
+	}
+	if p.config.RequireLowercase && !hasLower {
+		problems = append(problems, "Password must contain a lowercase letter")
+	}
+	if p.config.RequireNumber && !hasNumber {
+		problems = append(problems, "Password must contain a number")
+	}
+	if p.config.RequireSymbol && !hasSymbol {
+		problems = append(problems, "Password must contain a symbol")
+	}
+
+	if p.config.RejectEmail && containsEmail(password, email) {
+		problems = append(problems, "Password must not contain your email address")
+	}
+
+	if _, ok := p.common[strings.ToLower(password)]; ok {
+		problems = append(problems, "This password is too common, please choose another")
+	}
+
+	if p.breached != nil {
+		breached, err := p.breached.Contains(password)
+		if err != nil {
+			return nil, err
+		}
+		if breached {
+			problems = append(problems, "This password has appeared in a data breach, please choose another")
+		}
+	}
+
+	return problems, nil
+}
+
+// containsEmail reports whether the password contains the email address or its local part. Very short
+// local parts are ignored, since they would reject too many unrelated passwords.
+func containsEmail(password string, email string) bool {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return false
+	}
+
+	password = strings.ToLower(password)
+	if strings.Contains(password, email) {
+		return true
+	}
+
+	localPart, _, _ := strings.Cut(email, "@")
+	return utf8.RuneCountInString(localPart) >= 3 && strings.Contains(password, localPart)
+}
@@ -0,0 +1,200 @@
+package passwordPolicy
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		password string
+		email    string
+		want     []string
+	}{
+		{name: "long enough", config: Config{MinLength: 8, MaxLength: 16}, password: "abcdefgh"},
+		{name: "too short", config: Config{MinLength: 8, MaxLength: 16}, password: "abcdefg", want: []string{"Password must be at least 8 characters"}},
+		{name: "too long", config: Config{MinLength: 8, MaxLength: 16}, password: strings.Repeat("a", 17), want: []string{"Password must be at most 16 characters"}},
+		// Length is in characters, not bytes: 8 two-byte characters are long enough and 16 aren't too long
+		{name: "multibyte at minimum", config: Config{MinLength: 8, MaxLength: 16}, password: strings.Repeat("é", 8)},
+		{name: "multibyte at maximum", config: Config{MinLength: 8, MaxLength: 16}, password: strings.Repeat("é", 16)},
+		{name: "multibyte too short", config: Config{MinLength: 8, MaxLength: 16}, password: strings.Repeat("é", 7), want: []string{"Password must be at least 8 characters"}},
+		{
+			name:     "character classes",
+			config:   Config{MinLength: 1, MaxLength: 64, RequireUppercase: true, RequireLowercase: true, RequireNumber: true, RequireSymbol: true},
+			password: "abc",
+			want:     []string{"Password must contain an uppercase letter", "Password must contain a number", "Password must contain a symbol"},
+		},
+		{
+			name:     "every character class",
+			config:   Config{MinLength: 1, MaxLength: 64, RequireUppercase: true, RequireLowercase: true, RequireNumber: true, RequireSymbol: true},
+			password: "Ab1 ",
+		},
+		{name: "contains email", config: Config{MinLength: 1, MaxLength: 64, RejectEmail: true}, password: "xUser@Example.comx", email: "user@example.com", want: []string{"Password must not contain your email address"}},
+		{name: "contains local part", config: Config{MinLength: 1, MaxLength: 64, RejectEmail: true}, password: "myusername1", email: "username@example.com", want: []string{"Password must not contain your email address"}},
+		{name: "short local part", config: Config{MinLength: 1, MaxLength: 64, RejectEmail: true}, password: "abcdef", email: "ab@example.com"},
+		{name: "email unknown", config: Config{MinLength: 1, MaxLength: 64, RejectEmail: true}, password: "abcdef"},
+		{name: "common", config: Config{MinLength: 1, MaxLength: 64, RejectCommon: true}, password: "password", want: []string{"This password is too common, please choose another"}},
+		{name: "common in another case", config: Config{MinLength: 1, MaxLength: 64, RejectCommon: true}, password: "PassWord", want: []string{"This password is too common, please choose another"}},
+		{name: "common allowed", config: Config{MinLength: 1, MaxLength: 64}, password: "password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := New(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			problems, err := policy.Validate(tt.password, tt.email)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(problems, tt.want) && !(len(problems) == 0 && len(tt.want) == 0) {
+				t.Errorf("problems = %q, want %q", problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRejectsLengths(t *testing.T) {
+	for _, cfg := range []Config{{MinLength: 0, MaxLength: 10}, {MinLength: 10, MaxLength: 9}} {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("New(%+v) succeeded", cfg)
+		}
+	}
+}
+
+// writeSortedList writes a single file list of the breached passwords among enough others that it is binary
+// searched, with lowercase hashes as some copies of the list have
+func writeSortedList(t *testing.T, breached ...string) string {
+	t.Helper()
+
+	var lines []string
+	for i := range 1000 {
+		lines = append(lines, strings.ToLower(sha1Hex(fmt.Sprintf("filler %d", i)))+":1")
+	}
+	for _, password := range breached {
+		lines = append(lines, strings.ToLower(sha1Hex(password))+":42")
+	}
+	slices.Sort(lines)
+
+	path := filepath.Join(t.TempDir(), "pwned-passwords.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// writeRangeList writes a directory of range files holding the breached passwords
+func writeRangeList(t *testing.T, breached ...string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, password := range breached {
+		hash := sha1Hex(password)
+		path := filepath.Join(dir, hash[:hashPrefixLength]+".txt")
+
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(file, "0000000000000000000000000000000000A:1\r\n%s:42\r\n", hash[hashPrefixLength:])
+		if err := file.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestBreachedList(t *testing.T) {
+	breached := []string{"hunter2", "correct horse battery staple"}
+	layouts := map[string]string{
+		"sorted file": writeSortedList(t, breached...),
+		"range files": writeRangeList(t, breached...),
+	}
+
+	tests := map[string]bool{
+		"hunter2":                      true,
+		"correct horse battery staple": true,
+		// Passwords are case sensitive, so other cases have other hashes
+		"Hunter2":       false,
+		"HUNTER2":       false,
+		"hunter3":       false,
+		"filler 999":    true,
+		"not breached!": false,
+		"":              false,
+	}
+
+	for layout, path := range layouts {
+		t.Run(layout, func(t *testing.T) {
+			list, err := OpenBreachedList(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for password, want := range tests {
+				if layout == "range files" && strings.HasPrefix(password, "filler") {
+					continue
+				}
+
+				got, err := list.Contains(password)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != want {
+					t.Errorf("Contains(%q) = %v, want %v", password, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBreachedListFindsEveryLine(t *testing.T) {
+	// Every entry, including the first and last, is found by the binary search
+	path := writeSortedList(t)
+	list, err := OpenBreachedList(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range 1000 {
+		password := fmt.Sprintf("filler %d", i)
+		if found, err := list.Contains(password); err != nil || !found {
+			t.Fatalf("Contains(%q) = %v, %v, want true", password, found, err)
+		}
+	}
+}
+
+func TestValidateRejectsBreachedPassword(t *testing.T) {
+	policy, err := New(Config{MinLength: 1, MaxLength: 64, BreachedListPath: writeRangeList(t, "hunter2")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	problems, err := policy.Validate("hunter2", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(problems, []string{"This password has appeared in a data breach, please choose another"}) {
+		t.Errorf("problems = %q, want the breached problem", problems)
+	}
+
+	if _, err := New(Config{MinLength: 1, MaxLength: 64, BreachedListPath: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("New succeeded with a missing breached list")
+	}
+}
@@ -0,0 +1,101 @@
+package rbac
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nathanjms/go-api-template/internal/database"
+)
+
+// Roles seeded by the migrations
+const (
+	RoleAdmin = "admin"
+)
+
+// Permissions seeded by the migrations, checked with middleware.RequirePermission
+const (
+	PermissionUsersView          = "users.view"
+	PermissionUsersManageRoles   = "users.manage_roles"
+	PermissionUsersImpersonate   = "users.impersonate"
+	PermissionOauthManageClients = "oauth.manage_clients"
+)
+
+// Store answers whether a user has a permission. Permissions are looked up from the user's roles
+// rather than put in the JWT, so changes apply to existing sessions once the cache entry expires.
+type Store struct {
+	roles    database.RoleStore
+	cacheTTL time.Duration
+
+	mu    sync.Mutex
+	users map[int64]userEntry
+}
+
+type userEntry struct {
+	permissions map[string]bool
+	validUntil  time.Time
+}
+
+// New creates a permission store. Lookups are cached for cacheTTL, which bounds how long a role
+// change made on another instance can take to be seen by this one.
+func New(roles database.RoleStore, cacheTTL time.Duration) *Store {
+	return &Store{
+		roles:    roles,
+		cacheTTL: cacheTTL,
+		users:    map[int64]userEntry{},
+	}
+}
+
+// HasPermission reports whether any of the user's roles grant the permission
+func (s *Store) HasPermission(ctx context.Context, userId int64, permission string) (bool, error) {
+	permissions, err := s.permissions(ctx, userId)
+	if err != nil {
+		return false, err
+	}
+
+	return permissions[permission], nil
+}
+
+// Invalidate drops the cached permissions of a user whose roles have changed
+func (s *Store) Invalidate(userId int64) {
+	s.mu.Lock()
+	delete(s.users, userId)
+	s.mu.Unlock()
+}
+
+func (s *Store) permissions(ctx context.Context, userId int64) (map[string]bool, error) {
+	s.mu.Lock()
+	entry, ok := s.users[userId]
+	s.mu.Unlock()
+
+	if ok && time.Now().Before(entry.validUntil) {
+		return entry.permissions, nil
+	}
+
+	names, err := s.roles.GetPermissionNamesForUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make(map[string]bool, len(names))
+	for _, name := range names {
+		permissions[name] = true
+	}
+
+	s.mu.Lock()
+	s.prune()
+	s.users[userId] = userEntry{permissions: permissions, validUntil: time.Now().Add(s.cacheTTL)}
+	s.mu.Unlock()
+
+	return permissions, nil
+}
+
+// prune drops expired cache entries so the cache does not grow forever. It must be called with mu held.
+func (s *Store) prune() {
+	now := time.Now()
+	for userId, entry := range s.users {
+		if now.After(entry.validUntil) {
+			delete(s.users, userId)
+		}
+	}
+}
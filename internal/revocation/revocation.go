@@ -0,0 +1,282 @@
+package revocation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/jwtHelper"
+)
+
+const pruneInterval = time.Hour
+
+// Store keeps track of revoked access tokens and sessions. Revocations live in MySQL so every instance sees them,
+// with an in-process cache in front so most requests do not need a query.
+type Store struct {
+	stores   database.Stores
+	cacheTTL time.Duration
+
+	mu         sync.Mutex
+	tokens     map[string]tokenEntry
+	users      map[int64]userEntry
+	sessions   map[string]sessionEntry
+	lastPruned time.Time
+}
+
+type tokenEntry struct {
+	revoked    bool
+	validUntil time.Time
+}
+
+type sessionEntry struct {
+	userId     int64
+	revoked    bool
+	validUntil time.Time
+}
+
+type userEntry struct {
+	revokedBefore time.Time
+	validUntil    time.Time
+}
+
+// New creates a revocation store. Lookups are cached for cacheTTL, which bounds how long
+// a revocation made on another instance can take to be seen by this one.
+func New(stores database.Stores, cacheTTL time.Duration) *Store {
+	return &Store{
+		stores:     stores,
+		cacheTTL:   cacheTTL,
+		tokens:     map[string]tokenEntry{},
+		users:      map[int64]userEntry{},
+		sessions:   map[string]sessionEntry{},
+		lastPruned: time.Now(),
+	}
+}
+
+// IsRevoked reports whether the token has been revoked, either individually, by revoking its session,
+// or as part of revoking all of a user's tokens. Impersonation tokens are also revoked by revoking all of the
+// admin's tokens, since the admin is the one really using them.
+func (s *Store) IsRevoked(ctx context.Context, claims *jwtHelper.TokenClaims) (bool, error) {
+	s.prune()
+
+	revoked, err := s.isTokenRevoked(ctx, claims)
+	if err != nil || revoked {
+		return revoked, err
+	}
+
+	if claims.SessionID != "" {
+		revoked, err := s.isSessionRevoked(ctx, claims)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+
+	revokedBefore, err := s.userRevokedBefore(ctx, claims.UserID)
+	if err != nil {
+		return false, err
+	}
+	if claims.IssuedAt.Before(revokedBefore) {
+		return true, nil
+	}
+
+	if claims.ImpersonatorID != 0 {
+		impersonatorRevokedBefore, err := s.userRevokedBefore(ctx, claims.ImpersonatorID)
+		if err != nil {
+			return false, err
+		}
+
+		return claims.IssuedAt.Before(impersonatorRevokedBefore), nil
+	}
+
+	return false, nil
+}
+
+// Revoke revokes a single access token until it expires
+func (s *Store) Revoke(ctx context.Context, claims *jwtHelper.TokenClaims) error {
+	if err := s.stores.RevokedTokens.Revoke(ctx, claims.ID, claims.UserID, claims.ExpiresAt); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.tokens[claims.ID] = tokenEntry{revoked: true, validUntil: claims.ExpiresAt}
+	s.mu.Unlock()
+
+	s.prune()
+
+	return nil
+}
+
+// RevokeSession signs a single session out, revoking its refresh tokens and access tokens.
+// It returns false if the user has no active session with that id.
+func (s *Store) RevokeSession(ctx context.Context, userId int64, sessionId string) (bool, error) {
+	revoked, err := s.stores.Sessions.Revoke(ctx, userId, sessionId)
+	if err != nil || !revoked {
+		return revoked, err
+	}
+
+	if err := s.stores.RefreshTokens.RevokeFamily(ctx, sessionId); err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	s.sessions[sessionId] = sessionEntry{userId: userId, revoked: true, validUntil: time.Now().Add(s.cacheTTL)}
+	s.mu.Unlock()
+
+	return true, nil
+}
+
+// RevokeOtherSessions signs the user out of every session apart from keepSessionId, which may be empty
+func (s *Store) RevokeOtherSessions(ctx context.Context, userId int64, keepSessionId string) error {
+	if err := s.stores.Sessions.RevokeAllForUser(ctx, userId, keepSessionId); err != nil {
+		return err
+	}
+
+	if err := s.stores.RefreshTokens.RevokeAllForUserExcept(ctx, userId, keepSessionId); err != nil {
+		return err
+	}
+
+	// Forget what we knew about the user's other sessions, so this instance sees the revocation straight away
+	s.mu.Lock()
+	for sessionId, entry := range s.sessions {
+		if entry.userId == userId && sessionId != keepSessionId {
+			delete(s.sessions, sessionId)
+		}
+	}
+	s.mu.Unlock()
+
+	return nil
+}
+
+// RevokeAllForUser revokes every access and refresh token issued to the user so far, signing them out everywhere
+func (s *Store) RevokeAllForUser(ctx context.Context, userId int64) error {
+	// Token iat claims only have second precision
+	now := time.Now().UTC().Truncate(time.Second)
+
+	if err := s.stores.RevokedTokens.RevokeAllForUser(ctx, userId, now); err != nil {
+		return err
+	}
+
+	if err := s.stores.RefreshTokens.RevokeAllForUser(ctx, userId); err != nil {
+		return err
+	}
+
+	if err := s.stores.Sessions.RevokeAllForUser(ctx, userId, ""); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.users[userId] = userEntry{revokedBefore: now, validUntil: time.Now().Add(s.cacheTTL)}
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *Store) isTokenRevoked(ctx context.Context, claims *jwtHelper.TokenClaims) (bool, error) {
+	s.mu.Lock()
+	entry, ok := s.tokens[claims.ID]
+	s.mu.Unlock()
+
+	if ok && time.Now().Before(entry.validUntil) {
+		return entry.revoked, nil
+	}
+
+	revoked, err := s.stores.RevokedTokens.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return false, err
+	}
+
+	// A revocation is permanent, so it can be cached for as long as the token would be valid
+	validUntil := time.Now().Add(s.cacheTTL)
+	if revoked {
+		validUntil = claims.ExpiresAt
+	}
+
+	s.mu.Lock()
+	s.tokens[claims.ID] = tokenEntry{revoked: revoked, validUntil: validUntil}
+	s.mu.Unlock()
+
+	return revoked, nil
+}
+
+func (s *Store) isSessionRevoked(ctx context.Context, claims *jwtHelper.TokenClaims) (bool, error) {
+	s.mu.Lock()
+	entry, ok := s.sessions[claims.SessionID]
+	s.mu.Unlock()
+
+	if ok && time.Now().Before(entry.validUntil) {
+		return entry.revoked, nil
+	}
+
+	revoked, err := s.stores.Sessions.IsRevoked(ctx, claims.SessionID)
+	if err != nil {
+		return false, err
+	}
+
+	// Sessions are only looked up once per cache TTL, which makes this a cheap place to record them being used
+	if !revoked {
+		if err := s.stores.Sessions.Touch(ctx, claims.SessionID); err != nil {
+			return false, err
+		}
+	}
+
+	validUntil := time.Now().Add(s.cacheTTL)
+	if revoked {
+		validUntil = claims.ExpiresAt
+	}
+
+	s.mu.Lock()
+	s.sessions[claims.SessionID] = sessionEntry{userId: claims.UserID, revoked: revoked, validUntil: validUntil}
+	s.mu.Unlock()
+
+	return revoked, nil
+}
+
+func (s *Store) userRevokedBefore(ctx context.Context, userId int64) (time.Time, error) {
+	s.mu.Lock()
+	entry, ok := s.users[userId]
+	s.mu.Unlock()
+
+	if ok && time.Now().Before(entry.validUntil) {
+		return entry.revokedBefore, nil
+	}
+
+	revokedBefore, err := s.stores.RevokedTokens.GetRevokedBefore(ctx, userId)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	s.mu.Lock()
+	s.users[userId] = userEntry{revokedBefore: revokedBefore, validUntil: time.Now().Add(s.cacheTTL)}
+	s.mu.Unlock()
+
+	return revokedBefore, nil
+}
+
+// prune drops stale cache entries so the cache doesn't grow forever. Expired revocations are deleted from the
+// database by a background job.
+func (s *Store) prune() {
+	s.mu.Lock()
+	if time.Since(s.lastPruned) < pruneInterval {
+		s.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	s.lastPruned = now
+	for jti, entry := range s.tokens {
+		if now.After(entry.validUntil) {
+			delete(s.tokens, jti)
+		}
+	}
+	for userId, entry := range s.users {
+		if now.After(entry.validUntil) {
+			delete(s.users, userId)
+		}
+	}
+	for sessionId, entry := range s.sessions {
+		if now.After(entry.validUntil) {
+			delete(s.sessions, sessionId)
+		}
+	}
+	s.mu.Unlock()
+}
@@ -0,0 +1,203 @@
+package revocation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/jwtHelper"
+)
+
+// claims are for a token issued a minute ago, before any revocation the test makes
+func claims(id string, userId int64) *jwtHelper.TokenClaims {
+	now := time.Now().Truncate(time.Second)
+
+	return &jwtHelper.TokenClaims{
+		ID:        id,
+		UserID:    userId,
+		IssuedAt:  now.Add(-time.Minute),
+		ExpiresAt: now.Add(time.Hour),
+	}
+}
+
+func TestRevokeAllForImpersonatorRevokesImpersonationTokens(t *testing.T) {
+	ctx := context.Background()
+	s := New(database.NewMemoryStores(), time.Minute)
+
+	impersonation := claims("impersonation", 2)
+	impersonation.ImpersonatorID = 1
+
+	if revoked, err := s.IsRevoked(ctx, impersonation); err != nil || revoked {
+		t.Fatalf("IsRevoked = %v, %v before revoking", revoked, err)
+	}
+
+	// The admin is signed out everywhere, which has to include the tokens they are impersonating with
+	if err := s.RevokeAllForUser(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if revoked, err := s.IsRevoked(ctx, impersonation); err != nil || !revoked {
+		t.Errorf("IsRevoked = %v, %v after revoking the impersonator's tokens, want true", revoked, err)
+	}
+}
+
+func newSession(t *testing.T, stores database.Stores, id string, userId int64) {
+	t.Helper()
+
+	if err := stores.Sessions.Create(context.Background(), id, userId, "test", "127.0.0.1", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertRevoked(t *testing.T, s *Store, claims *jwtHelper.TokenClaims, want bool) {
+	t.Helper()
+
+	revoked, err := s.IsRevoked(context.Background(), claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revoked != want {
+		t.Errorf("IsRevoked(%s) = %v, want %v", claims.ID, revoked, want)
+	}
+}
+
+func TestRevokeRevokesOnlyThatToken(t *testing.T) {
+	s := New(database.NewMemoryStores(), time.Minute)
+	token, other := claims("token", 1), claims("other", 1)
+
+	assertRevoked(t, s, token, false)
+
+	if err := s.Revoke(context.Background(), token); err != nil {
+		t.Fatal(err)
+	}
+
+	assertRevoked(t, s, token, true)
+	assertRevoked(t, s, other, false)
+}
+
+func TestRevokeSession(t *testing.T) {
+	ctx := context.Background()
+	stores := database.NewMemoryStores()
+	s := New(stores, time.Minute)
+	newSession(t, stores, "session", 1)
+	newSession(t, stores, "other", 1)
+
+	token := claims("token", 1)
+	token.SessionID = "session"
+	other := claims("other token", 1)
+	other.SessionID = "other"
+
+	assertRevoked(t, s, token, false)
+
+	// Another user can't sign the session out
+	if revoked, err := s.RevokeSession(ctx, 2, "session"); err != nil || revoked {
+		t.Fatalf("RevokeSession by another user = %v, %v, want false", revoked, err)
+	}
+	assertRevoked(t, s, token, false)
+
+	if revoked, err := s.RevokeSession(ctx, 1, "session"); err != nil || !revoked {
+		t.Fatalf("RevokeSession = %v, %v, want true", revoked, err)
+	}
+	assertRevoked(t, s, token, true)
+	assertRevoked(t, s, other, false)
+
+	if revoked, err := s.RevokeSession(ctx, 1, "session"); err != nil || revoked {
+		t.Errorf("RevokeSession of a revoked session = %v, %v, want false", revoked, err)
+	}
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	stores := database.NewMemoryStores()
+	s := New(stores, time.Minute)
+	newSession(t, stores, "current", 1)
+	newSession(t, stores, "other", 1)
+	newSession(t, stores, "another user's", 2)
+
+	current := claims("current", 1)
+	current.SessionID = "current"
+	other := claims("other", 1)
+	other.SessionID = "other"
+	anotherUsers := claims("another user's", 2)
+	anotherUsers.SessionID = "another user's"
+
+	// Cache every session as not revoked first, so the test shows the cache being cleared
+	for _, c := range []*jwtHelper.TokenClaims{current, other, anotherUsers} {
+		assertRevoked(t, s, c, false)
+	}
+
+	if err := s.RevokeOtherSessions(context.Background(), 1, "current"); err != nil {
+		t.Fatal(err)
+	}
+
+	assertRevoked(t, s, current, false)
+	assertRevoked(t, s, other, true)
+	assertRevoked(t, s, anotherUsers, false)
+}
+
+func TestRevokeAllForUser(t *testing.T) {
+	s := New(database.NewMemoryStores(), time.Minute)
+
+	token := claims("token", 1)
+	impersonatingUser := claims("impersonating", 1)
+	impersonatingUser.ImpersonatorID = 3
+	anotherUsers := claims("another user's", 2)
+
+	for _, c := range []*jwtHelper.TokenClaims{token, impersonatingUser, anotherUsers} {
+		assertRevoked(t, s, c, false)
+	}
+
+	if err := s.RevokeAllForUser(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+
+	assertRevoked(t, s, token, true)
+	assertRevoked(t, s, impersonatingUser, true)
+	assertRevoked(t, s, anotherUsers, false)
+
+	// Tokens issued afterwards are unaffected
+	later := claims("later", 1)
+	later.IssuedAt = time.Now().Add(time.Second)
+	assertRevoked(t, s, later, false)
+}
+
+func TestRevocationOnAnotherInstanceWaitsForCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	stores := database.NewMemoryStores()
+	newSession(t, stores, "session", 2)
+
+	const cacheTTL = 100 * time.Millisecond
+	this, other := New(stores, cacheTTL), New(stores, cacheTTL)
+
+	token := claims("token", 1)
+	sessionToken := claims("session token", 2)
+	sessionToken.SessionID = "session"
+	userToken := claims("user token", 3)
+	tokens := []*jwtHelper.TokenClaims{token, sessionToken, userToken}
+
+	for _, c := range tokens {
+		assertRevoked(t, other, c, false)
+	}
+
+	if err := this.Revoke(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := this.RevokeSession(ctx, 2, "session"); err != nil {
+		t.Fatal(err)
+	}
+	if err := this.RevokeAllForUser(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	// The instance that revoked sees it straight away, the other keeps its cached answer until it goes stale
+	for _, c := range tokens {
+		assertRevoked(t, this, c, true)
+		assertRevoked(t, other, c, false)
+	}
+
+	time.Sleep(cacheTTL)
+
+	for _, c := range tokens {
+		assertRevoked(t, other, c, true)
+	}
+}
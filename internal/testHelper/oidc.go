@@ -0,0 +1,225 @@
+package testHelper
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/nathanjms/go-api-template/internal/oidcHelper"
+)
+
+const oidcKeyID = "test"
+
+// OIDCIdentity is who the user signs in to the OIDCProvider as
+type OIDCIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// oidcAuthorization is what the provider remembers about an authorization code until it is exchanged
+type oidcAuthorization struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	identity      OIDCIdentity
+}
+
+// OIDCProvider is an OpenID Connect provider on an httptest server. It serves discovery, signing keys and the
+// token endpoint, checking the client secret, redirect URI and PKCE verifier the way a real provider would.
+type OIDCProvider struct {
+	Server       *httptest.Server
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	key          *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]oidcAuthorization
+}
+
+func NewOIDCProvider(t testing.TB) *OIDCProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating provider key: %v", err)
+	}
+
+	p := &OIDCProvider{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		key:          key,
+		codes:        map[string]oidcAuthorization{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("GET /jwks", p.jwks)
+	mux.HandleFunc("POST /token", p.token)
+
+	p.Server = httptest.NewServer(mux)
+	p.Issuer = p.Server.URL
+	t.Cleanup(p.Server.Close)
+
+	return p
+}
+
+// Config is the provider's configuration for the API, as read from OIDC_<NAME>_* variables
+func (p *OIDCProvider) Config(name string, redirectURL string) oidcHelper.ProviderConfig {
+	return oidcHelper.ProviderConfig{
+		Name:         name,
+		Issuer:       p.Issuer,
+		ClientID:     p.ClientID,
+		ClientSecret: p.ClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"openid", "email"},
+	}
+}
+
+// Authorize signs the user in at the authorization URL the API redirected to, returning the code and state the
+// provider sends back to the redirect URI
+func (p *OIDCProvider) Authorize(t testing.TB, authURL string, identity OIDCIdentity) (code string, state string) {
+	t.Helper()
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parsing authorization URL: %v", err)
+	}
+	query := parsed.Query()
+	if query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("authorization URL %s is not for the code flow with S256 PKCE", authURL)
+	}
+
+	code, err = oidcHelper.RandomString()
+	if err != nil {
+		t.Fatalf("generating code: %v", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.codes[code] = oidcAuthorization{
+		clientID:      query.Get("client_id"),
+		redirectURI:   query.Get("redirect_uri"),
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+		identity:      identity,
+	}
+
+	return code, query.Get("state")
+}
+
+// IDToken signs an ID token with the provider's key, for tests that need one the token endpoint wouldn't issue
+func (p *OIDCProvider) IDToken(t testing.TB, claims jwt.MapClaims) string {
+	t.Helper()
+
+	signed, err := p.sign(claims)
+	if err != nil {
+		t.Fatalf("signing id_token: %v", err)
+	}
+
+	return signed
+}
+
+func (p *OIDCProvider) sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = oidcKeyID
+
+	return token.SignedString(p.key)
+}
+
+// Claims are the ID token claims the provider issues for the identity
+func (p *OIDCProvider) Claims(identity OIDCIdentity, nonce string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            p.Issuer,
+		"aud":            p.ClientID,
+		"sub":            identity.Subject,
+		"email":          identity.Email,
+		"email_verified": identity.EmailVerified,
+		"nonce":          nonce,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func (p *OIDCProvider) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 p.Issuer,
+		"authorization_endpoint": p.Issuer + "/authorize",
+		"token_endpoint":         p.Issuer + "/token",
+		"jwks_uri":               p.Issuer + "/jwks",
+	})
+}
+
+func (p *OIDCProvider) jwks(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": oidcKeyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+// token exchanges an authorization code once, for a client proving it holds the code's PKCE verifier
+func (p *OIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	invalidGrant := func() {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != p.ClientID || clientSecret != p.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		invalidGrant()
+		return
+	}
+
+	p.mu.Lock()
+	authorization, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	verifierHash := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || authorization.clientID != clientID || authorization.redirectURI != r.PostForm.Get("redirect_uri") ||
+		base64.RawURLEncoding.EncodeToString(verifierHash[:]) != authorization.codeChallenge {
+		invalidGrant()
+		return
+	}
+
+	idToken, err := p.sign(p.Claims(authorization.identity, authorization.nonce))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
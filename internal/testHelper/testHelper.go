@@ -0,0 +1,151 @@
+// Package testHelper builds applications on the memory stores, and fakes of the services they call, for testing
+// handlers without a database or network
+package testHelper
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nathanjms/go-api-template/internal/application"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/mailer"
+	"github.com/nathanjms/go-api-template/internal/passwordHasher"
+)
+
+var (
+	keyOnce sync.Once
+	key     string
+	keyErr  error
+)
+
+// signingKey generates an RSA key once per test binary, in the format RSA_PRIVATE_KEY takes
+func signingKey() (string, error) {
+	keyOnce.Do(func() {
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			keyErr = err
+			return
+		}
+		key = base64.StdEncoding.EncodeToString(x509.MarshalPKCS1PrivateKey(privateKey))
+	})
+
+	return key, keyErr
+}
+
+// Config returns the configuration from the environment's defaults, with a generated signing key and cheap password
+// hashing so tests run quickly
+func Config(t testing.TB) application.Config {
+	t.Helper()
+
+	secretKey, err := signingKey()
+	if err != nil {
+		t.Fatalf("generating signing key: %v", err)
+	}
+
+	cfg := application.LoadConfig()
+	cfg.JWT.KeysDir = ""
+	cfg.JWT.SecretKey = secretKey
+	cfg.Passwords.Algorithm = passwordHasher.Bcrypt
+	cfg.Passwords.BcryptCost = 4
+	cfg.LoginThrottle.Backend = "memory"
+	// The log mail driver is refused outside local development. Apps replace the mailer anyway.
+	t.Setenv("ENV", "local")
+	cfg.Mail.Driver = "log"
+
+	return cfg
+}
+
+// NewApp creates an application on fresh memory stores. Emails it sends are kept by the returned Mailer.
+func NewApp(t testing.TB) (*application.Application, *Mailer) {
+	t.Helper()
+
+	return NewAppWithConfig(t, Config(t))
+}
+
+// NewAppWithConfig is NewApp with the configuration given, which usually starts from Config
+func NewAppWithConfig(t testing.TB, cfg application.Config) (*application.Application, *Mailer) {
+	t.Helper()
+
+	app, err := application.NewWithStores(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg, database.NewMemoryStores())
+	if err != nil {
+		t.Fatalf("creating application: %v", err)
+	}
+	t.Cleanup(app.Close)
+
+	mail := &Mailer{}
+	app.Mailer = mail
+
+	return app, mail
+}
+
+// CreateUser registers a user with the given password
+func CreateUser(t testing.TB, app *application.Application, username string, password string) database.User {
+	t.Helper()
+	ctx := context.Background()
+
+	hash, err := app.Passwords.Hash(password)
+	if err != nil {
+		t.Fatalf("hashing password: %v", err)
+	}
+
+	id, err := app.Users.Create(ctx, username, hash)
+	if err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+
+	user, err := app.Users.FindUser(ctx, id)
+	if err != nil {
+		t.Fatalf("finding user: %v", err)
+	}
+
+	return user
+}
+
+// AccessToken starts a session for the user, returning an access token for it
+func AccessToken(t testing.TB, app *application.Application, user database.User) string {
+	t.Helper()
+
+	sessionId := uuid.NewString()
+	if err := app.Sessions.Create(context.Background(), sessionId, user.ID, "test", "192.0.2.1", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("creating session: %v", err)
+	}
+
+	token, _, err := app.JWTService.CreateAccessToken(user.ID, user.Username, sessionId)
+	if err != nil {
+		t.Fatalf("creating access token: %v", err)
+	}
+
+	return token
+}
+
+// Mailer keeps the messages sent through it instead of sending them
+type Mailer struct {
+	mu       sync.Mutex
+	messages []mailer.Message
+}
+
+func (m *Mailer) Send(msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, msg)
+
+	return nil
+}
+
+// Messages returns the messages sent so far, oldest first
+func (m *Mailer) Messages() []mailer.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]mailer.Message{}, m.messages...)
+}
@@ -0,0 +1,89 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryBackend keeps attempts in process. Attempts are not shared between instances or kept across restarts.
+type MemoryBackend struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+type memoryEntry struct {
+	failures      int
+	lastFailureAt time.Time
+	lockedUntil   time.Time
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		entries: map[string]*memoryEntry{},
+	}
+}
+
+func (b *MemoryBackend) Fail(_ context.Context, key string, now time.Time, resetBefore time.Time) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entry, ok := b.entries[key]
+	if !ok {
+		entry = &memoryEntry{}
+		b.entries[key] = entry
+	}
+
+	if entry.lastFailureAt.Before(resetBefore) {
+		entry.failures = 0
+	}
+	entry.failures++
+	entry.lastFailureAt = now
+
+	return entry.failures, nil
+}
+
+func (b *MemoryBackend) Lock(_ context.Context, key string, until time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if entry, ok := b.entries[key]; ok {
+		entry.lockedUntil = until
+	}
+
+	return nil
+}
+
+func (b *MemoryBackend) LockedUntil(_ context.Context, key string) (time.Time, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if entry, ok := b.entries[key]; ok {
+		return entry.lockedUntil, nil
+	}
+
+	return time.Time{}, nil
+}
+
+func (b *MemoryBackend) Reset(_ context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.entries, key)
+
+	return nil
+}
+
+func (b *MemoryBackend) Prune(_ context.Context, before time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	for key, entry := range b.entries {
+		if now.After(entry.lockedUntil) && entry.lastFailureAt.Before(before) {
+			delete(b.entries, key)
+		}
+	}
+
+	return nil
+}
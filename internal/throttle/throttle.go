@@ -0,0 +1,96 @@
+package throttle
+
+import (
+	"context"
+	"time"
+)
+
+// Backend stores failed attempts per key. MemoryBackend suits a single instance;
+// database.LoginAttemptModel shares attempts between instances through MySQL.
+type Backend interface {
+	// Fail records a failed attempt and returns how many there have been, starting the count again
+	// if the previous failure was before resetBefore
+	Fail(ctx context.Context, key string, now time.Time, resetBefore time.Time) (int, error)
+	// Lock blocks the key until the given time
+	Lock(ctx context.Context, key string, until time.Time) error
+	// LockedUntil returns when the key's lock ends, or the zero time if it isn't locked
+	LockedUntil(ctx context.Context, key string) (time.Time, error)
+	// Reset forgets the key's failures and lock
+	Reset(ctx context.Context, key string) error
+	// Prune removes keys that are not locked and last failed before the given time
+	Prune(ctx context.Context, before time.Time) error
+}
+
+// Policy decides how long a key is locked for. After FreeAttempts failures each further failure locks
+// the key for BaseDelay, doubling every time up to MaxDelay. Failures are forgotten after ResetAfter.
+type Policy struct {
+	FreeAttempts int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+	ResetAfter   time.Duration
+}
+
+// Delay returns how long to lock a key for after its nth failure
+func (p Policy) Delay(failures int) time.Duration {
+	if failures <= p.FreeAttempts {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := p.FreeAttempts + 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, p.MaxDelay)
+}
+
+// Limiter applies a Policy to keys, such as an account or a client IP
+type Limiter struct {
+	backend Backend
+	policy  Policy
+}
+
+func NewLimiter(backend Backend, policy Policy) *Limiter {
+	return &Limiter{backend: backend, policy: policy}
+}
+
+// Check returns how long until the key may try again, or zero if it isn't locked
+func (l *Limiter) Check(ctx context.Context, key string) (time.Duration, error) {
+	lockedUntil, err := l.backend.LockedUntil(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	if wait := time.Until(lockedUntil); wait > 0 {
+		return wait, nil
+	}
+
+	return 0, nil
+}
+
+// Fail records a failed attempt, locking the key if it has failed too often, and returns how long it is locked for
+func (l *Limiter) Fail(ctx context.Context, key string) (time.Duration, error) {
+	now := time.Now()
+	failures, err := l.backend.Fail(ctx, key, now, now.Add(-l.policy.ResetAfter))
+	if err != nil {
+		return 0, err
+	}
+
+	delay := l.policy.Delay(failures)
+	if delay == 0 {
+		return 0, nil
+	}
+
+	return delay, l.backend.Lock(ctx, key, now.Add(delay))
+}
+
+// Succeed clears the key's failures after a successful attempt
+func (l *Limiter) Succeed(ctx context.Context, key string) error {
+	return l.backend.Reset(ctx, key)
+}
+
+// Prune clears out forgotten failures so the backend does not grow forever. It is run by a background job rather
+// than on requests.
+func (l *Limiter) Prune(ctx context.Context) error {
+	return l.backend.Prune(ctx, time.Now().Add(-l.policy.ResetAfter))
+}
@@ -0,0 +1,117 @@
+package totpHelper
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key from the RFC 6238 test vectors, "12345678901234567890"
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeMatchesRFC6238(t *testing.T) {
+	// The RFC's 8 digit codes, of which 6 digit codes are the last 6
+	tests := map[int64]string{
+		59:          "94287082",
+		1111111109:  "07081804",
+		1111111111:  "14050471",
+		1234567890:  "89005924",
+		2000000000:  "69279037",
+		20000000000: "65353130",
+	}
+
+	for unix, want := range tests {
+		code, err := Code(rfcSecret, time.Unix(unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != want[2:] {
+			t.Errorf("code at %d = %s, want %s", unix, code, want[2:])
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := now.Unix() / period
+
+	codeAt := func(offset int64) string {
+		code, err := Code(rfcSecret, now.Add(time.Duration(offset*period)*time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current", code: codeAt(0), wantStep: step, wantOK: true},
+		{name: "previous step", code: codeAt(-1), wantStep: step - 1, wantOK: true},
+		{name: "next step", code: codeAt(1), wantStep: step + 1, wantOK: true},
+		{name: "two steps ago", code: codeAt(-2), wantOK: false},
+		{name: "two steps ahead", code: codeAt(2), wantOK: false},
+		{name: "with spaces", code: " " + codeAt(0)[:3] + " " + codeAt(0)[3:] + " ", wantStep: step, wantOK: true},
+		{name: "too short", code: codeAt(0)[:5], wantOK: false},
+		{name: "empty", code: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := Validate(rfcSecret, tt.code, now)
+			if ok != tt.wantOK || (ok && gotStep != tt.wantStep) {
+				t.Errorf("Validate = %d, %v, want %d, %v", gotStep, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+
+	if _, ok := Validate("not base32!", codeAt(0), now); ok {
+		t.Error("Validate accepted a code for an invalid secret")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := encoding.DecodeString(secret)
+	if err != nil || len(key) != secretBytes {
+		t.Errorf("secret %q decodes to %d bytes, %v, want %d", secret, len(key), err, secretBytes)
+	}
+	if !strings.Contains(URI("App", "user@example.com", secret), "secret="+secret) {
+		t.Error("URI doesn't contain the secret")
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 10 {
+		t.Fatalf("generated %d codes, want 10", len(codes))
+	}
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if len(code) != recoveryCodeLength+1 || code[recoveryCodeLength/2] != '-' {
+			t.Errorf("code %q is not formatted like abcde-fghij", code)
+		}
+		if seen[code] {
+			t.Errorf("code %q generated twice", code)
+		}
+		seen[code] = true
+
+		// However the user types it, it hashes the same
+		normalized := NormalizeRecoveryCode(code)
+		if typed := NormalizeRecoveryCode(" " + strings.ToUpper(strings.ReplaceAll(code, "-", " ")) + " "); typed != normalized {
+			t.Errorf("typed code normalizes to %q, want %q", typed, normalized)
+		}
+	}
+}
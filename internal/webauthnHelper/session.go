@@ -0,0 +1,48 @@
+package webauthnHelper
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/nathanjms/go-api-template/internal/database"
+	"github.com/nathanjms/go-api-template/internal/tokenHelper"
+)
+
+// sessionTTL is how long the user has to complete a ceremony after it begins
+const sessionTTL = 5 * time.Minute
+
+// SaveSession stores the session data for a ceremony, returning the opaque token the client must send back to finish it
+func SaveSession(ctx context.Context, sessions database.WebAuthnSessionStore, userId int64, ceremony string, session *webauthn.SessionData) (string, error) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+
+	token, tokenHash, err := tokenHelper.Generate()
+	if err != nil {
+		return "", err
+	}
+
+	if err := sessions.Create(ctx, tokenHash, userId, ceremony, string(data), time.Now().Add(sessionTTL)); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// LoadSession consumes the session for a ceremony, returning its data and the user it was started for (0 if none)
+func LoadSession(ctx context.Context, sessions database.WebAuthnSessionStore, token string, ceremony string) (webauthn.SessionData, int64, error) {
+	stored, err := sessions.Consume(ctx, tokenHelper.Hash(token), ceremony)
+	if err != nil {
+		return webauthn.SessionData{}, 0, err
+	}
+
+	var session webauthn.SessionData
+	if err := json.Unmarshal([]byte(stored.Data), &session); err != nil {
+		return webauthn.SessionData{}, 0, err
+	}
+
+	return session, stored.UserID.Int64, nil
+}
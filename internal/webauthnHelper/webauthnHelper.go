@@ -0,0 +1,108 @@
+package webauthnHelper
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/nathanjms/go-api-template/internal/database"
+)
+
+// New configures the WebAuthn relying party. rpId is the domain passkeys are bound to and origins
+// are the full origins (scheme, host and port) of the frontends allowed to use them.
+func New(rpId string, displayName string, origins []string) (*webauthn.WebAuthn, error) {
+	return webauthn.New(&webauthn.Config{
+		RPID:          rpId,
+		RPDisplayName: displayName,
+		RPOrigins:     origins,
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey:      protocol.ResidentKeyRequirementRequired,
+			UserVerification: protocol.VerificationRequired,
+		},
+	})
+}
+
+// User adapts a database.User and their credentials to the webauthn.User interface
+type User struct {
+	User        database.User
+	Credentials []database.UserCredential
+}
+
+func (u *User) WebAuthnID() []byte {
+	return UserHandle(u.User.ID)
+}
+
+func (u *User) WebAuthnName() string {
+	return u.User.Username
+}
+
+func (u *User) WebAuthnDisplayName() string {
+	return u.User.Username
+}
+
+func (u *User) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(u.Credentials))
+	for _, credential := range u.Credentials {
+		credentials = append(credentials, ToWebAuthnCredential(credential))
+	}
+	return credentials
+}
+
+// UserHandle is the opaque user handle stored on the authenticator, which is just the user's id
+func UserHandle(userId int64) []byte {
+	handle := make([]byte, 8)
+	binary.BigEndian.PutUint64(handle, uint64(userId))
+	return handle
+}
+
+// UserIdFromHandle reverses UserHandle
+func UserIdFromHandle(handle []byte) (int64, error) {
+	if len(handle) != 8 {
+		return 0, errors.New("invalid user handle")
+	}
+	return int64(binary.BigEndian.Uint64(handle)), nil
+}
+
+// ToWebAuthnCredential converts a stored credential back into the library's representation
+func ToWebAuthnCredential(credential database.UserCredential) webauthn.Credential {
+	transports := []protocol.AuthenticatorTransport{}
+	if credential.Transports != "" {
+		for _, transport := range strings.Split(credential.Transports, ",") {
+			transports = append(transports, protocol.AuthenticatorTransport(transport))
+		}
+	}
+
+	return webauthn.Credential{
+		ID:              credential.CredentialID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transport:       transports,
+		Flags:           webauthn.NewCredentialFlags(protocol.AuthenticatorFlags(credential.Flags)),
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    credential.AAGUID,
+			SignCount: credential.SignCount,
+		},
+	}
+}
+
+// FromWebAuthnCredential converts a newly registered credential into a row to store for the user
+func FromWebAuthnCredential(userId int64, name string, credential *webauthn.Credential) database.UserCredential {
+	transports := make([]string, 0, len(credential.Transport))
+	for _, transport := range credential.Transport {
+		transports = append(transports, string(transport))
+	}
+
+	return database.UserCredential{
+		UserID:          userId,
+		Name:            name,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+		Flags:           uint8(credential.Flags.ProtocolValue()),
+		Transports:      strings.Join(transports, ","),
+	}
+}
@@ -0,0 +1,257 @@
+package webauthnHelper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/nathanjms/go-api-template/internal/database"
+)
+
+const (
+	testRPID   = "localhost"
+	testOrigin = "http://localhost:3000"
+)
+
+type fixture struct {
+	ctx      context.Context
+	webAuthn *webauthn.WebAuthn
+	stores   database.Stores
+	user     database.User
+}
+
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+	ctx := context.Background()
+
+	webAuthn, err := New(testRPID, "Test", []string{testOrigin})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stores := database.NewMemoryStores()
+	id, err := stores.Users.Create(ctx, "user@example.com", "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := stores.Users.FindUser(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return fixture{ctx: ctx, webAuthn: webAuthn, stores: stores, user: user}
+}
+
+func (f fixture) webAuthnUser(t *testing.T) *User {
+	t.Helper()
+
+	credentials, err := f.stores.Credentials.GetByUserId(f.ctx, f.user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &User{User: f.user, Credentials: credentials}
+}
+
+// register runs the registration ceremony as the passkey registration handlers do, storing the new passkey
+func (f fixture) register(t *testing.T, a *authenticator) database.UserCredential {
+	t.Helper()
+
+	creation, session, err := f.webAuthn.BeginRegistration(f.webAuthnUser(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionToken, err := SaveSession(f.ctx, f.stores.WebAuthnSessions, f.user.ID, database.WebAuthnRegistration, session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response := a.create(t, creation)
+
+	stored, userId, err := LoadSession(f.ctx, f.stores.WebAuthnSessions, sessionToken, database.WebAuthnRegistration)
+	if err != nil || userId != f.user.ID {
+		t.Fatalf("LoadSession = %d, %v, want %d", userId, err, f.user.ID)
+	}
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(response)
+	if err != nil {
+		t.Fatalf("parsing attestation: %v", err)
+	}
+	credential, err := f.webAuthn.CreateCredential(f.webAuthnUser(t), stored, parsed)
+	if err != nil {
+		t.Fatalf("CreateCredential: %v", err)
+	}
+
+	if err := f.stores.Credentials.Create(f.ctx, FromWebAuthnCredential(f.user.ID, "Laptop", credential)); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := f.stores.Credentials.GetByCredentialId(f.ctx, a.credentialId)
+	if err != nil {
+		t.Fatalf("passkey not saved: %v", err)
+	}
+
+	return saved
+}
+
+// login runs the discoverable login ceremony as the passkey login handlers do, recording the use of the passkey
+func (f fixture) login(t *testing.T, a *authenticator) (*webauthn.Credential, error) {
+	t.Helper()
+
+	assertion, session, err := f.webAuthn.BeginDiscoverableLogin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionToken, err := SaveSession(f.ctx, f.stores.WebAuthnSessions, 0, database.WebAuthnLogin, session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response := a.get(t, assertion)
+
+	stored, _, err := LoadSession(f.ctx, f.stores.WebAuthnSessions, sessionToken, database.WebAuthnLogin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(response)
+	if err != nil {
+		t.Fatalf("parsing assertion: %v", err)
+	}
+
+	var storedCredential database.UserCredential
+	findUser := func(rawId, userHandle []byte) (webauthn.User, error) {
+		userId, err := UserIdFromHandle(userHandle)
+		if err != nil {
+			return nil, err
+		}
+
+		storedCredential, err = f.stores.Credentials.GetByCredentialId(f.ctx, rawId)
+		if err != nil || storedCredential.UserID != userId {
+			return nil, protocol.ErrBadRequest.WithDetails("Unknown credential")
+		}
+
+		return &User{User: f.user, Credentials: []database.UserCredential{storedCredential}}, nil
+	}
+
+	_, credential, err := f.webAuthn.ValidatePasskeyLogin(findUser, stored, parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	if !credential.Authenticator.CloneWarning {
+		flags := uint8(parsed.Response.AuthenticatorData.Flags)
+		if err := f.stores.Credentials.RecordUse(f.ctx, storedCredential.ID, credential.Authenticator.SignCount, flags); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return credential, nil
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	f := newFixture(t)
+	a := newAuthenticator(t, testRPID, testOrigin)
+
+	saved := f.register(t, a)
+	if saved.UserID != f.user.ID || saved.Name != "Laptop" || saved.Transports != "internal" {
+		t.Errorf("saved passkey = %+v", saved)
+	}
+	if userId, err := UserIdFromHandle(a.userHandle); err != nil || userId != f.user.ID {
+		t.Errorf("user handle is for user %d (%v), want %d", userId, err, f.user.ID)
+	}
+
+	for range 2 {
+		credential, err := f.login(t, a)
+		if err != nil {
+			t.Fatalf("login: %v", err)
+		}
+		if credential.Authenticator.CloneWarning {
+			t.Error("clone warning for an increasing signature counter")
+		}
+	}
+
+	used, err := f.stores.Credentials.GetByCredentialId(f.ctx, a.credentialId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if used.SignCount != 2 || !used.LastUsedAt.Valid {
+		t.Errorf("sign count = %d, last used %v, want 2 and set", used.SignCount, used.LastUsedAt)
+	}
+}
+
+func TestRegistrationRejectsWrongOrigin(t *testing.T) {
+	f := newFixture(t)
+	a := newAuthenticator(t, testRPID, "https://evil.example.com")
+
+	creation, session, err := f.webAuthn.BeginRegistration(f.webAuthnUser(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(a.create(t, creation))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.webAuthn.CreateCredential(f.webAuthnUser(t), *session, parsed); err == nil {
+		t.Error("CreateCredential accepted an attestation from another origin")
+	}
+}
+
+func TestLoginWarnsWhenSignCountGoesBackwards(t *testing.T) {
+	f := newFixture(t)
+	a := newAuthenticator(t, testRPID, testOrigin)
+	f.register(t, a)
+
+	if _, err := f.login(t, a); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	// A copy of the authenticator that hasn't seen the last use replays an old counter
+	a.signCount = 0
+	credential, err := f.login(t, a)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if !credential.Authenticator.CloneWarning {
+		t.Error("no clone warning when the signature counter went backwards")
+	}
+}
+
+func TestDeletedPasskeyCannotLogIn(t *testing.T) {
+	f := newFixture(t)
+	a := newAuthenticator(t, testRPID, testOrigin)
+	saved := f.register(t, a)
+
+	if deleted, err := f.stores.Credentials.Delete(f.ctx, f.user.ID+1, saved.ID); err != nil || deleted {
+		t.Fatalf("another user's Delete = %v, %v, want false", deleted, err)
+	}
+	if deleted, err := f.stores.Credentials.Delete(f.ctx, f.user.ID, saved.ID); err != nil || !deleted {
+		t.Fatalf("Delete = %v, %v, want true", deleted, err)
+	}
+
+	if _, err := f.login(t, a); err == nil {
+		t.Error("login succeeded with a deleted passkey")
+	}
+}
+
+func TestLoadSessionIsSingleUse(t *testing.T) {
+	f := newFixture(t)
+
+	_, session, err := f.webAuthn.BeginDiscoverableLogin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionToken, err := SaveSession(f.ctx, f.stores.WebAuthnSessions, 0, database.WebAuthnLogin, session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := LoadSession(f.ctx, f.stores.WebAuthnSessions, sessionToken, database.WebAuthnRegistration); err == nil {
+		t.Error("LoadSession accepted a login session for registration")
+	}
+	if _, _, err := LoadSession(f.ctx, f.stores.WebAuthnSessions, sessionToken, database.WebAuthnLogin); err != nil {
+		t.Fatalf("LoadSession: %v", err)
+	}
+	if _, _, err := LoadSession(f.ctx, f.stores.WebAuthnSessions, sessionToken, database.WebAuthnLogin); err == nil {
+		t.Error("LoadSession accepted a session twice")
+	}
+}